@@ -3,28 +3,51 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"expvar"
 	"fmt"
 	"io"
 	"net/http"
 
 	"github.com/ONSdigital/dp-feedback-api/config"
+	"github.com/ONSdigital/dp-feedback-api/models"
+	"github.com/ONSdigital/dp-feedback-api/spam"
 	"github.com/ONSdigital/log.go/v2/log"
 	"github.com/go-chi/chi/v5"
 )
 
 // API provides a struct to wrap the api around
 type API struct {
-	Cfg         *config.Config
-	Router      chi.Router
-	EmailSender EmailSender
+	Cfg              *config.Config
+	Router           chi.Router
+	Notifiers        []Notifier
+	Events           EventPublisher
+	RateLimiter      RateLimiter
+	IdempotencyCache IdempotencyCache
+	Duplicates       DuplicateDetector
+	Spam             *spam.Scorer
+	IdentityClient   IdentityClient
+	FeedbackStore    FeedbackStore
 }
 
 // Setup function sets up the api and returns an api
-func Setup(ctx context.Context, cfg *config.Config, r chi.Router, e EmailSender) *API {
+func Setup(ctx context.Context, cfg *config.Config, r chi.Router, notifiers []Notifier, events EventPublisher, rateLimiter RateLimiter, idempotencyCache IdempotencyCache, duplicates DuplicateDetector, idClient IdentityClient, s FeedbackStore) *API {
+	// the allowed domains are validated on startup, so this can only fail if the config was not validated
+	domains, err := cfg.DomainRules()
+	if err != nil {
+		log.Error(ctx, "failed to get allowed domains, links to them are scored as external links", err)
+	}
+
 	api := &API{
-		Cfg:         cfg,
-		Router:      r,
-		EmailSender: e,
+		Cfg:              cfg,
+		Router:           r,
+		Notifiers:        notifiers,
+		Events:           events,
+		RateLimiter:      rateLimiter,
+		IdempotencyCache: idempotencyCache,
+		Duplicates:       duplicates,
+		Spam:             spam.NewScorer(cfg.Spam, domains),
+		IdentityClient:   idClient,
+		FeedbackStore:    s,
 	}
 
 	api.mountEndpoints(ctx)
@@ -34,13 +57,24 @@ func Setup(ctx context.Context, cfg *config.Config, r chi.Router, e EmailSender)
 
 // mountEndpoints creates a a new chi Router with the auth middleware and required endpoints,
 // and then mounts it to the existing router, in order to prevent existing endpoints (i.e. /health) to go through auth.
+// Only the submission of feedback is rate limited, as it is the endpoint exposed to the public through the feedback form,
+// and idempotent, as it is retried by the feedback form on flaky connections. The retries that get the original response are not rate limited.
+// The stored feedback, and the counters published by expvar for monitoring, can only be read by the callers configured as feedback readers.
 func (api *API) mountEndpoints(ctx context.Context) {
 	r := chi.NewRouter()
+	r.Use(api.Authorise)
 	r.Route(api.Cfg.VersionPrefix, func(r chi.Router) {
-		r.Post("/feedback", api.PostFeedback)
+		r.With(api.Idempotent, api.RateLimit).Post("/feedback", api.PostFeedback)
+		r.With(api.AuthoriseRead).Get("/feedback", api.GetFeedbackList)
+		r.With(api.AuthoriseRead).Get("/feedback/summary", api.GetFeedbackSummary)
+		r.With(api.AuthoriseRead).Get("/feedback/{id}", api.GetFeedback)
 	})
 
-	r.Post("/feedback", api.PostFeedback)
+	r.With(api.Idempotent, api.RateLimit).Post("/feedback", api.PostFeedback)
+	r.With(api.AuthoriseRead).Get("/feedback", api.GetFeedbackList)
+	r.With(api.AuthoriseRead).Get("/feedback/summary", api.GetFeedbackSummary)
+	r.With(api.AuthoriseRead).Get("/feedback/{id}", api.GetFeedback)
+	r.With(api.AuthoriseRead).Get("/debug/vars", expvar.Handler().ServeHTTP)
 	api.Router.Mount("/", r)
 }
 
@@ -57,7 +91,36 @@ func Unmarshal(body io.ReadCloser, v interface{}) error {
 	return nil
 }
 
+// writeJSON marshals the provided value and writes it as the JSON response body with the provided status code
+func (api *API) writeJSON(ctx context.Context, w http.ResponseWriter, v interface{}, status int) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		api.handleError(ctx, w, fmt.Errorf("failed to marshal response: %w", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err := w.Write(b); err != nil {
+		log.Error(ctx, "failed to write response body", err)
+	}
+}
+
+// handleError logs the provided error and writes it as a JSON error response with the provided status code,
+// including the list of fields that failed validation, if any
 func (api *API) handleError(ctx context.Context, w http.ResponseWriter, err error, status int) {
-	log.Error(ctx, "request failed", err)
-	http.Error(w, err.Error(), status)
+	log.Error(ctx, "request failed", err, log.Data{"status": status})
+
+	b, errMarshal := json.Marshal(models.NewErrorResponse(err, status))
+	if errMarshal != nil {
+		log.Error(ctx, "failed to marshal error response", errMarshal)
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, errWrite := w.Write(b); errWrite != nil {
+		log.Error(ctx, "failed to write error response body", errWrite)
+	}
 }
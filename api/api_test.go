@@ -18,14 +18,17 @@ func TestSetup(t *testing.T) {
 	Convey("Given an API instance", t, func() {
 		r := chi.NewRouter()
 		ctx := context.Background()
-		cfg := &config.Config{
-			OnsDomain:     "localhost",
-			VersionPrefix: "/v1",
-		}
-		a := api.Setup(ctx, cfg, r, nil)
+		cfg := testConfig()
+		a := api.Setup(ctx, cfg, r, nil, nil, nil, nil, nil, nil, nil)
 
 		Convey("When created the following routes should have been added", func() {
 			So(hasRoute(a.Router, cfg.VersionPrefix+"/feedback", http.MethodPost), ShouldBeTrue)
+			So(hasRoute(a.Router, cfg.VersionPrefix+"/feedback", http.MethodGet), ShouldBeTrue)
+			So(hasRoute(a.Router, cfg.VersionPrefix+"/feedback/123", http.MethodGet), ShouldBeTrue)
+			So(hasRoute(a.Router, cfg.VersionPrefix+"/feedback/summary", http.MethodGet), ShouldBeTrue)
+			So(hasRoute(a.Router, "/feedback", http.MethodPost), ShouldBeTrue)
+			So(hasRoute(a.Router, "/feedback/123", http.MethodGet), ShouldBeTrue)
+			So(hasRoute(a.Router, "/feedback", http.MethodGet), ShouldBeTrue)
 		})
 	})
 }
@@ -48,6 +51,24 @@ func TestUnmarshal(t *testing.T) {
 	})
 }
 
+func testConfig() *config.Config {
+	return &config.Config{
+		OnsDomain:           "localhost",
+		VersionPrefix:       "/v1",
+		DefaultLimit:        20,
+		DefaultOffset:       0,
+		DefaultMaximumLimit: 100,
+	}
+}
+
+func newRouterWithHealth() chi.Router {
+	r := chi.NewRouter()
+	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	return r
+}
+
 func body(strBody string) io.ReadCloser {
 	buff := bytes.NewBufferString(strBody)
 	return io.NopCloser(buff)
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	dprequest "github.com/ONSdigital/dp-net/v3/request"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+const (
+	authorizationHeader = "Authorization"
+	bearerPrefix        = "bearer "
+)
+
+var (
+	errMissingToken    = errors.New("missing or malformed authorization header")
+	errUnknownIdentity = errors.New("no identity found for the provided token")
+	errNotReader       = errors.New("caller is not allowed to read feedback")
+)
+
+// Authorise is a middleware that checks the service auth token provided in the Authorization header
+// against the identity client, rejecting the request with 401 Unauthorized if it cannot be identified,
+// or with 500 Internal Server Error if the identity API cannot be reached or fails to identify it for any other reason.
+// The identifier of the calling service is added to the request context as the caller.
+func (api *API) Authorise(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		token, err := getBearerToken(r)
+		if err != nil {
+			api.handleError(ctx, w, err, http.StatusUnauthorized)
+			return
+		}
+
+		idCtx, status, authFailure, err := api.IdentityClient.CheckRequest(r, "", token)
+		switch {
+		case err != nil:
+			api.handleError(ctx, w, fmt.Errorf("failed to identify caller: %w", err), http.StatusInternalServerError)
+			return
+		case authFailure != nil && status == http.StatusUnauthorized:
+			api.handleError(ctx, w, fmt.Errorf("failed to identify caller: %w", authFailure), http.StatusUnauthorized)
+			return
+		case authFailure != nil:
+			api.handleError(ctx, w, fmt.Errorf("failed to identify caller, identity API returned status %d: %w", status, authFailure), http.StatusInternalServerError)
+			return
+		}
+
+		caller := dprequest.Caller(idCtx)
+		if caller == "" {
+			api.handleError(ctx, w, errUnknownIdentity, http.StatusUnauthorized)
+			return
+		}
+
+		log.Info(ctx, "caller identified", log.Data{"caller": caller})
+		next.ServeHTTP(w, r.WithContext(dprequest.SetCaller(ctx, caller)))
+	})
+}
+
+// AuthoriseRead is a middleware that only allows the callers configured as feedback readers to read the stored feedback,
+// which contains the names and email addresses of the submitters, rejecting any other caller with 403 Forbidden.
+// It expects the caller to have been identified by Authorise.
+func (api *API) AuthoriseRead(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		caller := dprequest.Caller(ctx)
+		if caller == "" || !slices.Contains(api.Cfg.FeedbackReaders, caller) {
+			api.handleError(ctx, w, fmt.Errorf("%w: %s", errNotReader, caller), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// getBearerToken extracts the token from the request Authorization header, which is expected to be in
+// the form 'Bearer <token>'. The prefix is matched case-insensitively.
+func getBearerToken(r *http.Request) (string, error) {
+	header := r.Header.Get(authorizationHeader)
+	if len(header) <= len(bearerPrefix) || !strings.EqualFold(header[:len(bearerPrefix)], bearerPrefix) {
+		return "", errMissingToken
+	}
+
+	token := strings.TrimSpace(header[len(bearerPrefix):])
+	if token == "" {
+		return "", errMissingToken
+	}
+	return token, nil
+}
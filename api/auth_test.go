@@ -0,0 +1,250 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ONSdigital/dp-api-clients-go/v2/identity"
+	"github.com/ONSdigital/dp-feedback-api/api"
+	"github.com/ONSdigital/dp-feedback-api/api/mock"
+	dprequest "github.com/ONSdigital/dp-net/v3/request"
+	"github.com/go-chi/chi/v5"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const (
+	testServiceToken = "serviceToken"
+	testServiceID    = "dp-frontend-feedback-controller"
+)
+
+func identityClientMock() *mock.IdentityClientMock {
+	return &mock.IdentityClientMock{
+		CheckRequestFunc: func(req *http.Request, florenceToken, serviceAuthToken string) (context.Context, int, identity.AuthFailure, error) {
+			if serviceAuthToken != testServiceToken {
+				return req.Context(), http.StatusUnauthorized, errors.New("unexpected status code returned from AuthAPI"), nil
+			}
+			return dprequest.SetCaller(req.Context(), testServiceID), http.StatusOK, nil, nil
+		},
+	}
+}
+
+func TestAuthorise(t *testing.T) {
+	Convey("Given an API with an identity client and an authorised handler", t, func() {
+		idClient := identityClientMock()
+		a := &api.API{IdentityClient: idClient}
+
+		var caller string
+		handlerCalled := false
+		h := a.Authorise(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handlerCalled = true
+			caller = dprequest.Caller(r.Context())
+		}))
+
+		Convey("When a request with a valid service token is received", func() {
+			r := httptest.NewRequest(http.MethodPost, "/feedback", http.NoBody)
+			r.Header.Set("Authorization", "Bearer "+testServiceToken)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			Convey("Then the token is checked as a service token", func() {
+				So(idClient.CheckRequestCalls(), ShouldHaveLength, 1)
+				So(idClient.CheckRequestCalls()[0].FlorenceToken, ShouldBeEmpty)
+				So(idClient.CheckRequestCalls()[0].ServiceAuthToken, ShouldEqual, testServiceToken)
+			})
+
+			Convey("Then the handler is called with the caller identity in the context", func() {
+				So(handlerCalled, ShouldBeTrue)
+				So(caller, ShouldEqual, testServiceID)
+			})
+		})
+
+		Convey("When a request with a lower case bearer prefix is received", func() {
+			r := httptest.NewRequest(http.MethodPost, "/feedback", http.NoBody)
+			r.Header.Set("Authorization", "bearer "+testServiceToken)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			Convey("Then the handler is called", func() {
+				So(handlerCalled, ShouldBeTrue)
+			})
+		})
+
+		Convey("When a request without an Authorization header is received", func() {
+			r := httptest.NewRequest(http.MethodPost, "/feedback", http.NoBody)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			Convey("Then 401 Unauthorized is returned without calling the identity client or the handler", func() {
+				So(w.Code, ShouldEqual, http.StatusUnauthorized)
+				So(idClient.CheckRequestCalls(), ShouldHaveLength, 0)
+				So(handlerCalled, ShouldBeFalse)
+			})
+		})
+
+		Convey("When a request with a malformed Authorization header is received", func() {
+			r := httptest.NewRequest(http.MethodPost, "/feedback", http.NoBody)
+			r.Header.Set("Authorization", testServiceToken)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			Convey("Then 401 Unauthorized is returned without calling the identity client or the handler", func() {
+				So(w.Code, ShouldEqual, http.StatusUnauthorized)
+				So(idClient.CheckRequestCalls(), ShouldHaveLength, 0)
+				So(handlerCalled, ShouldBeFalse)
+			})
+		})
+
+		Convey("When a request with an invalid service token is received", func() {
+			r := httptest.NewRequest(http.MethodPost, "/feedback", http.NoBody)
+			r.Header.Set("Authorization", "Bearer wrong")
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			Convey("Then 401 Unauthorized is returned without calling the handler", func() {
+				So(w.Code, ShouldEqual, http.StatusUnauthorized)
+				So(idClient.CheckRequestCalls(), ShouldHaveLength, 1)
+				So(handlerCalled, ShouldBeFalse)
+			})
+		})
+
+		Convey("When the identity API fails with a status other than 401 Unauthorized", func() {
+			idClient.CheckRequestFunc = func(req *http.Request, florenceToken, serviceAuthToken string) (context.Context, int, identity.AuthFailure, error) {
+				return req.Context(), http.StatusServiceUnavailable, errors.New("unexpected status code returned from AuthAPI"), nil
+			}
+			r := httptest.NewRequest(http.MethodPost, "/feedback", http.NoBody)
+			r.Header.Set("Authorization", "Bearer "+testServiceToken)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			Convey("Then 500 Internal Server Error is returned without calling the handler", func() {
+				So(w.Code, ShouldEqual, http.StatusInternalServerError)
+				So(handlerCalled, ShouldBeFalse)
+			})
+		})
+
+		Convey("When the identity API cannot be reached", func() {
+			idClient.CheckRequestFunc = func(req *http.Request, florenceToken, serviceAuthToken string) (context.Context, int, identity.AuthFailure, error) {
+				return req.Context(), http.StatusInternalServerError, nil, errors.New("connection refused")
+			}
+			r := httptest.NewRequest(http.MethodPost, "/feedback", http.NoBody)
+			r.Header.Set("Authorization", "Bearer "+testServiceToken)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			Convey("Then 500 Internal Server Error is returned without calling the handler", func() {
+				So(w.Code, ShouldEqual, http.StatusInternalServerError)
+				So(handlerCalled, ShouldBeFalse)
+			})
+		})
+
+		Convey("When the identity API identifies the token without an identifier", func() {
+			idClient.CheckRequestFunc = func(req *http.Request, florenceToken, serviceAuthToken string) (context.Context, int, identity.AuthFailure, error) {
+				return req.Context(), http.StatusOK, nil, nil
+			}
+			r := httptest.NewRequest(http.MethodPost, "/feedback", http.NoBody)
+			r.Header.Set("Authorization", "Bearer "+testServiceToken)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			Convey("Then 401 Unauthorized is returned without calling the handler", func() {
+				So(w.Code, ShouldEqual, http.StatusUnauthorized)
+				So(handlerCalled, ShouldBeFalse)
+			})
+		})
+	})
+}
+
+func TestAuthoriseRead(t *testing.T) {
+	Convey("Given an API configured with some feedback readers, and a handler that reads feedback", t, func() {
+		cfg := testConfig()
+		cfg.FeedbackReaders = []string{"dp-feedback-dashboard", testServiceID}
+		a := &api.API{Cfg: cfg}
+
+		handlerCalled := false
+		h := a.AuthoriseRead(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handlerCalled = true
+		}))
+
+		Convey("When a request from a feedback reader is received", func() {
+			r := httptest.NewRequest(http.MethodGet, "/feedback", http.NoBody)
+			r = r.WithContext(dprequest.SetCaller(r.Context(), testServiceID))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			Convey("Then the handler is called", func() {
+				So(handlerCalled, ShouldBeTrue)
+			})
+		})
+
+		Convey("When a request from another caller is received", func() {
+			r := httptest.NewRequest(http.MethodGet, "/feedback", http.NoBody)
+			r = r.WithContext(dprequest.SetCaller(r.Context(), "dp-frontend-router"))
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+
+			Convey("Then 403 Forbidden is returned without calling the handler", func() {
+				So(w.Code, ShouldEqual, http.StatusForbidden)
+				So(handlerCalled, ShouldBeFalse)
+			})
+		})
+
+		Convey("When a request without a caller is received", func() {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/feedback", http.NoBody))
+
+			Convey("Then 403 Forbidden is returned without calling the handler", func() {
+				So(w.Code, ShouldEqual, http.StatusForbidden)
+				So(handlerCalled, ShouldBeFalse)
+			})
+		})
+	})
+
+	Convey("Given an API without any feedback readers", t, func() {
+		idClient := identityClientMock()
+		r := chi.NewRouter()
+		api.Setup(context.Background(), testConfig(), r, nil, nil, nil, nil, nil, idClient, nil)
+
+		Convey("When an authorised caller requests the stored feedback or its summary", func() {
+			for _, path := range []string{"/feedback", "/v1/feedback/summary", "/feedback/123"} {
+				req := httptest.NewRequest(http.MethodGet, path, http.NoBody)
+				req.Header.Set("Authorization", "Bearer "+testServiceToken)
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+
+				Convey("Then 403 Forbidden is returned for "+path, func() {
+					So(w.Code, ShouldEqual, http.StatusForbidden)
+				})
+			}
+		})
+	})
+}
+
+func TestHealthIsNotAuthorised(t *testing.T) {
+	Convey("Given an API mounted on a router that already serves /health", t, func() {
+		idClient := identityClientMock()
+		r := newRouterWithHealth()
+		api.Setup(context.Background(), testConfig(), r, nil, nil, nil, nil, nil, idClient, nil)
+
+		Convey("When /health is requested without an Authorization header", func() {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", http.NoBody))
+
+			Convey("Then it is served without being authorised", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(idClient.CheckRequestCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When /feedback is requested without an Authorization header", func() {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/feedback", http.NoBody))
+
+			Convey("Then 401 Unauthorized is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusUnauthorized)
+			})
+		})
+	})
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"expvar"
+	"strings"
+	"time"
+
+	"github.com/ONSdigital/dp-feedback-api/models"
+)
+
+// Reasons why a submission is detected as sent by a bot
+const (
+	// BotHoneypot is the reason for submissions with the honeypot field, which is hidden from users, filled in
+	BotHoneypot = "honeypot"
+	// BotTooFast is the reason for submissions sent faster than the minimum time to fill in the form
+	BotTooFast = "too_fast"
+)
+
+// BotSubmissions counts the submissions detected as sent by bots, by reason.
+// It is published by expvar as feedback_bot_submissions, and served to the feedback readers on /debug/vars, so that it can be monitored.
+var BotSubmissions = expvar.NewMap("feedback_bot_submissions")
+
+// detectBot returns the reason why the provided feedback, received at the provided time, is detected as sent by a bot,
+// or an empty string if it looks like it was sent by a person. The fill time is only checked if the form provided when it was rendered.
+func (api *API) detectBot(f *models.Feedback, receivedAt time.Time) string {
+	if strings.TrimSpace(f.Honeypot) != "" {
+		return BotHoneypot
+	}
+	if api.Cfg.MinFormFillTime > 0 && f.FormRenderedAt != nil && receivedAt.Sub(*f.FormRenderedAt) < api.Cfg.MinFormFillTime {
+		return BotTooFast
+	}
+	return ""
+}
@@ -1,20 +1,40 @@
 package api
 
 import (
-	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"path"
+	"time"
 
 	"github.com/ONSdigital/dp-feedback-api/models"
+	"github.com/ONSdigital/dp-feedback-api/store"
+	"github.com/ONSdigital/log.go/v2/log"
+	"github.com/go-chi/chi/v5"
+	"github.com/gofrs/uuid"
 )
 
-const (
-	WholeSite     = "The whole website"
-	ASpecificPage = "A specific page"
-)
+var errSpam = errors.New("feedback rejected as spam")
+
+// WholeSite is the ons_url value provided when the feedback is about the whole website rather than a page
+const WholeSite = models.WholeSite
+
+// NewID generates a new unique identifier for a feedback submission
+var NewID = func() string {
+	return uuid.Must(uuid.NewV4()).String()
+}
+
+// Now returns the current time, which is assigned to feedback submissions when they are received
+var Now = func() time.Time {
+	return time.Now().UTC()
+}
 
 // PostFeedback is the handler for POST /feedback
-// It unmarshals and validates the feedback data before sending to configured email account
+// It unmarshals and validates the feedback data, scores it for spam, redacts personal information from it, stores it, and then notifies the enabled notifiers (e.g. email or webhook)
+// if the page is not useful. Feedback is rejected or quarantined according to its spam score, and feedback sent by bots is discarded.
+// Near-duplicates of recent feedback from the same submitter are counted as occurrences of the original feedback.
+// The created feedback is returned in the response body, and its location in the Location header.
 func (api *API) PostFeedback(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -23,62 +43,168 @@ func (api *API) PostFeedback(w http.ResponseWriter, r *http.Request) {
 		api.handleError(ctx, w, err, http.StatusBadRequest)
 		return
 	}
+	feedback.ClearAssigned()
+	receivedAt := Now()
+
+	// Submissions from bots are accepted as if they were stored, so that the bots cannot tell they have been detected,
+	// but they are discarded without storing or notifying them. The honeypot field is never stored or returned.
+	reason := api.detectBot(feedback, receivedAt)
+	feedback.Honeypot = ""
+	if reason != "" {
+		BotSubmissions.Add(reason, 1)
+		feedback.ID = NewID()
+		feedback.ReceivedAt = &receivedAt
+		log.Info(ctx, "feedback sent by a bot discarded", log.Data{"reason": reason, "feedback_id": feedback.ID})
+		api.writeCreated(ctx, w, r, feedback)
+		return
+	}
 
-	if feedback.OnsURL != WholeSite {
-		if err := feedback.Validate(api.Cfg); err != nil {
-			api.handleError(ctx, w, err, http.StatusBadRequest)
-			return
+	if err := feedback.Validate(api.Cfg); err != nil {
+		api.handleError(ctx, w, err, http.StatusBadRequest)
+		return
+	}
+
+	if !*feedback.IsPageUseful && feedback.Feedback == "" {
+		api.handleError(ctx, w, fmt.Errorf("description is required if page is not useful"), http.StatusBadRequest)
+		return
+	}
+
+	// Spam is scored on the submitted text
+	feedback.Spam = api.Spam.Score(feedback)
+	if feedback.Spam != nil && feedback.Spam.Action == models.SpamActionReject {
+		log.Warn(ctx, "feedback rejected as spam", log.Data{"score": feedback.Spam.Score, "reasons": feedback.Spam.Reasons})
+		api.handleError(ctx, w, errSpam, http.StatusBadRequest)
+		return
+	}
+
+	// Personal information is redacted from the description before it is stored, published or notified.
+	// The feedback is otherwise stored as it was submitted, and only sanitized where it is output, e.g. in emails.
+	feedback.Redact(api.Cfg.Redact)
+
+	feedback.ID = NewID()
+	feedback.ReceivedAt = &receivedAt
+
+	// Near-duplicates of recent feedback from the same submitter, e.g. double submissions, are not stored or notified again,
+	// but counted as occurrences of the original feedback. If the original feedback is not stored yet, e.g. it is still being
+	// submitted by a concurrent request, or could not be stored, the near-duplicate is stored instead.
+	if api.Duplicates != nil {
+		if originalID := api.Duplicates.Check(feedback); originalID != "" {
+			if api.addOccurrence(ctx, w, r, originalID, feedback) {
+				return
+			}
+		}
+	}
+
+	// Every other submission is stored, including "Yes" answers from the feedback footer
+	if err := api.FeedbackStore.AddFeedback(ctx, feedback); err != nil {
+		if api.Duplicates != nil {
+			api.Duplicates.Forget(feedback.ID)
+		}
+		api.handleError(ctx, w, fmt.Errorf("failed to store feedback: %w", err), http.StatusInternalServerError)
+		return
+	}
+
+	// Every submission is published for the analytics pipeline, including "Yes" answers.
+	// The event is only queued, so that the response does not wait for the brokers.
+	// Failures are only logged, as the feedback has already been stored.
+	if api.Events != nil {
+		if err := api.Events.PublishFeedbackReceived(ctx, feedback); err != nil {
+			log.Error(ctx, "failed to publish feedback-received event", err, log.Data{"feedback_id": feedback.ID})
 		}
 	}
 
-	// Only send email if page is not useful
+	if feedback.IsQuarantined() {
+		log.Warn(ctx, "feedback quarantined as spam", log.Data{"feedback_id": feedback.ID, "score": feedback.Spam.Score, "reasons": feedback.Spam.Reasons})
+	}
+
+	// Only notify if page is not useful
 	// This is expected when the user chooses "Yes" from the feedback footer options
-	if !*feedback.IsPageUseful {
-		if feedback.Feedback != "" {
-			feedback.Sanitize(api.Cfg.Sanitize)
-
-			if err := api.EmailSender.Send(
-				api.Cfg.FeedbackFrom,
-				[]string{api.Cfg.FeedbackTo},
-				GenerateFeedbackMessage(feedback, api.Cfg.FeedbackFrom, api.Cfg.FeedbackTo),
-			); err != nil {
-				api.handleError(ctx, w, fmt.Errorf("failed to send message: %w", err), http.StatusInternalServerError)
-				return
+	// Feedback quarantined as spam is stored, but nobody is notified of it.
+	// Failures are only logged, as the feedback has already been stored, and every other notifier is still notified.
+	if !*feedback.IsPageUseful && !feedback.IsQuarantined() {
+		for _, n := range api.Notifiers {
+			if err := n.Notify(ctx, feedback); err != nil {
+				log.Error(ctx, "failed to notify feedback", err, log.Data{"feedback_id": feedback.ID})
 			}
-		} else {
-			api.handleError(ctx, w, fmt.Errorf("description is required if page is not useful"), http.StatusBadRequest)
 		}
 	}
 
-	w.WriteHeader(http.StatusCreated)
+	api.writeCreated(ctx, w, r, feedback)
 }
 
-func GenerateFeedbackMessage(f *models.Feedback, from, to string) []byte {
-	var b bytes.Buffer
+// addOccurrence counts the provided near-duplicate as one more occurrence of the original feedback with the provided id,
+// and writes the near-duplicate as the response, with the id, location and occurrences of the original feedback.
+// It returns false, without writing any response, if the original feedback is not found, so that the near-duplicate can be stored instead.
+func (api *API) addOccurrence(ctx context.Context, w http.ResponseWriter, r *http.Request, originalID string, feedback *models.Feedback) bool {
+	original, err := api.FeedbackStore.AddOccurrence(ctx, originalID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			log.Info(ctx, "original of near-duplicate feedback not found, it is stored instead", log.Data{"feedback_id": originalID})
+			return false
+		}
+		api.handleError(ctx, w, fmt.Errorf("failed to count near-duplicate feedback: %w", err), http.StatusInternalServerError)
+		return true
+	}
+	log.Info(ctx, "near-duplicate feedback counted as an occurrence of the original", log.Data{"feedback_id": original.ID, "occurrences": original.Occurrences})
+
+	// the original feedback is not returned, as it may differ from the near-duplicate, e.g. in the name of the submitter
+	duplicate := *feedback
+	duplicate.ID, duplicate.ReceivedAt, duplicate.Occurrences = original.ID, original.ReceivedAt, original.Occurrences
+	api.writeCreated(ctx, w, r, &duplicate)
+	return true
+}
 
-	b.WriteString(fmt.Sprintf("From: %s\n", from))
-	b.WriteString(fmt.Sprintf("To: %s\n", to))
-	b.WriteString("Subject: Feedback received\n\n")
+// writeCreated writes the provided feedback as the response body with 201 Created, and its location in the Location header.
+// Its spam result is left out, so that submitters cannot tell how their feedback was scored; it is only returned by GET.
+func (api *API) writeCreated(ctx context.Context, w http.ResponseWriter, r *http.Request, feedback *models.Feedback) {
+	created := *feedback
+	created.Spam = nil
+	w.Header().Set("Location", path.Join(r.URL.Path, created.ID))
+	api.writeJSON(ctx, w, &created, http.StatusCreated)
+}
 
-	if !*f.IsGeneralFeedback {
-		b.WriteString(fmt.Sprintf("Feedback Type: %s\n", ASpecificPage))
-	}
+// GetFeedback is the handler for GET /feedback/{id}
+// It returns the stored feedback with the provided id
+func (api *API) GetFeedback(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	id := chi.URLParam(r, "id")
 
-	if f.OnsURL != "" {
-		b.WriteString(fmt.Sprintf("Page URL: %s\n", f.OnsURL))
+	feedback, err := api.FeedbackStore.GetFeedback(ctx, id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			api.handleError(ctx, w, err, http.StatusNotFound)
+			return
+		}
+		api.handleError(ctx, w, fmt.Errorf("failed to get feedback: %w", err), http.StatusInternalServerError)
+		return
 	}
 
-	if f.Feedback != "" {
-		b.WriteString(fmt.Sprintf("Description: %s\n", f.Feedback))
+	api.writeJSON(ctx, w, feedback, http.StatusOK)
+}
+
+// GetFeedbackList is the handler for GET /feedback
+// It returns a page of the stored feedback that matches the filters provided as query parameters, newest first
+func (api *API) GetFeedbackList(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	offset, limit, err := api.getPagination(query)
+	if err != nil {
+		api.handleError(ctx, w, err, http.StatusBadRequest)
+		return
 	}
 
-	if f.Name != "" {
-		b.WriteString(fmt.Sprintf("Name: %s\n", f.Name))
+	filter, err := getFeedbackFilter(query)
+	if err != nil {
+		api.handleError(ctx, w, err, http.StatusBadRequest)
+		return
 	}
 
-	if f.EmailAddress != "" {
-		b.WriteString(fmt.Sprintf("Email address: %s\n", f.EmailAddress))
+	items, err := api.FeedbackStore.GetFeedbackList(ctx, filter)
+	if err != nil {
+		api.handleError(ctx, w, fmt.Errorf("failed to get feedback list: %w", err), http.StatusInternalServerError)
+		return
 	}
 
-	return b.Bytes()
+	api.writeJSON(ctx, w, models.NewFeedbackList(items, offset, limit), http.StatusOK)
 }
@@ -1,10 +1,25 @@
 package api_test
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/ONSdigital/dp-feedback-api/api"
+	"github.com/ONSdigital/dp-feedback-api/api/mock"
+	"github.com/ONSdigital/dp-feedback-api/config"
+	"github.com/ONSdigital/dp-feedback-api/event"
 	"github.com/ONSdigital/dp-feedback-api/models"
+	"github.com/ONSdigital/dp-feedback-api/spam"
+	"github.com/ONSdigital/dp-feedback-api/store"
+	"github.com/ONSdigital/dp-kafka/v4/avro"
+	"github.com/ONSdigital/dp-kafka/v4/kafkatest"
+	"github.com/go-chi/chi/v5"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
@@ -22,26 +37,6 @@ var (
 	notGeneralFeedBack = false
 )
 
-var expectedEmail = `From: sender@mail.com
-To: receiver@mail.com
-Subject: Feedback received
-
-Feedback Type: A specific page
-Page URL: https://testhost:1234/sub/path
-Description: very nice and useful website!
-Name: Mr Feedback reporter
-Email address: feedback@reporter.com
-`
-
-var expectedGeneralEmail = `From: sender@mail.com
-To: receiver@mail.com
-Subject: Feedback received
-
-Description: very nice and useful website!
-Name: Mr Feedback reporter
-Email address: feedback@reporter.com
-`
-
 func testFeedback() *models.Feedback {
 	return &models.Feedback{
 		IsPageUseful:      &isPageUseful,
@@ -63,16 +58,652 @@ func testGeneralFeedback() *models.Feedback {
 	}
 }
 
-func TestGenerateFeedbackMessage(t *testing.T) {
-	Convey("The expected email is generated from a valid feedback model", t, func() {
-		f := testFeedback()
-		generated := api.GenerateFeedbackMessage(f, "sender@mail.com", "receiver@mail.com")
-		So(string(generated), ShouldEqual, expectedEmail)
+func TestPostFeedback(t *testing.T) {
+	receivedAt := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+	newID, now := api.NewID, api.Now
+	defer func() { api.NewID, api.Now = newID, now }()
+	api.NewID = func() string { return "test-id" }
+	api.Now = func() time.Time { return receivedAt }
+
+	Convey("Given an API with a store and some notifiers", t, func() {
+		cfg := testConfig()
+		cfg.OnsDomain = "testhost"
+		cfg.Sanitize = &config.Sanitize{}
+		storeMock := &mock.FeedbackStoreMock{
+			AddFeedbackFunc: func(ctx context.Context, f *models.Feedback) error { return nil },
+		}
+		emailMock := &mock.NotifierMock{
+			NotifyFunc: func(ctx context.Context, f *models.Feedback) error { return nil },
+		}
+		webhookMock := &mock.NotifierMock{
+			NotifyFunc: func(ctx context.Context, f *models.Feedback) error { return nil },
+		}
+		eventsMock := &mock.EventPublisherMock{
+			PublishFeedbackReceivedFunc: func(ctx context.Context, f *models.Feedback) error { return nil },
+		}
+		a := &api.API{Cfg: cfg, FeedbackStore: storeMock, Notifiers: []api.Notifier{emailMock, webhookMock}, Events: eventsMock}
+
+		Convey("When valid feedback is posted", func() {
+			w := httptest.NewRecorder()
+			a.PostFeedback(w, httptest.NewRequest(http.MethodPost, "/v1/feedback", body(feedbackPayload)))
+
+			Convey("Then 201 Created is returned with the created feedback and its location", func() {
+				expected := testFeedback()
+				expected.ID = "test-id"
+				expected.ReceivedAt = &receivedAt
+
+				So(w.Code, ShouldEqual, http.StatusCreated)
+				So(w.Header().Get("Location"), ShouldEqual, "/v1/feedback/test-id")
+				created := &models.Feedback{}
+				So(json.Unmarshal(w.Body.Bytes(), created), ShouldBeNil)
+				So(created, ShouldResemble, expected)
+			})
+
+			Convey("Then the feedback is stored with the generated id and timestamp", func() {
+				So(storeMock.AddFeedbackCalls(), ShouldHaveLength, 1)
+				So(storeMock.AddFeedbackCalls()[0].F.ID, ShouldEqual, "test-id")
+				So(*storeMock.AddFeedbackCalls()[0].F.ReceivedAt, ShouldEqual, receivedAt)
+			})
+
+			Convey("Then no notifier is notified, as the page is useful", func() {
+				So(emailMock.NotifyCalls(), ShouldBeEmpty)
+				So(webhookMock.NotifyCalls(), ShouldBeEmpty)
+			})
+
+			Convey("Then the feedback-received event is published", func() {
+				So(eventsMock.PublishFeedbackReceivedCalls(), ShouldHaveLength, 1)
+				So(eventsMock.PublishFeedbackReceivedCalls()[0].F.ID, ShouldEqual, "test-id")
+			})
+		})
+
+		Convey("When valid feedback for a page that is not useful is posted", func() {
+			w := httptest.NewRecorder()
+			payload := `{"is_page_useful": false, "is_general_feedback": false, "ons_url": "https://testhost/sub/path", "feedback": "broken link"}`
+			a.PostFeedback(w, httptest.NewRequest(http.MethodPost, "/v1/feedback", body(payload)))
+
+			Convey("Then 201 Created is returned and the feedback is stored", func() {
+				So(w.Code, ShouldEqual, http.StatusCreated)
+				So(storeMock.AddFeedbackCalls(), ShouldHaveLength, 1)
+			})
+
+			Convey("Then the feedback-received event is published", func() {
+				So(eventsMock.PublishFeedbackReceivedCalls(), ShouldHaveLength, 1)
+			})
+
+			Convey("Then all the notifiers are notified of the stored feedback", func() {
+				So(emailMock.NotifyCalls(), ShouldHaveLength, 1)
+				So(emailMock.NotifyCalls()[0].F.ID, ShouldEqual, "test-id")
+				So(webhookMock.NotifyCalls(), ShouldHaveLength, 1)
+				So(webhookMock.NotifyCalls()[0].F.ID, ShouldEqual, "test-id")
+			})
+		})
+
+		Convey("When publishing the feedback-received event fails", func() {
+			eventsMock.PublishFeedbackReceivedFunc = func(ctx context.Context, f *models.Feedback) error {
+				return errors.New("kafka error")
+			}
+			w := httptest.NewRecorder()
+			a.PostFeedback(w, httptest.NewRequest(http.MethodPost, "/v1/feedback", body(feedbackPayload)))
+
+			Convey("Then 201 Created is still returned, as the feedback is stored", func() {
+				So(w.Code, ShouldEqual, http.StatusCreated)
+				So(storeMock.AddFeedbackCalls(), ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("When the kafka producer cannot reach the brokers", func() {
+			producer := &kafkatest.IProducerMock{
+				IsInitialisedFunc: func() bool { return false },
+			}
+			a.Events = event.NewPublisher(producer, 1)
+			w := httptest.NewRecorder()
+			payload := `{"is_page_useful": false, "is_general_feedback": true, "feedback": "broken link"}`
+			a.PostFeedback(w, httptest.NewRequest(http.MethodPost, "/v1/feedback", body(payload)))
+
+			Convey("Then 201 Created is returned, and the notifiers are notified, without sending the event", func() {
+				So(w.Code, ShouldEqual, http.StatusCreated)
+				So(storeMock.AddFeedbackCalls(), ShouldHaveLength, 1)
+				So(emailMock.NotifyCalls(), ShouldHaveLength, 1)
+				So(producer.SendCalls(), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the kafka producer blocks", func() {
+			sending, unblock := make(chan struct{}, 1), make(chan struct{})
+			producer := &kafkatest.IProducerMock{
+				IsInitialisedFunc: func() bool { return true },
+				SendFunc: func(ctx context.Context, s *avro.Schema, event interface{}) error {
+					sending <- struct{}{}
+					<-unblock
+					return nil
+				},
+			}
+			events := event.NewPublisher(producer, 1)
+			events.Start(context.Background())
+			a.Events = events
+
+			done := make(chan *httptest.ResponseRecorder)
+			go func() {
+				w := httptest.NewRecorder()
+				payload := `{"is_page_useful": false, "is_general_feedback": true, "feedback": "broken link"}`
+				a.PostFeedback(w, httptest.NewRequest(http.MethodPost, "/v1/feedback", body(payload)))
+				done <- w
+			}()
+
+			Convey("Then 201 Created is returned, and the notifiers are notified, without waiting for the event to be sent", func() {
+				select {
+				case w := <-done:
+					So(w.Code, ShouldEqual, http.StatusCreated)
+				case <-time.After(5 * time.Second):
+					t.Fatal("timed out waiting for the response")
+				}
+				So(emailMock.NotifyCalls(), ShouldHaveLength, 1)
+
+				<-sending
+				close(unblock)
+				So(events.Close(context.Background()), ShouldBeNil)
+			})
+		})
+
+		Convey("When a notifier fails", func() {
+			emailMock.NotifyFunc = func(ctx context.Context, f *models.Feedback) error {
+				return errors.New("smtp error")
+			}
+			w := httptest.NewRecorder()
+			payload := `{"is_page_useful": false, "is_general_feedback": true, "feedback": "broken link"}`
+			a.PostFeedback(w, httptest.NewRequest(http.MethodPost, "/v1/feedback", body(payload)))
+
+			Convey("Then 201 Created is still returned, as the feedback is stored, and the other notifiers are notified", func() {
+				So(w.Code, ShouldEqual, http.StatusCreated)
+				So(storeMock.AddFeedbackCalls(), ShouldHaveLength, 1)
+				So(webhookMock.NotifyCalls(), ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("When feedback is posted with the fields assigned by the API", func() {
+			w := httptest.NewRecorder()
+			payload := `{"id": "client-id", "received_at": "2020-01-01T00:00:00Z", "is_page_useful": false, "is_general_feedback": true, "feedback": "broken link",
+				"spam": {"score": 0}, "redactions": [{"type": "postcode", "count": 1}], "occurrences": 100}`
+			a.PostFeedback(w, httptest.NewRequest(http.MethodPost, "/v1/feedback", body(payload)))
+
+			Convey("Then they are replaced or cleared before the feedback is stored", func() {
+				So(w.Code, ShouldEqual, http.StatusCreated)
+				So(storeMock.AddFeedbackCalls(), ShouldHaveLength, 1)
+				stored := storeMock.AddFeedbackCalls()[0].F
+				So(stored.ID, ShouldEqual, "test-id")
+				So(*stored.ReceivedAt, ShouldEqual, receivedAt)
+				So(stored.Spam, ShouldBeNil)
+				So(stored.Redactions, ShouldBeNil)
+				So(stored.Occurrences, ShouldEqual, 0)
+			})
+		})
+
+		Convey("When feedback that fails validation is posted", func() {
+			w := httptest.NewRecorder()
+			a.PostFeedback(w, httptest.NewRequest(http.MethodPost, "/v1/feedback", body(`{"is_page_useful": false}`)))
+
+			Convey("Then 400 Bad Request is returned and nothing is stored or notified", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(storeMock.AddFeedbackCalls(), ShouldHaveLength, 0)
+				So(emailMock.NotifyCalls(), ShouldHaveLength, 0)
+				So(eventsMock.PublishFeedbackReceivedCalls(), ShouldHaveLength, 0)
+			})
+
+			Convey("Then the response body is a JSON error containing the fields that failed validation", func() {
+				So(w.Header().Get("Content-Type"), ShouldEqual, "application/json")
+				errResp := &models.ErrorResponse{}
+				So(json.Unmarshal(w.Body.Bytes(), errResp), ShouldBeNil)
+				So(errResp, ShouldResemble, &models.ErrorResponse{
+					Code:    models.ErrCodeValidationFailed,
+					Message: "request body failed validation",
+					Errors:  []models.FieldError{{Field: "IsGeneralFeedback", JSONName: "is_general_feedback", Rule: "required"}},
+				})
+			})
+		})
+
+		Convey("When feedback about the whole website is posted", func() {
+			w := httptest.NewRecorder()
+			payload := `{"is_page_useful": false, "is_general_feedback": true, "ons_url": "The whole website", "feedback": "broken link"}`
+			a.PostFeedback(w, httptest.NewRequest(http.MethodPost, "/v1/feedback", body(payload)))
+
+			Convey("Then 201 Created is returned, as its ons_url is not checked against the allowed domains", func() {
+				So(w.Code, ShouldEqual, http.StatusCreated)
+				So(storeMock.AddFeedbackCalls(), ShouldHaveLength, 1)
+				So(storeMock.AddFeedbackCalls()[0].F.OnsURL, ShouldEqual, api.WholeSite)
+			})
+		})
+
+		Convey("When feedback about the whole website is posted without is_page_useful", func() {
+			w := httptest.NewRecorder()
+			payload := `{"is_general_feedback": true, "ons_url": "The whole website", "feedback": "broken link"}`
+			a.PostFeedback(w, httptest.NewRequest(http.MethodPost, "/v1/feedback", body(payload)))
+
+			Convey("Then 400 Bad Request is returned with the field that failed validation, and nothing is stored", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				errResp := &models.ErrorResponse{}
+				So(json.Unmarshal(w.Body.Bytes(), errResp), ShouldBeNil)
+				So(errResp.Errors, ShouldResemble, []models.FieldError{{Field: "IsPageUseful", JSONName: "is_page_useful", Rule: "required"}})
+				So(storeMock.AddFeedbackCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When feedback with the honeypot field filled in is posted", func() {
+			honeypotCount := botSubmissions(api.BotHoneypot)
+			w := httptest.NewRecorder()
+			payload := `{"is_page_useful": false, "is_general_feedback": true, "feedback": "nice", "honeypot": "bot"}`
+			a.PostFeedback(w, httptest.NewRequest(http.MethodPost, "/v1/feedback", body(payload)))
+
+			Convey("Then 201 Created is returned as if it was stored, without the honeypot field", func() {
+				So(w.Code, ShouldEqual, http.StatusCreated)
+				So(w.Header().Get("Location"), ShouldEqual, "/v1/feedback/test-id")
+				So(w.Body.String(), ShouldNotContainSubstring, "honeypot")
+			})
+
+			Convey("Then it is discarded without storing, publishing or notifying it", func() {
+				So(storeMock.AddFeedbackCalls(), ShouldBeEmpty)
+				So(eventsMock.PublishFeedbackReceivedCalls(), ShouldBeEmpty)
+				So(emailMock.NotifyCalls(), ShouldBeEmpty)
+				So(webhookMock.NotifyCalls(), ShouldBeEmpty)
+			})
+
+			Convey("Then it is counted as a bot submission", func() {
+				So(botSubmissions(api.BotHoneypot), ShouldEqual, honeypotCount+1)
+			})
+		})
+
+		Convey("Given a minimum time to fill in the form", func() {
+			cfg.MinFormFillTime = 2 * time.Second
+
+			Convey("When feedback is posted faster than the minimum time since the form was rendered", func() {
+				tooFastCount := botSubmissions(api.BotTooFast)
+				w := httptest.NewRecorder()
+				payload := `{"is_page_useful": false, "is_general_feedback": true, "feedback": "nice", "form_rendered_at": "2024-03-15T10:29:59Z"}`
+				a.PostFeedback(w, httptest.NewRequest(http.MethodPost, "/v1/feedback", body(payload)))
+
+				Convey("Then 201 Created is returned, but it is discarded and counted as a bot submission", func() {
+					So(w.Code, ShouldEqual, http.StatusCreated)
+					So(storeMock.AddFeedbackCalls(), ShouldBeEmpty)
+					So(emailMock.NotifyCalls(), ShouldBeEmpty)
+					So(botSubmissions(api.BotTooFast), ShouldEqual, tooFastCount+1)
+				})
+			})
+
+			Convey("When feedback is posted after the minimum time since the form was rendered", func() {
+				w := httptest.NewRecorder()
+				payload := `{"is_page_useful": false, "is_general_feedback": true, "feedback": "nice", "form_rendered_at": "2024-03-15T10:29:58Z"}`
+				a.PostFeedback(w, httptest.NewRequest(http.MethodPost, "/v1/feedback", body(payload)))
+
+				Convey("Then it is stored and notified", func() {
+					So(w.Code, ShouldEqual, http.StatusCreated)
+					So(storeMock.AddFeedbackCalls(), ShouldHaveLength, 1)
+					So(emailMock.NotifyCalls(), ShouldHaveLength, 1)
+				})
+			})
+
+			Convey("When feedback is posted without the time the form was rendered", func() {
+				w := httptest.NewRecorder()
+				payload := `{"is_page_useful": false, "is_general_feedback": true, "feedback": "nice"}`
+				a.PostFeedback(w, httptest.NewRequest(http.MethodPost, "/v1/feedback", body(payload)))
+
+				Convey("Then the fill time is not checked, and it is stored", func() {
+					So(w.Code, ShouldEqual, http.StatusCreated)
+					So(storeMock.AddFeedbackCalls(), ShouldHaveLength, 1)
+				})
+			})
+		})
+
+		Convey("Given that the API detects near-duplicates", func() {
+			originalReceivedAt := receivedAt.Add(-time.Minute)
+			original := &models.Feedback{ID: "original-id", ReceivedAt: &originalReceivedAt, Feedback: "broken link!", Name: "Someone Else",
+				EmailAddress: "someone@example.com", Occurrences: 2}
+			duplicatesMock := &mock.DuplicateDetectorMock{
+				CheckFunc:  func(f *models.Feedback) string { return "" },
+				ForgetFunc: func(id string) {},
+			}
+			storeMock.AddOccurrenceFunc = func(ctx context.Context, id string) (*models.Feedback, error) { return original, nil }
+			a.Duplicates = duplicatesMock
+			payload := `{"is_page_useful": false, "is_general_feedback": true, "feedback": "broken link", "email_address": "someone@example.com"}`
+
+			Convey("When feedback that is not a near-duplicate is posted", func() {
+				w := httptest.NewRecorder()
+				a.PostFeedback(w, httptest.NewRequest(http.MethodPost, "/v1/feedback", body(payload)))
+
+				Convey("Then it is checked with its id, and stored and notified", func() {
+					So(duplicatesMock.CheckCalls(), ShouldHaveLength, 1)
+					So(duplicatesMock.CheckCalls()[0].F.ID, ShouldEqual, "test-id")
+					So(w.Code, ShouldEqual, http.StatusCreated)
+					So(storeMock.AddFeedbackCalls(), ShouldHaveLength, 1)
+					So(storeMock.AddOccurrenceCalls(), ShouldBeEmpty)
+					So(emailMock.NotifyCalls(), ShouldHaveLength, 1)
+				})
+			})
+
+			Convey("When a near-duplicate of recent feedback is posted", func() {
+				duplicatesMock.CheckFunc = func(f *models.Feedback) string { return "original-id" }
+				w := httptest.NewRecorder()
+				a.PostFeedback(w, httptest.NewRequest(http.MethodPost, "/v1/feedback", body(payload)))
+
+				Convey("Then it is counted as an occurrence of the original, without storing, publishing or notifying it", func() {
+					So(storeMock.AddOccurrenceCalls(), ShouldHaveLength, 1)
+					So(storeMock.AddOccurrenceCalls()[0].ID, ShouldEqual, "original-id")
+					So(storeMock.AddFeedbackCalls(), ShouldBeEmpty)
+					So(eventsMock.PublishFeedbackReceivedCalls(), ShouldBeEmpty)
+					So(emailMock.NotifyCalls(), ShouldBeEmpty)
+					So(webhookMock.NotifyCalls(), ShouldBeEmpty)
+				})
+
+				Convey("Then 201 Created is returned with the posted feedback, and the id, location and occurrences of the original", func() {
+					isPageUseful := false
+					So(w.Code, ShouldEqual, http.StatusCreated)
+					So(w.Header().Get("Location"), ShouldEqual, "/v1/feedback/original-id")
+					returned := &models.Feedback{}
+					So(json.Unmarshal(w.Body.Bytes(), returned), ShouldBeNil)
+					So(returned, ShouldResemble, &models.Feedback{
+						ID:                "original-id",
+						ReceivedAt:        &originalReceivedAt,
+						IsPageUseful:      &isPageUseful,
+						IsGeneralFeedback: &isGeneralFeedback,
+						Feedback:          "broken link",
+						EmailAddress:      "someone@example.com",
+						Occurrences:       2,
+					})
+				})
+			})
+
+			Convey("When a near-duplicate of feedback that is not stored yet is posted", func() {
+				duplicatesMock.CheckFunc = func(f *models.Feedback) string { return "original-id" }
+				storeMock.AddOccurrenceFunc = func(ctx context.Context, id string) (*models.Feedback, error) { return nil, store.ErrNotFound }
+				w := httptest.NewRecorder()
+				a.PostFeedback(w, httptest.NewRequest(http.MethodPost, "/v1/feedback", body(payload)))
+
+				Convey("Then it is stored and notified instead", func() {
+					So(w.Code, ShouldEqual, http.StatusCreated)
+					So(w.Header().Get("Location"), ShouldEqual, "/v1/feedback/test-id")
+					So(storeMock.AddFeedbackCalls(), ShouldHaveLength, 1)
+					So(storeMock.AddFeedbackCalls()[0].F.ID, ShouldEqual, "test-id")
+					So(emailMock.NotifyCalls(), ShouldHaveLength, 1)
+				})
+			})
+
+			Convey("When a near-duplicate is posted and its occurrence cannot be counted", func() {
+				duplicatesMock.CheckFunc = func(f *models.Feedback) string { return "original-id" }
+				storeMock.AddOccurrenceFunc = func(ctx context.Context, id string) (*models.Feedback, error) { return nil, errors.New("store error") }
+				w := httptest.NewRecorder()
+				a.PostFeedback(w, httptest.NewRequest(http.MethodPost, "/v1/feedback", body(payload)))
+
+				Convey("Then 500 Internal Server Error is returned", func() {
+					So(w.Code, ShouldEqual, http.StatusInternalServerError)
+				})
+			})
+
+			Convey("When feedback that is not a near-duplicate cannot be stored", func() {
+				storeMock.AddFeedbackFunc = func(ctx context.Context, f *models.Feedback) error { return errors.New("store error") }
+				w := httptest.NewRecorder()
+				a.PostFeedback(w, httptest.NewRequest(http.MethodPost, "/v1/feedback", body(payload)))
+
+				Convey("Then it is forgotten by the detector, so that its retries are not counted as near-duplicates", func() {
+					So(w.Code, ShouldEqual, http.StatusInternalServerError)
+					So(duplicatesMock.ForgetCalls(), ShouldHaveLength, 1)
+					So(duplicatesMock.ForgetCalls()[0].ID, ShouldEqual, "test-id")
+				})
+			})
+		})
+
+		Convey("Given that the API redacts personal information", func() {
+			cfg.Redact = &config.Redact{NINO: true, PhoneNumber: true, Postcode: true, CardNumber: true}
+
+			Convey("When feedback with personal information in its description is posted", func() {
+				w := httptest.NewRecorder()
+				payload := `{"is_page_useful": false, "is_general_feedback": true, "feedback": "call me on 07700 900123", "redactions": [{"type": "provided", "count": 1}]}`
+				a.PostFeedback(w, httptest.NewRequest(http.MethodPost, "/v1/feedback", body(payload)))
+
+				Convey("Then it is stored, published and notified with the personal information redacted", func() {
+					expectedRedactions := []models.Redaction{{Type: models.RedactionPhoneNumber, Count: 1}}
+					So(w.Code, ShouldEqual, http.StatusCreated)
+					So(storeMock.AddFeedbackCalls(), ShouldHaveLength, 1)
+					So(storeMock.AddFeedbackCalls()[0].F.Feedback, ShouldEqual, "call me on [REDACTED PHONE NUMBER]")
+					So(storeMock.AddFeedbackCalls()[0].F.Redactions, ShouldResemble, expectedRedactions)
+					So(eventsMock.PublishFeedbackReceivedCalls()[0].F.Feedback, ShouldEqual, "call me on [REDACTED PHONE NUMBER]")
+					So(emailMock.NotifyCalls()[0].F.Feedback, ShouldEqual, "call me on [REDACTED PHONE NUMBER]")
+				})
+
+				Convey("Then the created feedback is returned with the redactions applied", func() {
+					created := &models.Feedback{}
+					So(json.Unmarshal(w.Body.Bytes(), created), ShouldBeNil)
+					So(created.Feedback, ShouldEqual, "call me on [REDACTED PHONE NUMBER]")
+					So(created.Redactions, ShouldResemble, []models.Redaction{{Type: models.RedactionPhoneNumber, Count: 1}})
+				})
+			})
+		})
+
+		Convey("Given that the API scores feedback for spam", func() {
+			domains, err := cfg.DomainRules()
+			So(err, ShouldBeNil)
+			a.Spam = spam.NewScorer(&config.Spam{TagThreshold: 3, QuarantineThreshold: 6, RejectThreshold: 10, Phrases: []string{"casino"}}, domains)
+
+			Convey("When feedback with a link to another site is posted", func() {
+				w := httptest.NewRecorder()
+				payload := `{"is_page_useful": false, "is_general_feedback": true, "feedback": "see www.example.com"}`
+				a.PostFeedback(w, httptest.NewRequest(http.MethodPost, "/v1/feedback", body(payload)))
+
+				Convey("Then it is stored and notified, tagged as possible spam", func() {
+					So(w.Code, ShouldEqual, http.StatusCreated)
+					So(storeMock.AddFeedbackCalls(), ShouldHaveLength, 1)
+					So(storeMock.AddFeedbackCalls()[0].F.Spam, ShouldResemble, &models.Spam{
+						Score:   spam.LinkScore + spam.ExternalLinkScore,
+						Reasons: []string{spam.ReasonLinks, spam.ReasonExternalLinks},
+						Action:  models.SpamActionTag,
+					})
+					So(emailMock.NotifyCalls(), ShouldHaveLength, 1)
+					So(webhookMock.NotifyCalls(), ShouldHaveLength, 1)
+				})
+
+				Convey("And the spam result is not returned in the response", func() {
+					So(w.Body.String(), ShouldNotContainSubstring, `"spam"`)
+				})
+			})
+
+			Convey("When feedback that reaches the quarantine threshold is posted", func() {
+				w := httptest.NewRecorder()
+				payload := `{"is_page_useful": false, "is_general_feedback": true, "feedback": "best casino at www.example.com"}`
+				a.PostFeedback(w, httptest.NewRequest(http.MethodPost, "/v1/feedback", body(payload)))
+
+				Convey("Then it is stored and published, but no notifier is notified", func() {
+					So(w.Code, ShouldEqual, http.StatusCreated)
+					So(storeMock.AddFeedbackCalls(), ShouldHaveLength, 1)
+					So(storeMock.AddFeedbackCalls()[0].F.IsQuarantined(), ShouldBeTrue)
+					So(eventsMock.PublishFeedbackReceivedCalls(), ShouldHaveLength, 1)
+					So(emailMock.NotifyCalls(), ShouldBeEmpty)
+					So(webhookMock.NotifyCalls(), ShouldBeEmpty)
+				})
+			})
+
+			Convey("When feedback without any signs of spam is posted with a spam result and a honeypot", func() {
+				w := httptest.NewRecorder()
+				payload := `{"is_page_useful": false, "is_general_feedback": true, "feedback": "broken link", "honeypot": " ", "spam": {"score": 0, "action": "tag"}}`
+				a.PostFeedback(w, httptest.NewRequest(http.MethodPost, "/v1/feedback", body(payload)))
+
+				Convey("Then they are not stored", func() {
+					So(w.Code, ShouldEqual, http.StatusCreated)
+					So(storeMock.AddFeedbackCalls()[0].F.Spam, ShouldBeNil)
+					So(storeMock.AddFeedbackCalls()[0].F.Honeypot, ShouldBeEmpty)
+				})
+			})
+		})
+	})
+}
+
+func TestGetFeedback(t *testing.T) {
+	receivedAt := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+	stored := &models.Feedback{ID: "123", ReceivedAt: &receivedAt, IsPageUseful: &isPageUseful, IsGeneralFeedback: &isGeneralFeedback}
+
+	Convey("Given an API with a store", t, func() {
+		storeMock := &mock.FeedbackStoreMock{
+			GetFeedbackFunc: func(ctx context.Context, id string) (*models.Feedback, error) {
+				switch id {
+				case "123":
+					return stored, nil
+				case "broken":
+					return nil, errors.New("store error")
+				default:
+					return nil, store.ErrNotFound
+				}
+			},
+		}
+		a := &api.API{Cfg: testConfig(), FeedbackStore: storeMock}
+		r := chi.NewRouter()
+		r.Get("/feedback/{id}", a.GetFeedback)
+
+		Convey("When GET /feedback/{id} is called for stored feedback", func() {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/feedback/123", http.NoBody))
+
+			Convey("Then 200 OK is returned with the stored feedback", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				f := &models.Feedback{}
+				So(json.Unmarshal(w.Body.Bytes(), f), ShouldBeNil)
+				So(f, ShouldResemble, stored)
+			})
+		})
+
+		Convey("When GET /feedback/{id} is called for feedback that is not stored", func() {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/feedback/unknown", http.NoBody))
+
+			Convey("Then 404 Not Found is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusNotFound)
+			})
+		})
+
+		Convey("When GET /feedback/{id} is called and the store fails", func() {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/feedback/broken", http.NoBody))
+
+			Convey("Then 500 Internal Server Error is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusInternalServerError)
+			})
+		})
+	})
+}
+
+func TestGetFeedbackList(t *testing.T) {
+	receivedAt := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+	stored := []*models.Feedback{
+		{ID: "1", ReceivedAt: &receivedAt, IsPageUseful: &isPageUseful, IsGeneralFeedback: &notGeneralFeedBack},
+		{ID: "2", ReceivedAt: &receivedAt, IsPageUseful: &isPageUseful, IsGeneralFeedback: &notGeneralFeedBack},
+		{ID: "3", ReceivedAt: &receivedAt, IsPageUseful: &isPageUseful, IsGeneralFeedback: &notGeneralFeedBack},
+	}
+
+	Convey("Given an API with a store containing some feedback", t, func() {
+		storeMock := &mock.FeedbackStoreMock{
+			GetFeedbackListFunc: func(ctx context.Context, filter *models.FeedbackFilter) ([]*models.Feedback, error) {
+				return stored, nil
+			},
+		}
+		a := &api.API{Cfg: testConfig(), FeedbackStore: storeMock}
+
+		Convey("When GET /feedback is called without query parameters", func() {
+			w := httptest.NewRecorder()
+			a.GetFeedbackList(w, httptest.NewRequest(http.MethodGet, "/feedback", http.NoBody))
+
+			Convey("Then 200 OK is returned with all the feedback and the default pagination values", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Header().Get("Content-Type"), ShouldEqual, "application/json")
+				list := &models.FeedbackList{}
+				So(json.Unmarshal(w.Body.Bytes(), list), ShouldBeNil)
+				So(list.Items, ShouldResemble, stored)
+				So(list.Count, ShouldEqual, 3)
+				So(list.Offset, ShouldEqual, 0)
+				So(list.Limit, ShouldEqual, 20)
+				So(list.TotalCount, ShouldEqual, 3)
+			})
+
+			Convey("Then the store is called with an empty filter", func() {
+				So(storeMock.GetFeedbackListCalls(), ShouldHaveLength, 1)
+				So(storeMock.GetFeedbackListCalls()[0].Filter, ShouldResemble, &models.FeedbackFilter{})
+			})
+		})
+
+		Convey("When GET /feedback is called with filters and pagination", func() {
+			w := httptest.NewRecorder()
+			url := "/feedback?offset=1&limit=1&is_page_useful=false&is_general_feedback=true" +
+				"&ons_url=https://www.ons.gov.uk/economy&start_date=2024-03-01&end_date=2024-03-15"
+			a.GetFeedbackList(w, httptest.NewRequest(http.MethodGet, url, http.NoBody))
+
+			Convey("Then 200 OK is returned with the requested page", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				list := &models.FeedbackList{}
+				So(json.Unmarshal(w.Body.Bytes(), list), ShouldBeNil)
+				So(list.Items, ShouldResemble, stored[1:2])
+				So(list.Count, ShouldEqual, 1)
+				So(list.Offset, ShouldEqual, 1)
+				So(list.Limit, ShouldEqual, 1)
+				So(list.TotalCount, ShouldEqual, 3)
+			})
+
+			Convey("Then the store is called with the expected filter, including the whole end date", func() {
+				start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+				end := time.Date(2024, 3, 16, 0, 0, 0, 0, time.UTC)
+				So(storeMock.GetFeedbackListCalls(), ShouldHaveLength, 1)
+				So(storeMock.GetFeedbackListCalls()[0].Filter, ShouldResemble, &models.FeedbackFilter{
+					IsPageUseful:      &notGeneralFeedBack,
+					IsGeneralFeedback: &isGeneralFeedback,
+					OnsURLPrefix:      "https://www.ons.gov.uk/economy",
+					ReceivedAfter:     &start,
+					ReceivedBefore:    &end,
+				})
+			})
+		})
+
+		Convey("When GET /feedback is called with invalid query parameters", func() {
+			for _, query := range []string{
+				"offset=-1",
+				"limit=abc",
+				"limit=101",
+				"is_page_useful=maybe",
+				"is_general_feedback=1x",
+				"start_date=yesterday",
+				"end_date=15/03/2024",
+				"start_date=2024-03-15&end_date=2024-03-01",
+			} {
+				w := httptest.NewRecorder()
+				a.GetFeedbackList(w, httptest.NewRequest(http.MethodGet, "/feedback?"+query, http.NoBody))
+
+				Convey("Then 400 Bad Request is returned for "+query, func() {
+					So(w.Code, ShouldEqual, http.StatusBadRequest)
+				})
+			}
+
+			Convey("Then the store is not called", func() {
+				So(storeMock.GetFeedbackListCalls(), ShouldHaveLength, 0)
+			})
+		})
 	})
 
-	Convey("The expected general email is generated from a valid feedback model", t, func() {
-		f := testGeneralFeedback()
-		generated := api.GenerateFeedbackMessage(f, "sender@mail.com", "receiver@mail.com")
-		So(string(generated), ShouldEqual, expectedGeneralEmail)
+	Convey("Given an API with a store that fails to list feedback", t, func() {
+		storeMock := &mock.FeedbackStoreMock{
+			GetFeedbackListFunc: func(ctx context.Context, filter *models.FeedbackFilter) ([]*models.Feedback, error) {
+				return nil, errors.New("store error")
+			},
+		}
+		a := &api.API{Cfg: testConfig(), FeedbackStore: storeMock}
+
+		Convey("When GET /feedback is called", func() {
+			w := httptest.NewRecorder()
+			a.GetFeedbackList(w, httptest.NewRequest(http.MethodGet, "/feedback", http.NoBody))
+
+			Convey("Then 500 Internal Server Error is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusInternalServerError)
+			})
+		})
 	})
 }
+
+// botSubmissions returns the number of bot submissions counted for the provided reason
+func botSubmissions(reason string) int64 {
+	v, ok := api.BotSubmissions.Get(reason).(*expvar.Int)
+	if !ok {
+		return 0
+	}
+	return v.Value()
+}
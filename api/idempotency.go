@@ -0,0 +1,133 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/ONSdigital/dp-feedback-api/idempotency"
+	dprequest "github.com/ONSdigital/dp-net/v3/request"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+const (
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+)
+
+var errIdempotencyKeyTooLong = fmt.Errorf("%s header must not be longer than %d characters", idempotencyKeyHeader, maxIdempotencyKeyLength)
+
+// Idempotent is a middleware that caches the responses to the requests with an Idempotency-Key header, so that their retries
+// get the original response, with an Idempotent-Replayed header, instead of being processed again (e.g. sending the same email twice).
+// The keys are scoped per caller. A request that reuses a key with a different body is rejected with 422 Unprocessable Entity,
+// and a request sent while another one with the same key is in progress is rejected with 409 Conflict.
+// Server errors and 429 Too Many Requests are not cached, so that the request can be retried. Requests are processed as usual without a key or a cache.
+func (api *API) Idempotent(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyKeyHeader)
+		if api.IdempotencyCache == nil || key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := r.Context()
+		if len(key) > maxIdempotencyKeyLength {
+			api.handleError(ctx, w, errIdempotencyKeyTooLong, http.StatusBadRequest)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			api.handleError(ctx, w, fmt.Errorf("failed to read req body: %w", err), http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		fingerprint := sha256.Sum256(body)
+
+		// keys are scoped per caller, so that callers cannot get the responses to each other's requests
+		scopedKey := dprequest.Caller(ctx) + "\x00" + key
+		cached, err := api.IdempotencyCache.Begin(scopedKey, hex.EncodeToString(fingerprint[:]))
+		switch {
+		case errors.Is(err, idempotency.ErrInProgress):
+			api.handleError(ctx, w, err, http.StatusConflict)
+			return
+		case errors.Is(err, idempotency.ErrKeyReused):
+			api.handleError(ctx, w, err, http.StatusUnprocessableEntity)
+			return
+		case err != nil:
+			api.handleError(ctx, w, fmt.Errorf("failed to check idempotency key: %w", err), http.StatusInternalServerError)
+			return
+		case cached != nil:
+			log.Info(ctx, "returning the cached response to a request with the same idempotency key", log.Data{"status": cached.Status})
+			replay(ctx, w, cached)
+			return
+		}
+
+		rec := &responseRecorder{ResponseWriter: w}
+		completed := false
+		defer func() {
+			if !completed {
+				api.IdempotencyCache.Cancel(scopedKey)
+			}
+		}()
+
+		next.ServeHTTP(rec, r)
+
+		if rec.status() < http.StatusInternalServerError && rec.status() != http.StatusTooManyRequests {
+			api.IdempotencyCache.Complete(scopedKey, &idempotency.Response{
+				Status: rec.status(),
+				Header: w.Header().Clone(),
+				Body:   rec.body.Bytes(),
+			})
+			completed = true
+		}
+	})
+}
+
+// replay writes the provided cached response
+func replay(ctx context.Context, w http.ResponseWriter, resp *idempotency.Response) {
+	for name, values := range resp.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set(idempotentReplayedHeader, "true")
+	w.WriteHeader(resp.Status)
+	if _, err := w.Write(resp.Body); err != nil {
+		log.Error(ctx, "failed to write response body", err)
+	}
+}
+
+// responseRecorder writes the response through to the wrapped ResponseWriter, and records its status code and body
+type responseRecorder struct {
+	http.ResponseWriter
+	code int
+	body bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(code int) {
+	if rec.code == 0 {
+		rec.code = code
+	}
+	rec.ResponseWriter.WriteHeader(code)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	if rec.code == 0 {
+		rec.code = http.StatusOK
+	}
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
+
+// status returns the status code written, which is 200 OK if nothing was written
+func (rec *responseRecorder) status() int {
+	if rec.code == 0 {
+		return http.StatusOK
+	}
+	return rec.code
+}
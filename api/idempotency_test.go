@@ -0,0 +1,193 @@
+package api_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-feedback-api/api"
+	"github.com/ONSdigital/dp-feedback-api/idempotency"
+	"github.com/ONSdigital/dp-feedback-api/models"
+	dprequest "github.com/ONSdigital/dp-net/v3/request"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestIdempotent(t *testing.T) {
+	Convey("Given an idempotent handler that returns the number of times it was called", t, func() {
+		a := &api.API{IdempotencyCache: idempotency.NewCache(time.Hour)}
+
+		calls := 0
+		status := http.StatusCreated
+		var bodies []string
+		h := a.Idempotent(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			b, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, string(b))
+			w.Header().Set("Location", fmt.Sprintf("/feedback/%d", calls))
+			w.WriteHeader(status)
+			fmt.Fprintf(w, `{"call":%d}`, calls)
+		}))
+
+		post := func(caller, key, body string) *httptest.ResponseRecorder {
+			r := httptest.NewRequest(http.MethodPost, "/feedback", strings.NewReader(body))
+			r = r.WithContext(dprequest.SetCaller(r.Context(), caller))
+			if key != "" {
+				r.Header.Set("Idempotency-Key", key)
+			}
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, r)
+			return w
+		}
+
+		Convey("When a request with an idempotency key is retried", func() {
+			first := post(testServiceID, "key-1", `{"feedback":"a"}`)
+			retry := post(testServiceID, "key-1", `{"feedback":"a"}`)
+
+			Convey("Then the handler is only called once, with the request body", func() {
+				So(calls, ShouldEqual, 1)
+				So(bodies, ShouldResemble, []string{`{"feedback":"a"}`})
+			})
+
+			Convey("Then the retry gets the original response, marked as replayed", func() {
+				So(first.Code, ShouldEqual, http.StatusCreated)
+				So(first.Header().Get("Idempotent-Replayed"), ShouldBeEmpty)
+				So(retry.Code, ShouldEqual, http.StatusCreated)
+				So(retry.Header().Get("Location"), ShouldEqual, "/feedback/1")
+				So(retry.Header().Get("Idempotent-Replayed"), ShouldEqual, "true")
+				So(retry.Body.String(), ShouldEqual, `{"call":1}`)
+			})
+		})
+
+		Convey("When requests with different idempotency keys, or without a key, are received", func() {
+			post(testServiceID, "key-1", `{"feedback":"a"}`)
+			post(testServiceID, "key-2", `{"feedback":"a"}`)
+			post(testServiceID, "", `{"feedback":"a"}`)
+			post(testServiceID, "", `{"feedback":"a"}`)
+
+			Convey("Then they are all processed", func() {
+				So(calls, ShouldEqual, 4)
+			})
+		})
+
+		Convey("When different callers use the same idempotency key", func() {
+			post(testServiceID, "key-1", `{"feedback":"a"}`)
+			w := post("other-service", "key-1", `{"feedback":"a"}`)
+
+			Convey("Then the requests of every caller are processed", func() {
+				So(calls, ShouldEqual, 2)
+				So(w.Body.String(), ShouldEqual, `{"call":2}`)
+			})
+		})
+
+		Convey("When an idempotency key is reused with a different body", func() {
+			post(testServiceID, "key-1", `{"feedback":"a"}`)
+			w := post(testServiceID, "key-1", `{"feedback":"b"}`)
+
+			Convey("Then 422 Unprocessable Entity is returned without calling the handler", func() {
+				So(w.Code, ShouldEqual, http.StatusUnprocessableEntity)
+				So(calls, ShouldEqual, 1)
+
+				resp := &models.ErrorResponse{}
+				So(json.Unmarshal(w.Body.Bytes(), resp), ShouldBeNil)
+				So(resp.Code, ShouldEqual, models.ErrCodeUnprocessable)
+			})
+		})
+
+		Convey("When a request with an idempotency key fails with a server error and is retried", func() {
+			status = http.StatusInternalServerError
+			post(testServiceID, "key-1", `{"feedback":"a"}`)
+			status = http.StatusCreated
+			w := post(testServiceID, "key-1", `{"feedback":"a"}`)
+
+			Convey("Then the retry is processed again", func() {
+				So(calls, ShouldEqual, 2)
+				So(w.Code, ShouldEqual, http.StatusCreated)
+				So(w.Header().Get("Idempotent-Replayed"), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When a request with an idempotency key is rate limited and is retried", func() {
+			status = http.StatusTooManyRequests
+			post(testServiceID, "key-1", `{"feedback":"a"}`)
+			status = http.StatusCreated
+			w := post(testServiceID, "key-1", `{"feedback":"a"}`)
+
+			Convey("Then the retry is processed again", func() {
+				So(calls, ShouldEqual, 2)
+				So(w.Code, ShouldEqual, http.StatusCreated)
+				So(w.Header().Get("Idempotent-Replayed"), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When a request with an idempotency key fails with a client error and is retried", func() {
+			status = http.StatusBadRequest
+			post(testServiceID, "key-1", `{"feedback":"a"}`)
+			w := post(testServiceID, "key-1", `{"feedback":"a"}`)
+
+			Convey("Then the retry gets the original error", func() {
+				So(calls, ShouldEqual, 1)
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(w.Header().Get("Idempotent-Replayed"), ShouldEqual, "true")
+			})
+		})
+
+		Convey("When a request with an idempotency key that is too long is received", func() {
+			w := post(testServiceID, strings.Repeat("k", 256), `{"feedback":"a"}`)
+
+			Convey("Then 400 Bad Request is returned without calling the handler", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(calls, ShouldEqual, 0)
+			})
+		})
+	})
+
+	Convey("Given an idempotent handler that is still processing a request", t, func() {
+		cache := idempotency.NewCache(time.Hour)
+		a := &api.API{IdempotencyCache: cache}
+		var retry *httptest.ResponseRecorder
+		var h http.Handler
+		newRequest := func() *http.Request {
+			r := httptest.NewRequest(http.MethodPost, "/feedback", strings.NewReader(`{"feedback":"a"}`))
+			r.Header.Set("Idempotency-Key", "key-1")
+			return r
+		}
+		h = a.Idempotent(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if retry == nil {
+				retry = httptest.NewRecorder()
+				h.ServeHTTP(retry, newRequest())
+			}
+			w.WriteHeader(http.StatusCreated)
+		}))
+
+		Convey("When it is retried, then 409 Conflict is returned", func() {
+			h.ServeHTTP(httptest.NewRecorder(), newRequest())
+			So(retry.Code, ShouldEqual, http.StatusConflict)
+
+			resp := &models.ErrorResponse{}
+			So(json.Unmarshal(retry.Body.Bytes(), resp), ShouldBeNil)
+			So(resp.Code, ShouldEqual, models.ErrCodeConflict)
+		})
+	})
+
+	Convey("Given a handler of an API without an idempotency cache", t, func() {
+		a := &api.API{}
+		calls := 0
+		h := a.Idempotent(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+		}))
+
+		Convey("Then requests with the same idempotency key are all processed", func() {
+			for i := 0; i < 2; i++ {
+				r := httptest.NewRequest(http.MethodPost, "/feedback", http.NoBody)
+				r.Header.Set("Idempotency-Key", "key-1")
+				h.ServeHTTP(httptest.NewRecorder(), r)
+			}
+			So(calls, ShouldEqual, 2)
+		})
+	})
+}
@@ -1,8 +1,61 @@
 package api
 
-//go:generate moq -out mock/email.go -pkg mock . EmailSender
+import (
+	"context"
+	"net/http"
+	"time"
 
-// EmailSender defines the required methods from the email sender package
-type EmailSender interface {
-	Send(from string, to []string, msg []byte) error
+	"github.com/ONSdigital/dp-api-clients-go/v2/identity"
+	"github.com/ONSdigital/dp-feedback-api/idempotency"
+	"github.com/ONSdigital/dp-feedback-api/models"
+)
+
+//go:generate moq -out mock/notifier.go -pkg mock . Notifier
+//go:generate moq -out mock/events.go -pkg mock . EventPublisher
+//go:generate moq -out mock/identity.go -pkg mock . IdentityClient
+//go:generate moq -out mock/store.go -pkg mock . FeedbackStore
+//go:generate moq -out mock/ratelimiter.go -pkg mock . RateLimiter
+//go:generate moq -out mock/idempotency.go -pkg mock . IdempotencyCache
+//go:generate moq -out mock/duplicates.go -pkg mock . DuplicateDetector
+
+// Notifier defines the required methods to notify a delivery channel (e.g. email or webhook) of new feedback
+type Notifier interface {
+	Notify(ctx context.Context, f *models.Feedback) error
+}
+
+// EventPublisher defines the required methods to publish the events about feedback
+type EventPublisher interface {
+	PublishFeedbackReceived(ctx context.Context, f *models.Feedback) error
+}
+
+// IdentityClient defines the required methods to identify the caller of a request from its auth token
+type IdentityClient interface {
+	CheckRequest(req *http.Request, florenceToken, serviceAuthToken string) (context.Context, int, identity.AuthFailure, error)
+}
+
+// FeedbackStore defines the required methods to persist feedback submissions
+type FeedbackStore interface {
+	AddFeedback(ctx context.Context, f *models.Feedback) error
+	GetFeedback(ctx context.Context, id string) (*models.Feedback, error)
+	AddOccurrence(ctx context.Context, id string) (*models.Feedback, error)
+	GetFeedbackList(ctx context.Context, filter *models.FeedbackFilter) ([]*models.Feedback, error)
+}
+
+// RateLimiter defines the required methods to limit the rate of requests per caller and per client IP
+type RateLimiter interface {
+	Allow(caller string, r *http.Request) (allowed bool, retryAfter time.Duration)
+}
+
+// IdempotencyCache defines the required methods to cache the responses to the requests with an idempotency key,
+// so that their retries get the original response instead of being processed again
+type IdempotencyCache interface {
+	Begin(key, fingerprint string) (*idempotency.Response, error)
+	Complete(key string, resp *idempotency.Response)
+	Cancel(key string)
+}
+
+// DuplicateDetector defines the required methods to detect the near-duplicates of recent feedback submissions
+type DuplicateDetector interface {
+	Check(f *models.Feedback) (originalID string)
+	Forget(id string)
 }
@@ -0,0 +1,83 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"context"
+	"github.com/ONSdigital/dp-feedback-api/api"
+	"github.com/ONSdigital/dp-feedback-api/models"
+	"sync"
+)
+
+// Ensure, that EventPublisherMock does implement api.EventPublisher.
+// If this is not the case, regenerate this file with moq.
+var _ api.EventPublisher = &EventPublisherMock{}
+
+// EventPublisherMock is a mock implementation of api.EventPublisher.
+//
+//	func TestSomethingThatUsesEventPublisher(t *testing.T) {
+//
+//		// make and configure a mocked api.EventPublisher
+//		mockedEventPublisher := &EventPublisherMock{
+//			PublishFeedbackReceivedFunc: func(ctx context.Context, f *models.Feedback) error {
+//				panic("mock out the PublishFeedbackReceived method")
+//			},
+//		}
+//
+//		// use mockedEventPublisher in code that requires api.EventPublisher
+//		// and then make assertions.
+//
+//	}
+type EventPublisherMock struct {
+	// PublishFeedbackReceivedFunc mocks the PublishFeedbackReceived method.
+	PublishFeedbackReceivedFunc func(ctx context.Context, f *models.Feedback) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// PublishFeedbackReceived holds details about calls to the PublishFeedbackReceived method.
+		PublishFeedbackReceived []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// F is the f argument value.
+			F *models.Feedback
+		}
+	}
+	lockPublishFeedbackReceived sync.RWMutex
+}
+
+// PublishFeedbackReceived calls PublishFeedbackReceivedFunc.
+func (mock *EventPublisherMock) PublishFeedbackReceived(ctx context.Context, f *models.Feedback) error {
+	if mock.PublishFeedbackReceivedFunc == nil {
+		panic("EventPublisherMock.PublishFeedbackReceivedFunc: method is nil but EventPublisher.PublishFeedbackReceived was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		F   *models.Feedback
+	}{
+		Ctx: ctx,
+		F:   f,
+	}
+	mock.lockPublishFeedbackReceived.Lock()
+	mock.calls.PublishFeedbackReceived = append(mock.calls.PublishFeedbackReceived, callInfo)
+	mock.lockPublishFeedbackReceived.Unlock()
+	return mock.PublishFeedbackReceivedFunc(ctx, f)
+}
+
+// PublishFeedbackReceivedCalls gets all the calls that were made to PublishFeedbackReceived.
+// Check the length with:
+//
+//	len(mockedEventPublisher.PublishFeedbackReceivedCalls())
+func (mock *EventPublisherMock) PublishFeedbackReceivedCalls() []struct {
+	Ctx context.Context
+	F   *models.Feedback
+} {
+	var calls []struct {
+		Ctx context.Context
+		F   *models.Feedback
+	}
+	mock.lockPublishFeedbackReceived.RLock()
+	calls = mock.calls.PublishFeedbackReceived
+	mock.lockPublishFeedbackReceived.RUnlock()
+	return calls
+}
@@ -0,0 +1,90 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"context"
+	"github.com/ONSdigital/dp-api-clients-go/v2/identity"
+	"github.com/ONSdigital/dp-feedback-api/api"
+	"net/http"
+	"sync"
+)
+
+// Ensure, that IdentityClientMock does implement api.IdentityClient.
+// If this is not the case, regenerate this file with moq.
+var _ api.IdentityClient = &IdentityClientMock{}
+
+// IdentityClientMock is a mock implementation of api.IdentityClient.
+//
+//	func TestSomethingThatUsesIdentityClient(t *testing.T) {
+//
+//		// make and configure a mocked api.IdentityClient
+//		mockedIdentityClient := &IdentityClientMock{
+//			CheckRequestFunc: func(req *http.Request, florenceToken string, serviceAuthToken string) (context.Context, int, identity.AuthFailure, error) {
+//				panic("mock out the CheckRequest method")
+//			},
+//		}
+//
+//		// use mockedIdentityClient in code that requires api.IdentityClient
+//		// and then make assertions.
+//
+//	}
+type IdentityClientMock struct {
+	// CheckRequestFunc mocks the CheckRequest method.
+	CheckRequestFunc func(req *http.Request, florenceToken string, serviceAuthToken string) (context.Context, int, identity.AuthFailure, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// CheckRequest holds details about calls to the CheckRequest method.
+		CheckRequest []struct {
+			// Req is the req argument value.
+			Req *http.Request
+			// FlorenceToken is the florenceToken argument value.
+			FlorenceToken string
+			// ServiceAuthToken is the serviceAuthToken argument value.
+			ServiceAuthToken string
+		}
+	}
+	lockCheckRequest sync.RWMutex
+}
+
+// CheckRequest calls CheckRequestFunc.
+func (mock *IdentityClientMock) CheckRequest(req *http.Request, florenceToken string, serviceAuthToken string) (context.Context, int, identity.AuthFailure, error) {
+	if mock.CheckRequestFunc == nil {
+		panic("IdentityClientMock.CheckRequestFunc: method is nil but IdentityClient.CheckRequest was just called")
+	}
+	callInfo := struct {
+		Req              *http.Request
+		FlorenceToken    string
+		ServiceAuthToken string
+	}{
+		Req:              req,
+		FlorenceToken:    florenceToken,
+		ServiceAuthToken: serviceAuthToken,
+	}
+	mock.lockCheckRequest.Lock()
+	mock.calls.CheckRequest = append(mock.calls.CheckRequest, callInfo)
+	mock.lockCheckRequest.Unlock()
+	return mock.CheckRequestFunc(req, florenceToken, serviceAuthToken)
+}
+
+// CheckRequestCalls gets all the calls that were made to CheckRequest.
+// Check the length with:
+//
+//	len(mockedIdentityClient.CheckRequestCalls())
+func (mock *IdentityClientMock) CheckRequestCalls() []struct {
+	Req              *http.Request
+	FlorenceToken    string
+	ServiceAuthToken string
+} {
+	var calls []struct {
+		Req              *http.Request
+		FlorenceToken    string
+		ServiceAuthToken string
+	}
+	mock.lockCheckRequest.RLock()
+	calls = mock.calls.CheckRequest
+	mock.lockCheckRequest.RUnlock()
+	return calls
+}
@@ -0,0 +1,83 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"context"
+	"github.com/ONSdigital/dp-feedback-api/api"
+	"github.com/ONSdigital/dp-feedback-api/models"
+	"sync"
+)
+
+// Ensure, that NotifierMock does implement api.Notifier.
+// If this is not the case, regenerate this file with moq.
+var _ api.Notifier = &NotifierMock{}
+
+// NotifierMock is a mock implementation of api.Notifier.
+//
+//	func TestSomethingThatUsesNotifier(t *testing.T) {
+//
+//		// make and configure a mocked api.Notifier
+//		mockedNotifier := &NotifierMock{
+//			NotifyFunc: func(ctx context.Context, f *models.Feedback) error {
+//				panic("mock out the Notify method")
+//			},
+//		}
+//
+//		// use mockedNotifier in code that requires api.Notifier
+//		// and then make assertions.
+//
+//	}
+type NotifierMock struct {
+	// NotifyFunc mocks the Notify method.
+	NotifyFunc func(ctx context.Context, f *models.Feedback) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Notify holds details about calls to the Notify method.
+		Notify []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// F is the f argument value.
+			F *models.Feedback
+		}
+	}
+	lockNotify sync.RWMutex
+}
+
+// Notify calls NotifyFunc.
+func (mock *NotifierMock) Notify(ctx context.Context, f *models.Feedback) error {
+	if mock.NotifyFunc == nil {
+		panic("NotifierMock.NotifyFunc: method is nil but Notifier.Notify was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		F   *models.Feedback
+	}{
+		Ctx: ctx,
+		F:   f,
+	}
+	mock.lockNotify.Lock()
+	mock.calls.Notify = append(mock.calls.Notify, callInfo)
+	mock.lockNotify.Unlock()
+	return mock.NotifyFunc(ctx, f)
+}
+
+// NotifyCalls gets all the calls that were made to Notify.
+// Check the length with:
+//
+//	len(mockedNotifier.NotifyCalls())
+func (mock *NotifierMock) NotifyCalls() []struct {
+	Ctx context.Context
+	F   *models.Feedback
+} {
+	var calls []struct {
+		Ctx context.Context
+		F   *models.Feedback
+	}
+	mock.lockNotify.RLock()
+	calls = mock.calls.Notify
+	mock.lockNotify.RUnlock()
+	return calls
+}
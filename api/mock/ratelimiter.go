@@ -0,0 +1,83 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"github.com/ONSdigital/dp-feedback-api/api"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Ensure, that RateLimiterMock does implement api.RateLimiter.
+// If this is not the case, regenerate this file with moq.
+var _ api.RateLimiter = &RateLimiterMock{}
+
+// RateLimiterMock is a mock implementation of api.RateLimiter.
+//
+//	func TestSomethingThatUsesRateLimiter(t *testing.T) {
+//
+//		// make and configure a mocked api.RateLimiter
+//		mockedRateLimiter := &RateLimiterMock{
+//			AllowFunc: func(caller string, r *http.Request) (bool, time.Duration) {
+//				panic("mock out the Allow method")
+//			},
+//		}
+//
+//		// use mockedRateLimiter in code that requires api.RateLimiter
+//		// and then make assertions.
+//
+//	}
+type RateLimiterMock struct {
+	// AllowFunc mocks the Allow method.
+	AllowFunc func(caller string, r *http.Request) (bool, time.Duration)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Allow holds details about calls to the Allow method.
+		Allow []struct {
+			// Caller is the caller argument value.
+			Caller string
+			// R is the r argument value.
+			R *http.Request
+		}
+	}
+	lockAllow sync.RWMutex
+}
+
+// Allow calls AllowFunc.
+func (mock *RateLimiterMock) Allow(caller string, r *http.Request) (bool, time.Duration) {
+	if mock.AllowFunc == nil {
+		panic("RateLimiterMock.AllowFunc: method is nil but RateLimiter.Allow was just called")
+	}
+	callInfo := struct {
+		Caller string
+		R      *http.Request
+	}{
+		Caller: caller,
+		R:      r,
+	}
+	mock.lockAllow.Lock()
+	mock.calls.Allow = append(mock.calls.Allow, callInfo)
+	mock.lockAllow.Unlock()
+	return mock.AllowFunc(caller, r)
+}
+
+// AllowCalls gets all the calls that were made to Allow.
+// Check the length with:
+//
+//	len(mockedRateLimiter.AllowCalls())
+func (mock *RateLimiterMock) AllowCalls() []struct {
+	Caller string
+	R      *http.Request
+} {
+	var calls []struct {
+		Caller string
+		R      *http.Request
+	}
+	mock.lockAllow.RLock()
+	calls = mock.calls.Allow
+	mock.lockAllow.RUnlock()
+	return calls
+}
@@ -0,0 +1,233 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"context"
+	"github.com/ONSdigital/dp-feedback-api/api"
+	"github.com/ONSdigital/dp-feedback-api/models"
+	"sync"
+)
+
+// Ensure, that FeedbackStoreMock does implement api.FeedbackStore.
+// If this is not the case, regenerate this file with moq.
+var _ api.FeedbackStore = &FeedbackStoreMock{}
+
+// FeedbackStoreMock is a mock implementation of api.FeedbackStore.
+//
+//	func TestSomethingThatUsesFeedbackStore(t *testing.T) {
+//
+//		// make and configure a mocked api.FeedbackStore
+//		mockedFeedbackStore := &FeedbackStoreMock{
+//			AddFeedbackFunc: func(ctx context.Context, f *models.Feedback) error {
+//				panic("mock out the AddFeedback method")
+//			},
+//			AddOccurrenceFunc: func(ctx context.Context, id string) (*models.Feedback, error) {
+//				panic("mock out the AddOccurrence method")
+//			},
+//			GetFeedbackFunc: func(ctx context.Context, id string) (*models.Feedback, error) {
+//				panic("mock out the GetFeedback method")
+//			},
+//			GetFeedbackListFunc: func(ctx context.Context, filter *models.FeedbackFilter) ([]*models.Feedback, error) {
+//				panic("mock out the GetFeedbackList method")
+//			},
+//		}
+//
+//		// use mockedFeedbackStore in code that requires api.FeedbackStore
+//		// and then make assertions.
+//
+//	}
+type FeedbackStoreMock struct {
+	// AddFeedbackFunc mocks the AddFeedback method.
+	AddFeedbackFunc func(ctx context.Context, f *models.Feedback) error
+
+	// AddOccurrenceFunc mocks the AddOccurrence method.
+	AddOccurrenceFunc func(ctx context.Context, id string) (*models.Feedback, error)
+
+	// GetFeedbackFunc mocks the GetFeedback method.
+	GetFeedbackFunc func(ctx context.Context, id string) (*models.Feedback, error)
+
+	// GetFeedbackListFunc mocks the GetFeedbackList method.
+	GetFeedbackListFunc func(ctx context.Context, filter *models.FeedbackFilter) ([]*models.Feedback, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// AddFeedback holds details about calls to the AddFeedback method.
+		AddFeedback []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// F is the f argument value.
+			F *models.Feedback
+		}
+		// AddOccurrence holds details about calls to the AddOccurrence method.
+		AddOccurrence []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+		}
+		// GetFeedback holds details about calls to the GetFeedback method.
+		GetFeedback []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+		}
+		// GetFeedbackList holds details about calls to the GetFeedbackList method.
+		GetFeedbackList []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Filter is the filter argument value.
+			Filter *models.FeedbackFilter
+		}
+	}
+	lockAddFeedback     sync.RWMutex
+	lockAddOccurrence   sync.RWMutex
+	lockGetFeedback     sync.RWMutex
+	lockGetFeedbackList sync.RWMutex
+}
+
+// AddFeedback calls AddFeedbackFunc.
+func (mock *FeedbackStoreMock) AddFeedback(ctx context.Context, f *models.Feedback) error {
+	if mock.AddFeedbackFunc == nil {
+		panic("FeedbackStoreMock.AddFeedbackFunc: method is nil but FeedbackStore.AddFeedback was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		F   *models.Feedback
+	}{
+		Ctx: ctx,
+		F:   f,
+	}
+	mock.lockAddFeedback.Lock()
+	mock.calls.AddFeedback = append(mock.calls.AddFeedback, callInfo)
+	mock.lockAddFeedback.Unlock()
+	return mock.AddFeedbackFunc(ctx, f)
+}
+
+// AddFeedbackCalls gets all the calls that were made to AddFeedback.
+// Check the length with:
+//
+//	len(mockedFeedbackStore.AddFeedbackCalls())
+func (mock *FeedbackStoreMock) AddFeedbackCalls() []struct {
+	Ctx context.Context
+	F   *models.Feedback
+} {
+	var calls []struct {
+		Ctx context.Context
+		F   *models.Feedback
+	}
+	mock.lockAddFeedback.RLock()
+	calls = mock.calls.AddFeedback
+	mock.lockAddFeedback.RUnlock()
+	return calls
+}
+
+// AddOccurrence calls AddOccurrenceFunc.
+func (mock *FeedbackStoreMock) AddOccurrence(ctx context.Context, id string) (*models.Feedback, error) {
+	if mock.AddOccurrenceFunc == nil {
+		panic("FeedbackStoreMock.AddOccurrenceFunc: method is nil but FeedbackStore.AddOccurrence was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockAddOccurrence.Lock()
+	mock.calls.AddOccurrence = append(mock.calls.AddOccurrence, callInfo)
+	mock.lockAddOccurrence.Unlock()
+	return mock.AddOccurrenceFunc(ctx, id)
+}
+
+// AddOccurrenceCalls gets all the calls that were made to AddOccurrence.
+// Check the length with:
+//
+//	len(mockedFeedbackStore.AddOccurrenceCalls())
+func (mock *FeedbackStoreMock) AddOccurrenceCalls() []struct {
+	Ctx context.Context
+	ID  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+	}
+	mock.lockAddOccurrence.RLock()
+	calls = mock.calls.AddOccurrence
+	mock.lockAddOccurrence.RUnlock()
+	return calls
+}
+
+// GetFeedback calls GetFeedbackFunc.
+func (mock *FeedbackStoreMock) GetFeedback(ctx context.Context, id string) (*models.Feedback, error) {
+	if mock.GetFeedbackFunc == nil {
+		panic("FeedbackStoreMock.GetFeedbackFunc: method is nil but FeedbackStore.GetFeedback was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockGetFeedback.Lock()
+	mock.calls.GetFeedback = append(mock.calls.GetFeedback, callInfo)
+	mock.lockGetFeedback.Unlock()
+	return mock.GetFeedbackFunc(ctx, id)
+}
+
+// GetFeedbackCalls gets all the calls that were made to GetFeedback.
+// Check the length with:
+//
+//	len(mockedFeedbackStore.GetFeedbackCalls())
+func (mock *FeedbackStoreMock) GetFeedbackCalls() []struct {
+	Ctx context.Context
+	ID  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+	}
+	mock.lockGetFeedback.RLock()
+	calls = mock.calls.GetFeedback
+	mock.lockGetFeedback.RUnlock()
+	return calls
+}
+
+// GetFeedbackList calls GetFeedbackListFunc.
+func (mock *FeedbackStoreMock) GetFeedbackList(ctx context.Context, filter *models.FeedbackFilter) ([]*models.Feedback, error) {
+	if mock.GetFeedbackListFunc == nil {
+		panic("FeedbackStoreMock.GetFeedbackListFunc: method is nil but FeedbackStore.GetFeedbackList was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Filter *models.FeedbackFilter
+	}{
+		Ctx:    ctx,
+		Filter: filter,
+	}
+	mock.lockGetFeedbackList.Lock()
+	mock.calls.GetFeedbackList = append(mock.calls.GetFeedbackList, callInfo)
+	mock.lockGetFeedbackList.Unlock()
+	return mock.GetFeedbackListFunc(ctx, filter)
+}
+
+// GetFeedbackListCalls gets all the calls that were made to GetFeedbackList.
+// Check the length with:
+//
+//	len(mockedFeedbackStore.GetFeedbackListCalls())
+func (mock *FeedbackStoreMock) GetFeedbackListCalls() []struct {
+	Ctx    context.Context
+	Filter *models.FeedbackFilter
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Filter *models.FeedbackFilter
+	}
+	mock.lockGetFeedbackList.RLock()
+	calls = mock.calls.GetFeedbackList
+	mock.lockGetFeedbackList.RUnlock()
+	return calls
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/ONSdigital/dp-feedback-api/models"
+)
+
+// query parameter names
+const (
+	ParamOffset            = "offset"
+	ParamLimit             = "limit"
+	ParamIsPageUseful      = "is_page_useful"
+	ParamIsGeneralFeedback = "is_general_feedback"
+	ParamOnsURL            = "ons_url"
+	ParamStartDate         = "start_date"
+	ParamEndDate           = "end_date"
+)
+
+const dateLayout = "2006-01-02"
+
+// getPagination returns the offset and limit query parameters, or the configured defaults if they are not provided
+func (api *API) getPagination(query url.Values) (offset, limit int, err error) {
+	offset, limit = api.Cfg.DefaultOffset, api.Cfg.DefaultLimit
+
+	if v := query.Get(ParamOffset); v != "" {
+		if offset, err = strconv.Atoi(v); err != nil || offset < 0 {
+			return 0, 0, fmt.Errorf("invalid query parameter '%s': must be a non-negative integer", ParamOffset)
+		}
+	}
+
+	if v := query.Get(ParamLimit); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit < 0 {
+			return 0, 0, fmt.Errorf("invalid query parameter '%s': must be a non-negative integer", ParamLimit)
+		}
+		if limit > api.Cfg.DefaultMaximumLimit {
+			return 0, 0, fmt.Errorf("invalid query parameter '%s': must not be greater than %d", ParamLimit, api.Cfg.DefaultMaximumLimit)
+		}
+	}
+
+	return offset, limit, nil
+}
+
+// getFeedbackFilter returns a feedback filter from the provided query parameters.
+// Dates can be provided in RFC3339 or 'YYYY-MM-DD' format, and both ends of the range are inclusive.
+func getFeedbackFilter(query url.Values) (*models.FeedbackFilter, error) {
+	var err error
+	filter := &models.FeedbackFilter{
+		OnsURLPrefix: query.Get(ParamOnsURL),
+	}
+
+	if filter.IsPageUseful, err = getBool(query, ParamIsPageUseful); err != nil {
+		return nil, err
+	}
+	if filter.IsGeneralFeedback, err = getBool(query, ParamIsGeneralFeedback); err != nil {
+		return nil, err
+	}
+
+	if v := query.Get(ParamStartDate); v != "" {
+		start, _, err := parseDate(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid query parameter '%s': %w", ParamStartDate, err)
+		}
+		filter.ReceivedAfter = &start
+	}
+
+	if v := query.Get(ParamEndDate); v != "" {
+		end, isDay, err := parseDate(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid query parameter '%s': %w", ParamEndDate, err)
+		}
+		// the filter upper bound is exclusive, so it is moved to the end of the day or instant provided
+		if isDay {
+			end = end.AddDate(0, 0, 1)
+		} else {
+			end = end.Add(time.Nanosecond)
+		}
+		filter.ReceivedBefore = &end
+	}
+
+	if filter.ReceivedAfter != nil && filter.ReceivedBefore != nil && !filter.ReceivedAfter.Before(*filter.ReceivedBefore) {
+		return nil, fmt.Errorf("invalid query parameters: '%s' must not be after '%s'", ParamStartDate, ParamEndDate)
+	}
+
+	return filter, nil
+}
+
+// getBool returns a pointer to the boolean value of the provided query parameter, or nil if it is not provided
+func getBool(query url.Values, param string) (*bool, error) {
+	v := query.Get(param)
+	if v == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query parameter '%s': must be a boolean", param)
+	}
+	return &b, nil
+}
+
+// parseDate parses a date in RFC3339 or 'YYYY-MM-DD' format, returning true if it was a whole day
+func parseDate(v string) (t time.Time, isDay bool, err error) {
+	if t, err = time.Parse(dateLayout, v); err == nil {
+		return t.UTC(), true, nil
+	}
+	if t, err = time.Parse(time.RFC3339, v); err == nil {
+		return t.UTC(), false, nil
+	}
+	return time.Time{}, false, fmt.Errorf("must be a date in RFC3339 or '%s' format", dateLayout)
+}
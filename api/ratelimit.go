@@ -0,0 +1,41 @@
+package api
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	dprequest "github.com/ONSdigital/dp-net/v3/request"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+const retryAfterHeader = "Retry-After"
+
+var errTooManyRequests = errors.New("too many requests, please try again later")
+
+// RateLimit is a middleware that rejects the requests over the rate limits of their caller or client IP
+// with 429 Too Many Requests, and a Retry-After header with the number of seconds to wait before retrying.
+// It must run after Authorise, which adds the caller to the request context, and after Idempotent, so that the retries that get
+// the original response do not count towards the limits. Requests are not limited without a rate limiter.
+func (api *API) RateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if api.RateLimiter == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := r.Context()
+		caller := dprequest.Caller(ctx)
+		allowed, retryAfter := api.RateLimiter.Allow(caller, r)
+		if !allowed {
+			seconds := int(math.Max(1, math.Ceil(retryAfter.Seconds())))
+			log.Warn(ctx, "request rate limited", log.Data{"caller": caller, "retry_after": seconds})
+			w.Header().Set(retryAfterHeader, strconv.Itoa(seconds))
+			api.handleError(ctx, w, errTooManyRequests, http.StatusTooManyRequests)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,153 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-feedback-api/api"
+	"github.com/ONSdigital/dp-feedback-api/api/mock"
+	"github.com/ONSdigital/dp-feedback-api/idempotency"
+	"github.com/ONSdigital/dp-feedback-api/models"
+	dprequest "github.com/ONSdigital/dp-net/v3/request"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func rateLimiterMock(allowed bool, retryAfter time.Duration) *mock.RateLimiterMock {
+	return &mock.RateLimiterMock{
+		AllowFunc: func(caller string, r *http.Request) (bool, time.Duration) {
+			return allowed, retryAfter
+		},
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	Convey("Given a rate limited handler", t, func() {
+		limiter := rateLimiterMock(true, 0)
+		a := &api.API{RateLimiter: limiter}
+
+		handlerCalled := false
+		h := a.RateLimit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handlerCalled = true
+		}))
+
+		r := httptest.NewRequest(http.MethodPost, "/feedback", http.NoBody)
+		r = r.WithContext(dprequest.SetCaller(r.Context(), testServiceID))
+		w := httptest.NewRecorder()
+
+		Convey("When a request within the rate limits is received", func() {
+			h.ServeHTTP(w, r)
+
+			Convey("Then the rate limits of the caller and the request are checked, and the handler is called", func() {
+				So(limiter.AllowCalls(), ShouldHaveLength, 1)
+				So(limiter.AllowCalls()[0].Caller, ShouldEqual, testServiceID)
+				So(limiter.AllowCalls()[0].R, ShouldEqual, r)
+				So(handlerCalled, ShouldBeTrue)
+			})
+		})
+
+		Convey("When a request over the rate limits is received", func() {
+			limiter.AllowFunc = rateLimiterMock(false, 1500*time.Millisecond).AllowFunc
+			h.ServeHTTP(w, r)
+
+			Convey("Then 429 Too Many Requests is returned with the seconds to wait, rounded up, without calling the handler", func() {
+				So(w.Code, ShouldEqual, http.StatusTooManyRequests)
+				So(w.Header().Get("Retry-After"), ShouldEqual, "2")
+				So(handlerCalled, ShouldBeFalse)
+
+				resp := &models.ErrorResponse{}
+				So(json.Unmarshal(w.Body.Bytes(), resp), ShouldBeNil)
+				So(resp.Code, ShouldEqual, models.ErrCodeTooManyRequests)
+			})
+		})
+
+		Convey("When a request over the rate limits that can be retried straight away is received", func() {
+			limiter.AllowFunc = rateLimiterMock(false, time.Millisecond).AllowFunc
+			h.ServeHTTP(w, r)
+
+			Convey("Then the client is asked to wait at least a second", func() {
+				So(w.Code, ShouldEqual, http.StatusTooManyRequests)
+				So(w.Header().Get("Retry-After"), ShouldEqual, "1")
+			})
+		})
+	})
+
+	Convey("Given a handler of an API without a rate limiter", t, func() {
+		a := &api.API{}
+		handlerCalled := false
+		h := a.RateLimit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handlerCalled = true
+		}))
+
+		Convey("Then requests are not limited", func() {
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/feedback", http.NoBody))
+			So(handlerCalled, ShouldBeTrue)
+		})
+	})
+}
+
+func TestOnlyPostFeedbackIsRateLimited(t *testing.T) {
+	Convey("Given an API with a rate limiter that rejects every request", t, func() {
+		r := newRouterWithHealth()
+		limiter := rateLimiterMock(false, time.Second)
+		api.Setup(context.Background(), testConfig(), r, nil, nil, limiter, nil, nil, identityClientMock(), nil)
+
+		Convey("When feedback is posted to the versioned and unversioned paths", func() {
+			for _, path := range []string{"/v1/feedback", "/feedback"} {
+				req := httptest.NewRequest(http.MethodPost, path, http.NoBody)
+				req.Header.Set("Authorization", "Bearer "+testServiceToken)
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+				So(w.Code, ShouldEqual, http.StatusTooManyRequests)
+			}
+
+			Convey("Then the rate limits of the authorised caller are checked", func() {
+				So(limiter.AllowCalls(), ShouldHaveLength, 2)
+				So(limiter.AllowCalls()[0].Caller, ShouldEqual, testServiceID)
+			})
+		})
+
+		Convey("When a request that is not authorised is received, then it is rejected before checking the rate limits", func() {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/feedback", http.NoBody))
+			So(w.Code, ShouldEqual, http.StatusUnauthorized)
+			So(limiter.AllowCalls(), ShouldBeEmpty)
+		})
+
+		Convey("When /health is requested, then it is not rate limited", func() {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", http.NoBody))
+			So(w.Code, ShouldEqual, http.StatusOK)
+			So(limiter.AllowCalls(), ShouldBeEmpty)
+		})
+	})
+}
+
+func TestIdempotentRetriesAreNotRateLimited(t *testing.T) {
+	Convey("Given an API with a rate limiter and an idempotency cache", t, func() {
+		r := newRouterWithHealth()
+		limiter := rateLimiterMock(true, 0)
+		api.Setup(context.Background(), testConfig(), r, nil, nil, limiter, idempotency.NewCache(time.Hour), nil, identityClientMock(), nil)
+
+		Convey("When a submission with an idempotency key is retried", func() {
+			var w *httptest.ResponseRecorder
+			for i := 0; i < 2; i++ {
+				req := httptest.NewRequest(http.MethodPost, "/v1/feedback", strings.NewReader("{"))
+				req.Header.Set("Authorization", "Bearer "+testServiceToken)
+				req.Header.Set("Idempotency-Key", "key-1")
+				w = httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+			}
+
+			Convey("Then the retry gets the original response without checking the rate limits again", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(w.Header().Get("Idempotent-Replayed"), ShouldEqual, "true")
+				So(limiter.AllowCalls(), ShouldHaveLength, 1)
+			})
+		})
+	})
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ONSdigital/dp-feedback-api/models"
+)
+
+// ParamInterval is the query parameter used to split page usefulness summaries by period
+const ParamInterval = "interval"
+
+// GetFeedbackSummary is the handler for GET /feedback/summary
+// It returns the "Is this page useful?" answers aggregated by page, and optionally by day or week,
+// with the pages with most "not useful" answers first
+func (api *API) GetFeedbackSummary(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	query := r.URL.Query()
+
+	offset, limit, err := api.getPagination(query)
+	if err != nil {
+		api.handleError(ctx, w, err, http.StatusBadRequest)
+		return
+	}
+
+	interval := query.Get(ParamInterval)
+	if !models.IsValidInterval(interval) {
+		api.handleError(ctx, w, fmt.Errorf("invalid query parameter '%s': must be '%s' or '%s'", ParamInterval, models.IntervalDay, models.IntervalWeek), http.StatusBadRequest)
+		return
+	}
+
+	filter, err := getFeedbackFilter(query)
+	if err != nil {
+		api.handleError(ctx, w, err, http.StatusBadRequest)
+		return
+	}
+
+	items, err := api.FeedbackStore.GetFeedbackList(ctx, filter)
+	if err != nil {
+		api.handleError(ctx, w, fmt.Errorf("failed to get feedback list: %w", err), http.StatusInternalServerError)
+		return
+	}
+
+	summaries := models.Summarise(items, interval)
+	api.writeJSON(ctx, w, models.NewUsefulnessSummaryList(summaries, offset, limit), http.StatusOK)
+}
@@ -0,0 +1,64 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-feedback-api/api"
+	"github.com/ONSdigital/dp-feedback-api/api/mock"
+	"github.com/ONSdigital/dp-feedback-api/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGetFeedbackSummary(t *testing.T) {
+	receivedAt := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+	notUseful := false
+	stored := []*models.Feedback{
+		{ID: "1", ReceivedAt: &receivedAt, IsPageUseful: &isPageUseful, OnsURL: "https://www.ons.gov.uk/economy"},
+		{ID: "2", ReceivedAt: &receivedAt, IsPageUseful: &notUseful, OnsURL: "https://www.ons.gov.uk/census"},
+	}
+
+	Convey("Given an API with a store containing some feedback", t, func() {
+		storeMock := &mock.FeedbackStoreMock{
+			GetFeedbackListFunc: func(ctx context.Context, filter *models.FeedbackFilter) ([]*models.Feedback, error) {
+				return stored, nil
+			},
+		}
+		a := &api.API{Cfg: testConfig(), FeedbackStore: storeMock}
+
+		Convey("When GET /feedback/summary is called by day for a page prefix", func() {
+			w := httptest.NewRecorder()
+			a.GetFeedbackSummary(w, httptest.NewRequest(http.MethodGet, "/feedback/summary?interval=day&ons_url=www.ons.gov.uk", http.NoBody))
+
+			Convey("Then 200 OK is returned with the summaries of the pages with most 'not useful' answers first", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				list := &models.UsefulnessSummaryList{}
+				So(json.Unmarshal(w.Body.Bytes(), list), ShouldBeNil)
+				So(list.TotalCount, ShouldEqual, 2)
+				So(list.Items, ShouldResemble, []*models.UsefulnessSummary{
+					{OnsURL: "www.ons.gov.uk/census", PeriodStart: "2024-03-15", NotUseful: 1, Total: 1, NotUsefulPercentage: 100},
+					{OnsURL: "www.ons.gov.uk/economy", PeriodStart: "2024-03-15", Useful: 1, Total: 1, UsefulPercentage: 100},
+				})
+			})
+
+			Convey("Then the store is called with the page prefix filter", func() {
+				So(storeMock.GetFeedbackListCalls(), ShouldHaveLength, 1)
+				So(storeMock.GetFeedbackListCalls()[0].Filter.OnsURLPrefix, ShouldEqual, "www.ons.gov.uk")
+			})
+		})
+
+		Convey("When GET /feedback/summary is called with an invalid interval", func() {
+			w := httptest.NewRecorder()
+			a.GetFeedbackSummary(w, httptest.NewRequest(http.MethodGet, "/feedback/summary?interval=month", http.NoBody))
+
+			Convey("Then 400 Bad Request is returned and the store is not called", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(storeMock.GetFeedbackListCalls(), ShouldHaveLength, 0)
+			})
+		})
+	})
+}
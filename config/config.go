@@ -1,6 +1,13 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/kelseyhightower/envconfig"
@@ -13,20 +20,78 @@ type Config struct {
 	HealthCheckInterval        time.Duration `envconfig:"HEALTHCHECK_INTERVAL"`
 	HealthCheckCriticalTimeout time.Duration `envconfig:"HEALTHCHECK_CRITICAL_TIMEOUT"`
 	OnsDomain                  string        `envconfig:"ONS_DOMAIN"`
+	AllowedDomains             []string      `envconfig:"ALLOWED_DOMAINS"`
 	FeedbackTo                 string        `envconfig:"FEEDBACK_TO"`
 	FeedbackFrom               string        `envconfig:"FEEDBACK_FROM"`
+	FeedbackRoutesFile         string        `envconfig:"FEEDBACK_ROUTES_FILE"`
 	VersionPrefix              string        `envconfig:"VERSION_PREFIX"`
+	ZebedeeURL                 string        `envconfig:"ZEBEDEE_URL"`
+	FeedbackReaders            []string      `envconfig:"FEEDBACK_READERS"`
+	DefaultLimit               int           `envconfig:"DEFAULT_LIMIT"`
+	DefaultOffset              int           `envconfig:"DEFAULT_OFFSET"`
+	DefaultMaximumLimit        int           `envconfig:"DEFAULT_MAXIMUM_LIMIT"`
+	MinFormFillTime            time.Duration `envconfig:"MIN_FORM_FILL_TIME"`
+	IdempotencyWindow          time.Duration `envconfig:"IDEMPOTENCY_WINDOW"`
+	Notifiers                  []string      `envconfig:"NOTIFIERS"`
 	Mail                       *Mail
+	EmailTemplates             *EmailTemplates
 	Sanitize                   *Sanitize
+	Redact                     *Redact
+	Store                      *Store
+	Outbox                     *Outbox
+	Digest                     *Digest
+	Webhook                    *Webhook
+	Kafka                      *Kafka
+	RateLimit                  *RateLimit
+	Spam                       *Spam
+	Duplicate                  *Duplicate
 }
 
+// Notifiers that can be enabled to deliver the feedback
+const (
+	// NotifierEmail sends the feedback by email, according to the feedback routes
+	NotifierEmail = "email"
+	// NotifierWebhook posts the feedback to the configured webhook
+	NotifierWebhook = "webhook"
+)
+
 // Mail represents the subset of configuration corresponding to the email service
 type Mail struct {
-	Host      string `envconfig:"MAIL_HOST"`
-	User      string `envconfig:"MAIL_USER"`
-	Password  string `envconfig:"MAIL_PASSWORD" json:"-"`
-	Port      string `envconfig:"MAIL_PORT"`
-	Encrypted bool   `envconfig:"MAIL_ENCRYPTION"`
+	Host            string        `envconfig:"MAIL_HOST"`
+	User            string        `envconfig:"MAIL_USER"`
+	UserFile        string        `envconfig:"MAIL_USER_FILE"`
+	Password        string        `envconfig:"MAIL_PASSWORD" json:"-"`
+	PasswordFile    string        `envconfig:"MAIL_PASSWORD_FILE"`
+	Port            string        `envconfig:"MAIL_PORT"`
+	Encrypted       bool          `envconfig:"MAIL_ENCRYPTION"`
+	TLSMode         string        `envconfig:"MAIL_TLS_MODE"`
+	TLSCAFile       string        `envconfig:"MAIL_TLS_CA_FILE"`
+	TLSServerName   string        `envconfig:"MAIL_TLS_SERVER_NAME"`
+	PoolSize        int           `envconfig:"MAIL_POOL_SIZE"`
+	PoolIdleTimeout time.Duration `envconfig:"MAIL_POOL_IDLE_TIMEOUT"`
+}
+
+// TLS modes for the connections to the mail server
+const (
+	// TLSModeNone never uses TLS
+	TLSModeNone = "none"
+	// TLSModeStartTLS upgrades the connection with STARTTLS if the server supports it, and carries on without TLS otherwise
+	TLSModeStartTLS = "starttls"
+	// TLSModeStartTLSRequired upgrades the connection with STARTTLS, and fails if the server does not support it
+	TLSModeStartTLSRequired = "starttls-required"
+	// TLSModeImplicitTLS uses TLS from the start of the connection (SMTPS), typically on port 465
+	TLSModeImplicitTLS = "implicit-tls"
+)
+
+// EmailTemplates represents the subset of configuration corresponding to the templates used to generate feedback and digest emails.
+// Any empty path uses the default template embedded in the binary.
+type EmailTemplates struct {
+	SubjectPath       string `envconfig:"EMAIL_SUBJECT_TEMPLATE"`
+	TextPath          string `envconfig:"EMAIL_TEXT_TEMPLATE"`
+	HTMLPath          string `envconfig:"EMAIL_HTML_TEMPLATE"`
+	DigestSubjectPath string `envconfig:"EMAIL_DIGEST_SUBJECT_TEMPLATE"`
+	DigestTextPath    string `envconfig:"EMAIL_DIGEST_TEXT_TEMPLATE"`
+	DigestHTMLPath    string `envconfig:"EMAIL_DIGEST_HTML_TEMPLATE"`
 }
 
 // Sanitize represents the subset of configuration corresponding to the input string sanitization
@@ -36,6 +101,95 @@ type Sanitize struct {
 	NoSQL bool `envconfig:"SANITIZE_NO_SQL"`
 }
 
+// Redact represents the subset of configuration corresponding to the redaction of personal information from the feedback description
+type Redact struct {
+	NINO        bool `envconfig:"REDACT_NINO"`
+	PhoneNumber bool `envconfig:"REDACT_PHONE_NUMBER"`
+	Postcode    bool `envconfig:"REDACT_POSTCODE"`
+	CardNumber  bool `envconfig:"REDACT_CARD_NUMBER"`
+}
+
+// Store represents the subset of configuration corresponding to the feedback store
+type Store struct {
+	Path    string        `envconfig:"STORE_PATH"`
+	Timeout time.Duration `envconfig:"STORE_TIMEOUT"`
+}
+
+// Outbox represents the subset of configuration corresponding to the delivery of the emails queued in the outbox
+type Outbox struct {
+	PollInterval   time.Duration `envconfig:"OUTBOX_POLL_INTERVAL"`
+	BatchSize      int           `envconfig:"OUTBOX_BATCH_SIZE"`
+	MaxAttempts    int           `envconfig:"OUTBOX_MAX_ATTEMPTS"`
+	InitialBackoff time.Duration `envconfig:"OUTBOX_INITIAL_BACKOFF"`
+	MaxBackoff     time.Duration `envconfig:"OUTBOX_MAX_BACKOFF"`
+}
+
+// Digest represents the subset of configuration corresponding to the scheduling of digest emails
+type Digest struct {
+	PollInterval time.Duration `envconfig:"DIGEST_POLL_INTERVAL"`
+}
+
+// Webhook represents the subset of configuration corresponding to the delivery of feedback to an HTTP webhook
+type Webhook struct {
+	URL          string        `envconfig:"WEBHOOK_URL"`
+	Secret       string        `envconfig:"WEBHOOK_SECRET" json:"-"`
+	SecretFile   string        `envconfig:"WEBHOOK_SECRET_FILE"`
+	Timeout      time.Duration `envconfig:"WEBHOOK_TIMEOUT"`
+	MaxRetries   int           `envconfig:"WEBHOOK_MAX_RETRIES"`
+	RetryBackoff time.Duration `envconfig:"WEBHOOK_RETRY_BACKOFF"`
+	QueueSize    int           `envconfig:"WEBHOOK_QUEUE_SIZE"`
+}
+
+// Kafka represents the subset of configuration corresponding to the publication of feedback events to Kafka
+type Kafka struct {
+	Enabled               bool     `envconfig:"KAFKA_ENABLED"`
+	Addr                  []string `envconfig:"KAFKA_ADDR"`
+	Version               string   `envconfig:"KAFKA_VERSION"`
+	MinBrokersHealthy     int      `envconfig:"KAFKA_PRODUCER_MIN_BROKERS_HEALTHY"`
+	SecProtocol           string   `envconfig:"KAFKA_SEC_PROTO"`
+	SecCACerts            string   `envconfig:"KAFKA_SEC_CA_CERTS"`
+	SecSkipVerify         bool     `envconfig:"KAFKA_SEC_SKIP_VERIFY"`
+	FeedbackReceivedTopic string   `envconfig:"KAFKA_FEEDBACK_RECEIVED_TOPIC"`
+	QueueSize             int      `envconfig:"KAFKA_QUEUE_SIZE"`
+}
+
+// RateLimit represents the subset of configuration corresponding to the rate limiting of POST /feedback,
+// per authenticated caller and per client IP. A limit of 0 requests per minute disables the corresponding rate limiting.
+type RateLimit struct {
+	CallerPerMinute int      `envconfig:"RATE_LIMIT_CALLER_PER_MINUTE"`
+	CallerBurst     int      `envconfig:"RATE_LIMIT_CALLER_BURST"`
+	IPPerMinute     int      `envconfig:"RATE_LIMIT_IP_PER_MINUTE"`
+	IPBurst         int      `envconfig:"RATE_LIMIT_IP_BURST"`
+	TrustedProxies  []string `envconfig:"RATE_LIMIT_TRUSTED_PROXIES"`
+}
+
+// Spam represents the subset of configuration corresponding to the spam scoring of feedback submissions.
+// Feedback is tagged, quarantined or rejected if its score reaches the corresponding threshold. A threshold of 0 disables the action.
+type Spam struct {
+	TagThreshold        int      `envconfig:"SPAM_TAG_THRESHOLD"`
+	QuarantineThreshold int      `envconfig:"SPAM_QUARANTINE_THRESHOLD"`
+	RejectThreshold     int      `envconfig:"SPAM_REJECT_THRESHOLD"`
+	Phrases             []string `envconfig:"SPAM_PHRASES"`
+}
+
+// Duplicate represents the subset of configuration corresponding to the detection of near-duplicate feedback submissions.
+// Feedback is a near-duplicate of feedback from the same submitter received within the window if their similarity, from 0 to 1,
+// reaches the configured one. A window of 0 disables the detection.
+type Duplicate struct {
+	Window     time.Duration `envconfig:"DUPLICATE_WINDOW"`
+	Similarity float64       `envconfig:"DUPLICATE_SIMILARITY"`
+}
+
+// KafkaTLSProtocol is the value of KAFKA_SEC_PROTO that enables TLS for the connections to the Kafka brokers
+const KafkaTLSProtocol = "TLS"
+
+// DefaultSpamPhrases are the phrases, typical of link spam and SEO junk, that increase the spam score of feedback by default
+var DefaultSpamPhrases = []string{
+	"backlinks", "best price", "buy now", "casino", "cialis", "click here", "earn money", "escort", "forex",
+	"free money", "guest post", "increase your traffic", "investment opportunity", "payday loan", "porn",
+	"seo services", "viagra", "work from home",
+}
+
 var cfg *Config
 
 // Get returns the default config with any modifications through environment
@@ -54,23 +208,265 @@ func Get() (*Config, error) {
 		VersionPrefix:              "/v1",
 		FeedbackTo:                 "to@gmail.com",
 		FeedbackFrom:               "from@gmail.com",
+		ZebedeeURL:                 "http://localhost:8082",
+		DefaultLimit:               20,
+		DefaultOffset:              0,
+		DefaultMaximumLimit:        1000,
+		MinFormFillTime:            2 * time.Second,
+		IdempotencyWindow:          24 * time.Hour,
+		Notifiers:                  []string{NotifierEmail},
 		Mail: &Mail{
-			Host:      "localhost",
-			Port:      "1025",
-			User:      "",
-			Password:  "",
-			Encrypted: true,
+			Host:            "localhost",
+			Port:            "1025",
+			User:            "",
+			Password:        "",
+			Encrypted:       true,
+			TLSMode:         TLSModeStartTLS,
+			PoolSize:        2,
+			PoolIdleTimeout: 30 * time.Second,
 		},
+		EmailTemplates: &EmailTemplates{},
 		Sanitize: &Sanitize{
 			HTML:  true,
 			SQL:   true,
 			NoSQL: true,
 		},
+		Redact: &Redact{
+			NINO:        true,
+			PhoneNumber: true,
+			Postcode:    true,
+			CardNumber:  true,
+		},
+		Store: &Store{
+			Path:    "feedback.db",
+			Timeout: 5 * time.Second,
+		},
+		Outbox: &Outbox{
+			PollInterval:   5 * time.Second,
+			BatchSize:      50,
+			MaxAttempts:    10,
+			InitialBackoff: 30 * time.Second,
+			MaxBackoff:     time.Hour,
+		},
+		Digest: &Digest{
+			PollInterval: time.Minute,
+		},
+		Webhook: &Webhook{
+			Timeout:      5 * time.Second,
+			MaxRetries:   3,
+			RetryBackoff: time.Second,
+			QueueSize:    100,
+		},
+		Kafka: &Kafka{
+			Enabled:               false,
+			Addr:                  []string{"localhost:9092"},
+			Version:               "3.5.1",
+			MinBrokersHealthy:     1,
+			FeedbackReceivedTopic: "feedback-received",
+			QueueSize:             100,
+		},
+		RateLimit: &RateLimit{
+			CallerPerMinute: 600,
+			CallerBurst:     100,
+			IPPerMinute:     10,
+			IPBurst:         10,
+			TrustedProxies:  []string{"127.0.0.0/8", "::1/128", "10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "fc00::/7"},
+		},
+		Spam: &Spam{
+			TagThreshold:        3,
+			QuarantineThreshold: 6,
+			RejectThreshold:     10,
+			Phrases:             DefaultSpamPhrases,
+		},
+		Duplicate: &Duplicate{
+			Window:     10 * time.Minute,
+			Similarity: 0.7,
+		},
 	}
 
-	return cfg, envconfig.Process("", cfg)
+	if err := envconfig.Process("", cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, cfg.loadSecretFiles()
 }
 
-func (c *Config) Validate() error {
+// loadSecretFiles sets the secrets configured with a *_FILE variable, e.g. MAIL_PASSWORD_FILE, to the content of their file,
+// so that they can be provided by a secret store instead of environment variables
+func (c *Config) loadSecretFiles() error {
+	for _, s := range []struct {
+		name, file string
+		value      *string
+	}{
+		{"MAIL_USER", c.Mail.UserFile, &c.Mail.User},
+		{"MAIL_PASSWORD", c.Mail.PasswordFile, &c.Mail.Password},
+		{"WEBHOOK_SECRET", c.Webhook.SecretFile, &c.Webhook.Secret},
+	} {
+		if s.file == "" {
+			continue
+		}
+		if *s.value != "" {
+			return fmt.Errorf("%s and %s_FILE must not both be set", s.name, s.name)
+		}
+		v, err := ReadSecretFile(s.file)
+		if err != nil {
+			return fmt.Errorf("failed to load %s: %w", s.name, err)
+		}
+		*s.value = v
+	}
 	return nil
 }
+
+// ReadSecretFile returns the content of the secret file with the provided path, without any trailing line break
+func ReadSecretFile(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
+
+// HasNotifier returns true if the provided notifier is enabled
+func (c *Config) HasNotifier(name string) bool {
+	for _, n := range c.Notifiers {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that the configuration is consistent and usable, and returns an error listing all the problems found, if any
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(isHostPort(c.BindAddr), "BIND_ADDR must be a host and port, e.g. localhost:28600, got %q", c.BindAddr)
+	check(strings.TrimSpace(c.OnsDomain) != "", "ONS_DOMAIN must not be empty")
+	if _, err := ParseDomainRules(c.AllowedDomains); err != nil {
+		errs = append(errs, fmt.Errorf("ALLOWED_DOMAINS: %w", err))
+	}
+	check(isEmailAddress(c.FeedbackTo), "FEEDBACK_TO must be a valid email address, got %q", c.FeedbackTo)
+	check(isEmailAddress(c.FeedbackFrom), "FEEDBACK_FROM must be a valid email address, got %q", c.FeedbackFrom)
+	check(strings.HasPrefix(c.VersionPrefix, "/"), "VERSION_PREFIX must start with '/', got %q", c.VersionPrefix)
+
+	check(c.GracefulShutdownTimeout > 0, "GRACEFUL_SHUTDOWN_TIMEOUT must be positive, got %s", c.GracefulShutdownTimeout)
+	check(c.HealthCheckInterval > 0, "HEALTHCHECK_INTERVAL must be positive, got %s", c.HealthCheckInterval)
+	check(c.HealthCheckCriticalTimeout >= c.HealthCheckInterval,
+		"HEALTHCHECK_CRITICAL_TIMEOUT (%s) must not be shorter than HEALTHCHECK_INTERVAL (%s)", c.HealthCheckCriticalTimeout, c.HealthCheckInterval)
+	check(c.MinFormFillTime >= 0, "MIN_FORM_FILL_TIME must not be negative, got %s", c.MinFormFillTime)
+	check(c.IdempotencyWindow >= 0, "IDEMPOTENCY_WINDOW must not be negative, got %s", c.IdempotencyWindow)
+
+	for _, n := range c.Notifiers {
+		check(n == NotifierEmail || n == NotifierWebhook, "NOTIFIERS must only contain %q or %q, got %q", NotifierEmail, NotifierWebhook, n)
+	}
+
+	errs = append(errs, c.Mail.validate()...)
+	errs = append(errs, c.Kafka.validate()...)
+
+	check(c.Store.Timeout > 0, "STORE_TIMEOUT must be positive, got %s", c.Store.Timeout)
+	check(c.Outbox.PollInterval > 0, "OUTBOX_POLL_INTERVAL must be positive, got %s", c.Outbox.PollInterval)
+	check(c.Outbox.BatchSize > 0, "OUTBOX_BATCH_SIZE must be positive, got %d", c.Outbox.BatchSize)
+	check(c.Outbox.MaxAttempts > 0, "OUTBOX_MAX_ATTEMPTS must be positive, got %d", c.Outbox.MaxAttempts)
+	check(c.Outbox.InitialBackoff > 0, "OUTBOX_INITIAL_BACKOFF must be positive, got %s", c.Outbox.InitialBackoff)
+	check(c.Outbox.MaxBackoff >= c.Outbox.InitialBackoff,
+		"OUTBOX_MAX_BACKOFF (%s) must not be shorter than OUTBOX_INITIAL_BACKOFF (%s)", c.Outbox.MaxBackoff, c.Outbox.InitialBackoff)
+	check(c.Digest.PollInterval > 0, "DIGEST_POLL_INTERVAL must be positive, got %s", c.Digest.PollInterval)
+	if c.HasNotifier(NotifierWebhook) {
+		check(c.Webhook.URL != "", "WEBHOOK_URL must be set when the %q notifier is enabled", NotifierWebhook)
+		check(c.Webhook.Timeout > 0, "WEBHOOK_TIMEOUT must be positive, got %s", c.Webhook.Timeout)
+		check(c.Webhook.MaxRetries >= 0, "WEBHOOK_MAX_RETRIES must not be negative, got %d", c.Webhook.MaxRetries)
+		check(c.Webhook.QueueSize > 0, "WEBHOOK_QUEUE_SIZE must be positive, got %d", c.Webhook.QueueSize)
+	}
+	check(c.Duplicate.Window >= 0, "DUPLICATE_WINDOW must not be negative, got %s", c.Duplicate.Window)
+	if c.Duplicate.Window > 0 {
+		check(c.Duplicate.Similarity > 0 && c.Duplicate.Similarity <= 1, "DUPLICATE_SIMILARITY must be greater than 0 and at most 1, got %v", c.Duplicate.Similarity)
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate returns the problems of the mail configuration. PLAIN auth, used if MAIL_ENCRYPTION is true, sends the password
+// in clear, so a user can only be set with a TLS mode that encrypts the connection, unless the mail server is local.
+func (m *Mail) validate() []error {
+	var errs []error
+	if port, err := strconv.Atoi(m.Port); err != nil || port < 1 || port > 65535 {
+		errs = append(errs, fmt.Errorf("MAIL_PORT must be a number from 1 to 65535, got %q", m.Port))
+	}
+	if m.PoolIdleTimeout < 0 {
+		errs = append(errs, fmt.Errorf("MAIL_POOL_IDLE_TIMEOUT must not be negative, got %s", m.PoolIdleTimeout))
+	}
+
+	switch m.TLSMode {
+	case TLSModeNone:
+		if m.TLSCAFile != "" || m.TLSServerName != "" {
+			errs = append(errs, fmt.Errorf("MAIL_TLS_CA_FILE and MAIL_TLS_SERVER_NAME must not be set with MAIL_TLS_MODE %q", TLSModeNone))
+		}
+		if m.Encrypted && m.User != "" && !isLocalhost(m.Host) {
+			errs = append(errs, fmt.Errorf("MAIL_TLS_MODE must not be %q when MAIL_ENCRYPTION uses PLAIN auth with MAIL_USER on a remote MAIL_HOST", TLSModeNone))
+		}
+	case TLSModeStartTLS, TLSModeStartTLSRequired, TLSModeImplicitTLS:
+	default:
+		errs = append(errs, fmt.Errorf("MAIL_TLS_MODE must be one of %q, %q, %q or %q, got %q",
+			TLSModeNone, TLSModeStartTLS, TLSModeStartTLSRequired, TLSModeImplicitTLS, m.TLSMode))
+	}
+	return errs
+}
+
+// validate returns the problems of the Kafka configuration, which is only checked if Kafka is enabled
+func (k *Kafka) validate() []error {
+	if !k.Enabled {
+		return nil
+	}
+	var errs []error
+	if len(k.Addr) == 0 {
+		errs = append(errs, errors.New("KAFKA_ADDR must not be empty when Kafka is enabled"))
+	} else if k.MinBrokersHealthy < 1 || k.MinBrokersHealthy > len(k.Addr) {
+		errs = append(errs, fmt.Errorf("KAFKA_PRODUCER_MIN_BROKERS_HEALTHY must be from 1 to the number of brokers in KAFKA_ADDR (%d), got %d",
+			len(k.Addr), k.MinBrokersHealthy))
+	}
+	if k.FeedbackReceivedTopic == "" {
+		errs = append(errs, errors.New("KAFKA_FEEDBACK_RECEIVED_TOPIC must not be empty when Kafka is enabled"))
+	}
+	if k.QueueSize <= 0 {
+		errs = append(errs, fmt.Errorf("KAFKA_QUEUE_SIZE must be positive, got %d", k.QueueSize))
+	}
+	switch k.SecProtocol {
+	case "":
+		if k.SecCACerts != "" || k.SecSkipVerify {
+			errs = append(errs, fmt.Errorf("KAFKA_SEC_CA_CERTS and KAFKA_SEC_SKIP_VERIFY must not be set unless KAFKA_SEC_PROTO is %q", KafkaTLSProtocol))
+		}
+	case KafkaTLSProtocol:
+	default:
+		errs = append(errs, fmt.Errorf("KAFKA_SEC_PROTO must be empty or %q, got %q", KafkaTLSProtocol, k.SecProtocol))
+	}
+	return errs
+}
+
+// isHostPort returns true if the provided address is a host, possibly empty, and a port number
+func isHostPort(addr string) bool {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	p, err := strconv.Atoi(port)
+	return err == nil && p >= 0 && p <= 65535
+}
+
+// isEmailAddress returns true if the provided string is a single, bare email address
+func isEmailAddress(s string) bool {
+	a, err := mail.ParseAddress(s)
+	return err == nil && a.Address == s
+}
+
+// isLocalhost returns true if the provided host is the local machine
+func isLocalhost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
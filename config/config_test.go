@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -29,18 +30,80 @@ func TestConfig(t *testing.T) {
 					VersionPrefix:              "/v1",
 					FeedbackTo:                 "to@gmail.com",
 					FeedbackFrom:               "from@gmail.com",
+					ZebedeeURL:                 "http://localhost:8082",
+					DefaultLimit:               20,
+					DefaultOffset:              0,
+					DefaultMaximumLimit:        1000,
+					MinFormFillTime:            2 * time.Second,
+					IdempotencyWindow:          24 * time.Hour,
+					Notifiers:                  []string{NotifierEmail},
 					Mail: &Mail{
-						Host:      "localhost",
-						Port:      "1025",
-						User:      "",
-						Password:  "",
-						Encrypted: true,
+						Host:            "localhost",
+						Port:            "1025",
+						User:            "",
+						Password:        "",
+						Encrypted:       true,
+						TLSMode:         TLSModeStartTLS,
+						PoolSize:        2,
+						PoolIdleTimeout: 30 * time.Second,
 					},
+					EmailTemplates: &EmailTemplates{},
 					Sanitize: &Sanitize{
 						HTML:  true,
 						SQL:   true,
 						NoSQL: true,
 					},
+					Redact: &Redact{
+						NINO:        true,
+						PhoneNumber: true,
+						Postcode:    true,
+						CardNumber:  true,
+					},
+					Store: &Store{
+						Path:    "feedback.db",
+						Timeout: 5 * time.Second,
+					},
+					Outbox: &Outbox{
+						PollInterval:   5 * time.Second,
+						BatchSize:      50,
+						MaxAttempts:    10,
+						InitialBackoff: 30 * time.Second,
+						MaxBackoff:     time.Hour,
+					},
+					Digest: &Digest{
+						PollInterval: time.Minute,
+					},
+					Webhook: &Webhook{
+						Timeout:      5 * time.Second,
+						MaxRetries:   3,
+						RetryBackoff: time.Second,
+						QueueSize:    100,
+					},
+					Kafka: &Kafka{
+						Enabled:               false,
+						Addr:                  []string{"localhost:9092"},
+						Version:               "3.5.1",
+						MinBrokersHealthy:     1,
+						FeedbackReceivedTopic: "feedback-received",
+						QueueSize:             100,
+					},
+					RateLimit: &RateLimit{
+						CallerPerMinute: 600,
+						CallerBurst:     100,
+						IPPerMinute:     10,
+						IPBurst:         10,
+						TrustedProxies:  []string{"127.0.0.0/8", "::1/128", "10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "fc00::/7"},
+					},
+					Spam: &Spam{
+						TagThreshold:        3,
+						QuarantineThreshold: 6,
+						RejectThreshold:     10,
+						Phrases:             DefaultSpamPhrases,
+					},
+					Duplicate: &Duplicate{
+						Window:     10 * time.Minute,
+						Similarity: 0.7,
+					},
 				})
 			})
 			Convey("Then a second call to config should return the same config", func() {
@@ -52,3 +115,147 @@ func TestConfig(t *testing.T) {
 		})
 	})
 }
+
+// newConfig returns a new copy of the configuration, retrieved from the current environment
+func newConfig() (*Config, error) {
+	cfg = nil
+	defer func() { cfg = nil }()
+	return Get()
+}
+
+// validConfig returns a new copy of the default configuration
+func validConfig() *Config {
+	c, err := newConfig()
+	So(err, ShouldBeNil)
+	return c
+}
+
+func TestValidate(t *testing.T) {
+	os.Clearenv()
+	Convey("Given the default configuration", t, func() {
+		c := validConfig()
+
+		Convey("Then it is valid", func() {
+			So(c.Validate(), ShouldBeNil)
+		})
+
+		Convey("When each field has an invalid value", func() {
+			enableWebhook := func() { c.Notifiers, c.Webhook.URL = []string{NotifierWebhook}, "http://localhost:8080/hook" }
+			for _, tc := range []struct {
+				update func()
+				err    string
+			}{
+				{func() { c.BindAddr = "localhost" }, `BIND_ADDR must be a host and port, e.g. localhost:28600, got "localhost"`},
+				{func() { c.BindAddr = ":http" }, `BIND_ADDR must be a host and port, e.g. localhost:28600, got ":http"`},
+				{func() { c.OnsDomain = " " }, "ONS_DOMAIN must not be empty"},
+				{func() { c.AllowedDomains = []string{"ons.gov.uk", "*"} },
+					`ALLOWED_DOMAINS: invalid allowed domain "*": expected a host, e.g. ons.gov.uk, or *. followed by a host, optionally followed by a path`},
+				{func() { c.FeedbackTo = "Feedback <to@gmail.com>" }, `FEEDBACK_TO must be a valid email address, got "Feedback <to@gmail.com>"`},
+				{func() { c.FeedbackFrom = "from" }, `FEEDBACK_FROM must be a valid email address, got "from"`},
+				{func() { c.VersionPrefix = "v1" }, `VERSION_PREFIX must start with '/', got "v1"`},
+				{func() { c.GracefulShutdownTimeout = 0 }, "GRACEFUL_SHUTDOWN_TIMEOUT must be positive, got 0s"},
+				{func() { c.HealthCheckCriticalTimeout = 10 * time.Second },
+					"HEALTHCHECK_CRITICAL_TIMEOUT (10s) must not be shorter than HEALTHCHECK_INTERVAL (30s)"},
+				{func() { c.Notifiers = []string{"pigeon"} }, `NOTIFIERS must only contain "email" or "webhook", got "pigeon"`},
+				{func() { c.Mail.Port = "smtp" }, `MAIL_PORT must be a number from 1 to 65535, got "smtp"`},
+				{func() { c.Mail.Port = "70000" }, `MAIL_PORT must be a number from 1 to 65535, got "70000"`},
+				{func() { c.Mail.TLSMode = "ssl" },
+					`MAIL_TLS_MODE must be one of "none", "starttls", "starttls-required" or "implicit-tls", got "ssl"`},
+				{func() { c.Mail.TLSMode, c.Mail.TLSCAFile = TLSModeNone, "ca.pem" },
+					`MAIL_TLS_CA_FILE and MAIL_TLS_SERVER_NAME must not be set with MAIL_TLS_MODE "none"`},
+				{func() { c.Mail.TLSMode, c.Mail.Host, c.Mail.User = TLSModeNone, "smtp.example.com", "user" },
+					`MAIL_TLS_MODE must not be "none" when MAIL_ENCRYPTION uses PLAIN auth with MAIL_USER on a remote MAIL_HOST`},
+				{func() { c.Kafka.Enabled, c.Kafka.SecProtocol = true, "SSL" }, `KAFKA_SEC_PROTO must be empty or "TLS", got "SSL"`},
+				{func() { c.Kafka.Enabled, c.Kafka.SecSkipVerify = true, true },
+					`KAFKA_SEC_CA_CERTS and KAFKA_SEC_SKIP_VERIFY must not be set unless KAFKA_SEC_PROTO is "TLS"`},
+				{func() { c.Kafka.Enabled, c.Kafka.Addr = true, nil }, "KAFKA_ADDR must not be empty when Kafka is enabled"},
+				{func() { c.Kafka.Enabled, c.Kafka.QueueSize = true, 0 }, "KAFKA_QUEUE_SIZE must be positive, got 0"},
+				{func() { c.Kafka.Enabled, c.Kafka.MinBrokersHealthy = true, 2 },
+					"KAFKA_PRODUCER_MIN_BROKERS_HEALTHY must be from 1 to the number of brokers in KAFKA_ADDR (1), got 2"},
+				{func() { c.Store.Timeout = -time.Second }, "STORE_TIMEOUT must be positive, got -1s"},
+				{func() { c.Outbox.BatchSize = 0 }, "OUTBOX_BATCH_SIZE must be positive, got 0"},
+				{func() { c.Outbox.MaxAttempts = -1 }, "OUTBOX_MAX_ATTEMPTS must be positive, got -1"},
+				{func() { c.Outbox.MaxBackoff = time.Second }, "OUTBOX_MAX_BACKOFF (1s) must not be shorter than OUTBOX_INITIAL_BACKOFF (30s)"},
+				{func() { c.Notifiers = []string{NotifierWebhook} }, `WEBHOOK_URL must be set when the "webhook" notifier is enabled`},
+				{func() { enableWebhook(); c.Webhook.MaxRetries = -1 }, "WEBHOOK_MAX_RETRIES must not be negative, got -1"},
+				{func() { enableWebhook(); c.Webhook.QueueSize = 0 }, "WEBHOOK_QUEUE_SIZE must be positive, got 0"},
+				{func() { c.Duplicate.Similarity = 1.5 }, "DUPLICATE_SIMILARITY must be greater than 0 and at most 1, got 1.5"},
+			} {
+				c = validConfig()
+				tc.update()
+
+				Convey("Then the problem is reported: "+tc.err, func() {
+					err := c.Validate()
+					So(err, ShouldNotBeNil)
+					So(err.Error(), ShouldEqual, tc.err)
+				})
+			}
+		})
+
+		Convey("Then the mail user may be sent without TLS to a local mail server", func() {
+			c.Mail.TLSMode, c.Mail.User = TLSModeNone, "user"
+			So(c.Validate(), ShouldBeNil)
+		})
+
+		Convey("Then the duplicate similarity is not checked when duplicate detection is disabled", func() {
+			c.Duplicate.Window, c.Duplicate.Similarity = 0, 1.5
+			So(c.Validate(), ShouldBeNil)
+		})
+
+		Convey("When several fields are invalid", func() {
+			c.OnsDomain = ""
+			c.Mail.Port = ""
+			c.VersionPrefix = ""
+
+			Convey("Then all the problems are reported in a single error", func() {
+				So(c.Validate().Error(), ShouldEqual, "ONS_DOMAIN must not be empty\n"+
+					`VERSION_PREFIX must start with '/', got ""`+"\n"+
+					`MAIL_PORT must be a number from 1 to 65535, got ""`)
+			})
+		})
+	})
+}
+
+func TestSecretFiles(t *testing.T) {
+	Convey("Given secrets provided in files", t, func() {
+		os.Clearenv()
+		dir := t.TempDir()
+		for name, value := range map[string]string{"user": "mail-user\n", "password": "mail-password\r\n", "secret": "webhook-secret"} {
+			So(os.WriteFile(filepath.Join(dir, name), []byte(value), 0o600), ShouldBeNil)
+		}
+		t.Setenv("MAIL_USER_FILE", filepath.Join(dir, "user"))
+		t.Setenv("MAIL_PASSWORD_FILE", filepath.Join(dir, "password"))
+		t.Setenv("WEBHOOK_SECRET_FILE", filepath.Join(dir, "secret"))
+
+		Convey("When the config values are retrieved", func() {
+			c, err := newConfig()
+
+			Convey("Then the secrets are read from their files, without trailing line breaks", func() {
+				So(err, ShouldBeNil)
+				So(c.Mail.User, ShouldEqual, "mail-user")
+				So(c.Mail.Password, ShouldEqual, "mail-password")
+				So(c.Webhook.Secret, ShouldEqual, "webhook-secret")
+			})
+		})
+
+		Convey("When a secret is also provided in its environment variable", func() {
+			t.Setenv("MAIL_PASSWORD", "password")
+			_, err := newConfig()
+
+			Convey("Then the config values cannot be retrieved", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldEqual, "MAIL_PASSWORD and MAIL_PASSWORD_FILE must not both be set")
+			})
+		})
+
+		Convey("When a secret file does not exist", func() {
+			t.Setenv("WEBHOOK_SECRET_FILE", filepath.Join(dir, "missing"))
+			_, err := newConfig()
+
+			Convey("Then the config values cannot be retrieved", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldStartWith, "failed to load WEBHOOK_SECRET: failed to read secret file")
+			})
+		})
+	})
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// subdomainsPrefix marks an allowed domain that matches the subdomains of a host, e.g. "*.ons.gov.uk"
+const subdomainsPrefix = "*."
+
+// DomainRule allows the URLs of a site: their host must be Host or, if Subdomains is true, any of its subdomains,
+// and their path must start with one of Paths, unless there is none
+type DomainRule struct {
+	Host       string
+	Subdomains bool
+	Paths      []string
+}
+
+// String returns the host pattern of the rule, as configured, e.g. "*.ons.gov.uk"
+func (r *DomainRule) String() string {
+	if r.Subdomains {
+		return subdomainsPrefix + r.Host
+	}
+	return r.Host
+}
+
+// MatchesHost returns true if the provided host is allowed by the rule, ignoring the path
+func (r *DomainRule) MatchesHost(host string) bool {
+	host = strings.ToLower(host)
+	if r.Subdomains {
+		return strings.HasSuffix(host, "."+r.Host)
+	}
+	return host == r.Host
+}
+
+// AllowsPath returns true if the provided path starts with any of the paths of the rule, or if the rule does not restrict the paths.
+// Paths are matched by whole segments, so "/census" allows "/census/2021" but not "/censuses".
+func (r *DomainRule) AllowsPath(path string) bool {
+	if len(r.Paths) == 0 {
+		return true
+	}
+	for _, p := range r.Paths {
+		if path == p || strings.HasPrefix(path, strings.TrimSuffix(p, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseDomainRules returns the rules of the provided allowed domains, each of which is an exact host, e.g. "cy.ons.gov.uk",
+// or a pattern matching all the subdomains of a host, e.g. "*.ons.gov.uk", optionally followed by a path, e.g. "census.gov.uk/2021".
+// The paths of the allowed domains with the same host pattern are combined into the path allow-list of a single rule,
+// which allows all paths if any of them does not have a path.
+func ParseDomainRules(domains []string) ([]*DomainRule, error) {
+	var rules []*DomainRule
+	byPattern := map[string]*DomainRule{}
+	unrestricted := map[*DomainRule]bool{}
+	for _, d := range domains {
+		pattern, path, hasPath := strings.Cut(strings.TrimSpace(d), "/")
+		pattern = strings.ToLower(pattern)
+		rule := &DomainRule{Host: pattern}
+		if strings.HasPrefix(pattern, subdomainsPrefix) {
+			rule.Host, rule.Subdomains = strings.TrimPrefix(pattern, subdomainsPrefix), true
+		}
+		if rule.Host == "" || strings.ContainsAny(rule.Host, "*:") {
+			return nil, fmt.Errorf("invalid allowed domain %q: expected a host, e.g. ons.gov.uk, or *. followed by a host, optionally followed by a path", d)
+		}
+
+		if existing, ok := byPattern[rule.String()]; ok {
+			rule = existing
+		} else {
+			byPattern[rule.String()] = rule
+			rules = append(rules, rule)
+		}
+		if hasPath && path != "" {
+			rule.Paths = append(rule.Paths, "/"+path)
+		} else {
+			unrestricted[rule] = true
+		}
+	}
+
+	// an allowed domain without a path allows all the paths of its host pattern
+	for rule := range unrestricted {
+		rule.Paths = nil
+	}
+	return rules, nil
+}
+
+// DomainRules returns the rules of the domains that feedback is allowed from: ALLOWED_DOMAINS,
+// or ONS_DOMAIN and all its subdomains if it is empty
+func (c *Config) DomainRules() ([]*DomainRule, error) {
+	if len(c.AllowedDomains) == 0 {
+		return ParseDomainRules([]string{c.OnsDomain, subdomainsPrefix + c.OnsDomain})
+	}
+	return ParseDomainRules(c.AllowedDomains)
+}
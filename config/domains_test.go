@@ -0,0 +1,81 @@
+package config
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseDomainRules(t *testing.T) {
+	Convey("Given a list of allowed domains with hosts, subdomain patterns and paths", t, func() {
+		domains := []string{"ons.gov.uk", "*.ONS.gov.uk", "census.gov.uk/2021", "census.gov.uk/help/", "cy.ons.gov.uk", "cy.ons.gov.uk/economy"}
+
+		Convey("When they are parsed", func() {
+			rules, err := ParseDomainRules(domains)
+
+			Convey("Then there is a rule per host pattern, in order, combining their paths", func() {
+				So(err, ShouldBeNil)
+				So(rules, ShouldResemble, []*DomainRule{
+					{Host: "ons.gov.uk"},
+					{Host: "ons.gov.uk", Subdomains: true},
+					{Host: "census.gov.uk", Paths: []string{"/2021", "/help/"}},
+					{Host: "cy.ons.gov.uk"},
+				})
+				So(rules[1].String(), ShouldEqual, "*.ons.gov.uk")
+			})
+		})
+	})
+
+	Convey("Invalid allowed domains cannot be parsed", t, func() {
+		for _, d := range []string{"", "*.", "/path", "ons.*.uk", "ons.gov.uk:443"} {
+			_, err := ParseDomainRules([]string{d})
+			So(err, ShouldNotBeNil)
+		}
+	})
+}
+
+func TestDomainRule(t *testing.T) {
+	Convey("Given a rule for the subdomains of a host", t, func() {
+		rule := &DomainRule{Host: "ons.gov.uk", Subdomains: true}
+
+		Convey("Then it matches its subdomains, in any case, but not the host itself or other hosts", func() {
+			So(rule.MatchesHost("www.ons.gov.uk"), ShouldBeTrue)
+			So(rule.MatchesHost("Beta.ONS.gov.uk"), ShouldBeTrue)
+			So(rule.MatchesHost("ons.gov.uk"), ShouldBeFalse)
+			So(rule.MatchesHost("notons.gov.uk"), ShouldBeFalse)
+		})
+
+		Convey("Then it allows any path", func() {
+			So(rule.AllowsPath(""), ShouldBeTrue)
+			So(rule.AllowsPath("/economy"), ShouldBeTrue)
+		})
+	})
+
+	Convey("Given a rule for a host with a path allow-list", t, func() {
+		rule := &DomainRule{Host: "census.gov.uk", Paths: []string{"/2021", "/help/"}}
+
+		Convey("Then it only matches the host", func() {
+			So(rule.MatchesHost("census.gov.uk"), ShouldBeTrue)
+			So(rule.MatchesHost("www.census.gov.uk"), ShouldBeFalse)
+		})
+
+		Convey("Then it only allows the paths under the allowed paths, by whole segments", func() {
+			So(rule.AllowsPath("/2021"), ShouldBeTrue)
+			So(rule.AllowsPath("/2021/results"), ShouldBeTrue)
+			So(rule.AllowsPath("/help/"), ShouldBeTrue)
+			So(rule.AllowsPath("/help/contact"), ShouldBeTrue)
+			So(rule.AllowsPath("/20211"), ShouldBeFalse)
+			So(rule.AllowsPath("/"), ShouldBeFalse)
+		})
+	})
+
+	Convey("Given a configuration without allowed domains", t, func() {
+		c := &Config{OnsDomain: "ons.gov.uk"}
+
+		Convey("Then ONS_DOMAIN and all its subdomains are allowed", func() {
+			rules, err := c.DomainRules()
+			So(err, ShouldBeNil)
+			So(rules, ShouldResemble, []*DomainRule{{Host: "ons.gov.uk"}, {Host: "ons.gov.uk", Subdomains: true}})
+		})
+	})
+}
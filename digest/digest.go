@@ -0,0 +1,155 @@
+// Package digest accumulates the feedback of the routes configured with a digest, and sends a single email per route
+// and interval summarising all of it, instead of an email per submission.
+package digest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ONSdigital/dp-feedback-api/config"
+	"github.com/ONSdigital/dp-feedback-api/email"
+	"github.com/ONSdigital/dp-feedback-api/models"
+	"github.com/ONSdigital/dp-feedback-api/routing"
+	"github.com/ONSdigital/dp-feedback-api/worker"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// Now returns the current time, used to decide when the digests are due
+var Now = func() time.Time {
+	return time.Now().UTC()
+}
+
+// Scheduler persists the feedback of the routes with a digest in the store, and queues a digest email for a route once
+// its oldest feedback has been waiting for the interval of the route. As the feedback is persisted, digests that are
+// due while the service is stopped are sent once it is started again.
+type Scheduler struct {
+	store     Store
+	queue     Queue
+	routes    *routing.Table
+	templates *email.Templates
+	from      string
+	cfg       *config.Digest
+
+	sendMu sync.Mutex
+	worker *worker.Worker
+}
+
+// New returns a new Scheduler that persists feedback in the provided store, and queues the digests of the routes
+// in the provided table, generated from the provided templates, in the provided queue
+func New(store Store, queue Queue, routes *routing.Table, templates *email.Templates, from string, cfg *config.Digest) *Scheduler {
+	return &Scheduler{
+		store:     store,
+		queue:     queue,
+		routes:    routes,
+		templates: templates,
+		from:      from,
+		cfg:       cfg,
+		worker:    worker.New(),
+	}
+}
+
+// Add persists the feedback to be included in the next digest of the route with the provided name
+func (s *Scheduler) Add(ctx context.Context, route string, f *models.Feedback) error {
+	e := &models.DigestEntry{
+		ID:       route + "/" + f.ID,
+		Route:    route,
+		Feedback: f,
+		AddedAt:  Now(),
+	}
+	if err := s.store.AddDigestEntry(ctx, e); err != nil {
+		return fmt.Errorf("failed to add feedback to digest: %w", err)
+	}
+	return nil
+}
+
+// Start runs the scheduler in a new go-routine, which sends the digests that are due every poll interval,
+// until the scheduler is closed
+func (s *Scheduler) Start(ctx context.Context) {
+	s.worker.Start(func(stop <-chan struct{}) {
+		s.run(ctx, stop)
+	})
+}
+
+func (s *Scheduler) run(ctx context.Context, stop <-chan struct{}) {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		if err := s.SendDue(ctx); err != nil {
+			log.Error(ctx, "failed to send digests", err)
+		}
+	}
+}
+
+// SendDue queues the digest of every route whose oldest feedback has been waiting for at least the interval of the route.
+// A route that fails does not prevent the digests of the other routes from being sent.
+func (s *Scheduler) SendDue(ctx context.Context) error {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+
+	now := Now()
+	var errs []error
+	for _, route := range s.routes.DigestRoutes() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		entries, err := s.store.GetDigestEntries(ctx, route.Name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to get digest entries of route '%s': %w", route.Name, err))
+			continue
+		}
+		if len(entries) == 0 || now.Sub(entries[0].AddedAt) < route.DigestInterval() {
+			continue
+		}
+
+		if err := s.send(ctx, route, entries); err != nil {
+			errs = append(errs, fmt.Errorf("failed to send digest of route '%s': %w", route.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// send queues the digest email with the provided entries of a route, and then removes them from the store
+func (s *Scheduler) send(ctx context.Context, route *routing.Route, entries []*models.DigestEntry) error {
+	ids := make([]string, 0, len(entries))
+	feedback := make([]*models.Feedback, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, e.ID)
+		feedback = append(feedback, e.Feedback)
+	}
+
+	msg, err := s.templates.DigestMessage(route.Name, feedback, s.from, route.To)
+	if err != nil {
+		return fmt.Errorf("failed to generate message: %w", err)
+	}
+	b, err := msg.Bytes()
+	if err != nil {
+		return fmt.Errorf("failed to generate message: %w", err)
+	}
+	if err := s.queue.Enqueue(ctx, msg.From, msg.To, b); err != nil {
+		return fmt.Errorf("failed to queue message: %w", err)
+	}
+
+	// if this fails, the same feedback is sent again in the next digest, rather than being lost
+	if err := s.store.DeleteDigestEntries(ctx, ids); err != nil {
+		return fmt.Errorf("failed to delete sent digest entries: %w", err)
+	}
+	log.Info(ctx, "digest queued", log.Data{"route": route.Name, "count": len(entries)})
+	return nil
+}
+
+// Close stops the scheduler, waiting for any digest being sent. The feedback waiting for the next digests remains
+// in the store, to be sent once the service is started again.
+func (s *Scheduler) Close(ctx context.Context) error {
+	return s.worker.Stop(ctx)
+}
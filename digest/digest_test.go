@@ -0,0 +1,181 @@
+package digest_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-feedback-api/config"
+	"github.com/ONSdigital/dp-feedback-api/digest"
+	"github.com/ONSdigital/dp-feedback-api/digest/mock"
+	"github.com/ONSdigital/dp-feedback-api/email"
+	"github.com/ONSdigital/dp-feedback-api/models"
+	"github.com/ONSdigital/dp-feedback-api/routing"
+	"github.com/ONSdigital/dp-feedback-api/store"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var (
+	ctx           = context.Background()
+	testTime      = time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+	pageNotUseful = false
+	specificPage  = false
+	errQueue      = errors.New("queue error")
+)
+
+const testRoutes = `{"routes": [
+	{"name": "census", "path_prefixes": ["/census"], "digest": "hourly", "to": ["census@mail.com"]},
+	{"name": "economy", "path_prefixes": ["/economy"], "digest": "daily", "to": ["economy@mail.com"]},
+	{"name": "releases", "path_prefixes": ["/releases"], "to": ["releases@mail.com"]}
+]}`
+
+// setClock overrides the digest clock, returning a func to restore it and a func to move the clock forward
+func setClock() (restore func(), advance func(time.Duration)) {
+	now := digest.Now
+	current := testTime
+	digest.Now = func() time.Time { return current }
+	return func() { digest.Now = now }, func(d time.Duration) { current = current.Add(d) }
+}
+
+func testFeedback(id, onsURL string) *models.Feedback {
+	receivedAt := digest.Now()
+	return &models.Feedback{
+		ID:                id,
+		ReceivedAt:        &receivedAt,
+		IsPageUseful:      &pageNotUseful,
+		IsGeneralFeedback: &specificPage,
+		OnsURL:            onsURL,
+		Feedback:          "feedback " + id,
+	}
+}
+
+func newScheduler(t *testing.T, s digest.Store, q digest.Queue) *digest.Scheduler {
+	routesFile := filepath.Join(t.TempDir(), "routes.json")
+	So(os.WriteFile(routesFile, []byte(testRoutes), 0o600), ShouldBeNil)
+	routes, err := routing.Load(routesFile, "receiver@mail.com")
+	So(err, ShouldBeNil)
+	templates, err := email.LoadTemplates(&config.EmailTemplates{}, nil)
+	So(err, ShouldBeNil)
+	return digest.New(s, q, routes, templates, "sender@mail.com", &config.Digest{PollInterval: time.Hour})
+}
+
+func TestSendDue(t *testing.T) {
+	restore, advance := setClock()
+	defer restore()
+
+	Convey("Given a scheduler with some feedback added to the digests of two routes", t, func() {
+		s := store.NewMemory()
+		queue := &mock.QueueMock{
+			EnqueueFunc: func(ctx context.Context, from string, to []string, msg []byte) error { return nil },
+		}
+		scheduler := newScheduler(t, s, queue)
+
+		So(scheduler.Add(ctx, "census", testFeedback("c1", "https://localhost/census")), ShouldBeNil)
+		So(scheduler.Add(ctx, "economy", testFeedback("e1", "https://localhost/economy")), ShouldBeNil)
+		advance(30 * time.Minute)
+		So(scheduler.Add(ctx, "census", testFeedback("c2", "https://localhost/census/2021")), ShouldBeNil)
+
+		Convey("When the digests are checked before any interval has elapsed", func() {
+			So(scheduler.SendDue(ctx), ShouldBeNil)
+
+			Convey("Then no digest is sent", func() {
+				So(queue.EnqueueCalls(), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the digests are checked once the oldest feedback of a route has waited for its interval", func() {
+			advance(30 * time.Minute)
+			So(scheduler.SendDue(ctx), ShouldBeNil)
+
+			Convey("Then a single digest with all the feedback of the route is queued for its recipients", func() {
+				So(queue.EnqueueCalls(), ShouldHaveLength, 1)
+				call := queue.EnqueueCalls()[0]
+				So(call.From, ShouldEqual, "sender@mail.com")
+				So(call.To, ShouldResemble, []string{"census@mail.com"})
+				So(string(call.Msg), ShouldContainSubstring, "Subject: Feedback digest - census - 2 submissions\r\n")
+				So(string(call.Msg), ShouldContainSubstring, "Description: feedback c1")
+				So(string(call.Msg), ShouldContainSubstring, "Description: feedback c2")
+			})
+
+			Convey("Then the feedback of the route is removed from the store, and the feedback of other routes is kept", func() {
+				entries, err := s.GetDigestEntries(ctx, "census")
+				So(err, ShouldBeNil)
+				So(entries, ShouldBeEmpty)
+				entries, err = s.GetDigestEntries(ctx, "economy")
+				So(err, ShouldBeNil)
+				So(entries, ShouldHaveLength, 1)
+			})
+
+			Convey("Then the next digest of the route is not sent until new feedback has waited for the interval", func() {
+				So(scheduler.Add(ctx, "census", testFeedback("c3", "https://localhost/census")), ShouldBeNil)
+				advance(59 * time.Minute)
+				So(scheduler.SendDue(ctx), ShouldBeNil)
+				So(queue.EnqueueCalls(), ShouldHaveLength, 1)
+
+				advance(time.Minute)
+				So(scheduler.SendDue(ctx), ShouldBeNil)
+				So(queue.EnqueueCalls(), ShouldHaveLength, 2)
+				So(string(queue.EnqueueCalls()[1].Msg), ShouldContainSubstring, "Subject: Feedback digest - census - 1 submission\r\n")
+			})
+		})
+
+		Convey("When queueing a digest fails", func() {
+			queue.EnqueueFunc = func(ctx context.Context, from string, to []string, msg []byte) error {
+				return errQueue
+			}
+			advance(24 * time.Hour)
+			err := scheduler.SendDue(ctx)
+
+			Convey("Then the error of every route is returned, and the feedback is kept for the next digest", func() {
+				So(err, ShouldNotBeNil)
+				So(errors.Is(err, errQueue), ShouldBeTrue)
+				So(err.Error(), ShouldContainSubstring, "failed to send digest of route 'census'")
+				So(err.Error(), ShouldContainSubstring, "failed to send digest of route 'economy'")
+				So(queue.EnqueueCalls(), ShouldHaveLength, 2)
+
+				entries, err := s.GetDigestEntries(ctx, "census")
+				So(err, ShouldBeNil)
+				So(entries, ShouldHaveLength, 2)
+			})
+		})
+
+		Convey("When the same feedback is added to the digest of a route again", func() {
+			err := scheduler.Add(ctx, "census", testFeedback("c1", "https://localhost/census"))
+
+			Convey("Then it fails, so that the feedback is not sent twice", func() {
+				So(err, ShouldNotBeNil)
+				So(errors.Is(err, store.ErrAlreadyExists), ShouldBeTrue)
+			})
+		})
+	})
+}
+
+func TestStartAndClose(t *testing.T) {
+	Convey("Given a scheduler with an empty store", t, func() {
+		storeMock := &mock.StoreMock{
+			GetDigestEntriesFunc: func(ctx context.Context, route string) ([]*models.DigestEntry, error) { return nil, nil },
+		}
+		scheduler := newScheduler(t, storeMock, &mock.QueueMock{})
+
+		Convey("When it is started and then closed", func() {
+			scheduler.Start(ctx)
+			err := scheduler.Close(ctx)
+
+			Convey("Then it closes without sending any digest", func() {
+				So(err, ShouldBeNil)
+				So(storeMock.DeleteDigestEntriesCalls(), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When it is closed without being started", func() {
+			Convey("Then it closes straight away, and can no longer be started", func() {
+				So(scheduler.Close(ctx), ShouldBeNil)
+				scheduler.Start(ctx)
+				So(scheduler.Close(ctx), ShouldBeNil)
+			})
+		})
+	})
+}
@@ -0,0 +1,22 @@
+package digest
+
+import (
+	"context"
+
+	"github.com/ONSdigital/dp-feedback-api/models"
+)
+
+//go:generate moq -out mock/store.go -pkg mock . Store
+//go:generate moq -out mock/queue.go -pkg mock . Queue
+
+// Store defines the required methods to persist the feedback waiting to be included in digests
+type Store interface {
+	AddDigestEntry(ctx context.Context, e *models.DigestEntry) error
+	GetDigestEntries(ctx context.Context, route string) ([]*models.DigestEntry, error)
+	DeleteDigestEntries(ctx context.Context, ids []string) error
+}
+
+// Queue defines the required methods to queue the digest emails for delivery
+type Queue interface {
+	Enqueue(ctx context.Context, from string, to []string, msg []byte) error
+}
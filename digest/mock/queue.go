@@ -0,0 +1,94 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"context"
+	"github.com/ONSdigital/dp-feedback-api/digest"
+	"sync"
+)
+
+// Ensure, that QueueMock does implement digest.Queue.
+// If this is not the case, regenerate this file with moq.
+var _ digest.Queue = &QueueMock{}
+
+// QueueMock is a mock implementation of digest.Queue.
+//
+//	func TestSomethingThatUsesQueue(t *testing.T) {
+//
+//		// make and configure a mocked digest.Queue
+//		mockedQueue := &QueueMock{
+//			EnqueueFunc: func(ctx context.Context, from string, to []string, msg []byte) error {
+//				panic("mock out the Enqueue method")
+//			},
+//		}
+//
+//		// use mockedQueue in code that requires digest.Queue
+//		// and then make assertions.
+//
+//	}
+type QueueMock struct {
+	// EnqueueFunc mocks the Enqueue method.
+	EnqueueFunc func(ctx context.Context, from string, to []string, msg []byte) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Enqueue holds details about calls to the Enqueue method.
+		Enqueue []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// From is the from argument value.
+			From string
+			// To is the to argument value.
+			To []string
+			// Msg is the msg argument value.
+			Msg []byte
+		}
+	}
+	lockEnqueue sync.RWMutex
+}
+
+// Enqueue calls EnqueueFunc.
+func (mock *QueueMock) Enqueue(ctx context.Context, from string, to []string, msg []byte) error {
+	if mock.EnqueueFunc == nil {
+		panic("QueueMock.EnqueueFunc: method is nil but Queue.Enqueue was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		From string
+		To   []string
+		Msg  []byte
+	}{
+		Ctx:  ctx,
+		From: from,
+		To:   to,
+		Msg:  msg,
+	}
+	mock.lockEnqueue.Lock()
+	mock.calls.Enqueue = append(mock.calls.Enqueue, callInfo)
+	mock.lockEnqueue.Unlock()
+	return mock.EnqueueFunc(ctx, from, to, msg)
+}
+
+// EnqueueCalls gets all the calls that were made to Enqueue.
+// Check the length with:
+//
+//	len(mockedQueue.EnqueueCalls())
+func (mock *QueueMock) EnqueueCalls() []struct {
+	Ctx  context.Context
+	From string
+	To   []string
+	Msg  []byte
+} {
+	var calls []struct {
+		Ctx  context.Context
+		From string
+		To   []string
+		Msg  []byte
+	}
+	mock.lockEnqueue.RLock()
+	calls = mock.calls.Enqueue
+	mock.lockEnqueue.RUnlock()
+	return calls
+}
@@ -0,0 +1,183 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"context"
+	"github.com/ONSdigital/dp-feedback-api/digest"
+	"github.com/ONSdigital/dp-feedback-api/models"
+	"sync"
+)
+
+// Ensure, that StoreMock does implement digest.Store.
+// If this is not the case, regenerate this file with moq.
+var _ digest.Store = &StoreMock{}
+
+// StoreMock is a mock implementation of digest.Store.
+//
+//	func TestSomethingThatUsesStore(t *testing.T) {
+//
+//		// make and configure a mocked digest.Store
+//		mockedStore := &StoreMock{
+//			AddDigestEntryFunc: func(ctx context.Context, e *models.DigestEntry) error {
+//				panic("mock out the AddDigestEntry method")
+//			},
+//			DeleteDigestEntriesFunc: func(ctx context.Context, ids []string) error {
+//				panic("mock out the DeleteDigestEntries method")
+//			},
+//			GetDigestEntriesFunc: func(ctx context.Context, route string) ([]*models.DigestEntry, error) {
+//				panic("mock out the GetDigestEntries method")
+//			},
+//		}
+//
+//		// use mockedStore in code that requires digest.Store
+//		// and then make assertions.
+//
+//	}
+type StoreMock struct {
+	// AddDigestEntryFunc mocks the AddDigestEntry method.
+	AddDigestEntryFunc func(ctx context.Context, e *models.DigestEntry) error
+
+	// DeleteDigestEntriesFunc mocks the DeleteDigestEntries method.
+	DeleteDigestEntriesFunc func(ctx context.Context, ids []string) error
+
+	// GetDigestEntriesFunc mocks the GetDigestEntries method.
+	GetDigestEntriesFunc func(ctx context.Context, route string) ([]*models.DigestEntry, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// AddDigestEntry holds details about calls to the AddDigestEntry method.
+		AddDigestEntry []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// E is the e argument value.
+			E *models.DigestEntry
+		}
+		// DeleteDigestEntries holds details about calls to the DeleteDigestEntries method.
+		DeleteDigestEntries []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Ids is the ids argument value.
+			Ids []string
+		}
+		// GetDigestEntries holds details about calls to the GetDigestEntries method.
+		GetDigestEntries []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Route is the route argument value.
+			Route string
+		}
+	}
+	lockAddDigestEntry      sync.RWMutex
+	lockDeleteDigestEntries sync.RWMutex
+	lockGetDigestEntries    sync.RWMutex
+}
+
+// AddDigestEntry calls AddDigestEntryFunc.
+func (mock *StoreMock) AddDigestEntry(ctx context.Context, e *models.DigestEntry) error {
+	if mock.AddDigestEntryFunc == nil {
+		panic("StoreMock.AddDigestEntryFunc: method is nil but Store.AddDigestEntry was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		E   *models.DigestEntry
+	}{
+		Ctx: ctx,
+		E:   e,
+	}
+	mock.lockAddDigestEntry.Lock()
+	mock.calls.AddDigestEntry = append(mock.calls.AddDigestEntry, callInfo)
+	mock.lockAddDigestEntry.Unlock()
+	return mock.AddDigestEntryFunc(ctx, e)
+}
+
+// AddDigestEntryCalls gets all the calls that were made to AddDigestEntry.
+// Check the length with:
+//
+//	len(mockedStore.AddDigestEntryCalls())
+func (mock *StoreMock) AddDigestEntryCalls() []struct {
+	Ctx context.Context
+	E   *models.DigestEntry
+} {
+	var calls []struct {
+		Ctx context.Context
+		E   *models.DigestEntry
+	}
+	mock.lockAddDigestEntry.RLock()
+	calls = mock.calls.AddDigestEntry
+	mock.lockAddDigestEntry.RUnlock()
+	return calls
+}
+
+// DeleteDigestEntries calls DeleteDigestEntriesFunc.
+func (mock *StoreMock) DeleteDigestEntries(ctx context.Context, ids []string) error {
+	if mock.DeleteDigestEntriesFunc == nil {
+		panic("StoreMock.DeleteDigestEntriesFunc: method is nil but Store.DeleteDigestEntries was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Ids []string
+	}{
+		Ctx: ctx,
+		Ids: ids,
+	}
+	mock.lockDeleteDigestEntries.Lock()
+	mock.calls.DeleteDigestEntries = append(mock.calls.DeleteDigestEntries, callInfo)
+	mock.lockDeleteDigestEntries.Unlock()
+	return mock.DeleteDigestEntriesFunc(ctx, ids)
+}
+
+// DeleteDigestEntriesCalls gets all the calls that were made to DeleteDigestEntries.
+// Check the length with:
+//
+//	len(mockedStore.DeleteDigestEntriesCalls())
+func (mock *StoreMock) DeleteDigestEntriesCalls() []struct {
+	Ctx context.Context
+	Ids []string
+} {
+	var calls []struct {
+		Ctx context.Context
+		Ids []string
+	}
+	mock.lockDeleteDigestEntries.RLock()
+	calls = mock.calls.DeleteDigestEntries
+	mock.lockDeleteDigestEntries.RUnlock()
+	return calls
+}
+
+// GetDigestEntries calls GetDigestEntriesFunc.
+func (mock *StoreMock) GetDigestEntries(ctx context.Context, route string) ([]*models.DigestEntry, error) {
+	if mock.GetDigestEntriesFunc == nil {
+		panic("StoreMock.GetDigestEntriesFunc: method is nil but Store.GetDigestEntries was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Route string
+	}{
+		Ctx:   ctx,
+		Route: route,
+	}
+	mock.lockGetDigestEntries.Lock()
+	mock.calls.GetDigestEntries = append(mock.calls.GetDigestEntries, callInfo)
+	mock.lockGetDigestEntries.Unlock()
+	return mock.GetDigestEntriesFunc(ctx, route)
+}
+
+// GetDigestEntriesCalls gets all the calls that were made to GetDigestEntries.
+// Check the length with:
+//
+//	len(mockedStore.GetDigestEntriesCalls())
+func (mock *StoreMock) GetDigestEntriesCalls() []struct {
+	Ctx   context.Context
+	Route string
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Route string
+	}
+	mock.lockGetDigestEntries.RLock()
+	calls = mock.calls.GetDigestEntries
+	mock.lockGetDigestEntries.RUnlock()
+	return calls
+}
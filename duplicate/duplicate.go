@@ -0,0 +1,153 @@
+// Package duplicate detects the near-duplicates of recent feedback submissions, e.g. the same feedback submitted twice
+// by double-clicking, or the same complaint pasted on several pages, by the similarity of their fingerprints
+package duplicate
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/ONSdigital/dp-feedback-api/config"
+	"github.com/ONSdigital/dp-feedback-api/models"
+)
+
+// Now returns the current time, used to expire the recent submissions
+var Now = time.Now
+
+// urlTokenPrefix distinguishes the tokens of the URL from the words of the description
+const urlTokenPrefix = "url:"
+
+// submission is the fingerprint of a recent feedback submission
+type submission struct {
+	id         string
+	tokens     map[string]struct{}
+	receivedAt time.Time
+}
+
+// Detector keeps the fingerprints of the feedback submitted within the configured window, per submitter,
+// and detects the submissions that are similar enough to any of them.
+// Submitters are identified by their email address, so that the feedback of different people is never merged.
+type Detector struct {
+	window     time.Duration
+	similarity float64
+
+	mu        sync.Mutex
+	recent    map[string][]*submission // by submitter, oldest first
+	lastSweep time.Time
+}
+
+// New returns a Detector with the provided configuration
+func New(cfg *config.Duplicate) *Detector {
+	return &Detector{
+		window:     cfg.Window,
+		similarity: cfg.Similarity,
+		recent:     map[string][]*submission{},
+	}
+}
+
+// Check returns the id of the recent submission that the provided feedback is a near-duplicate of.
+// Otherwise, it records the feedback as a recent submission under its id, and returns an empty string.
+// Only feedback with a description and an email address is checked, so that the answers to "Is this page useful?" are all counted,
+// and anonymous feedback, which cannot be told apart from the feedback of other people, e.g. behind the same proxy, is never merged.
+func (d *Detector) Check(f *models.Feedback) string {
+	submitter := strings.ToLower(strings.TrimSpace(f.EmailAddress))
+	if submitter == "" || strings.TrimSpace(f.Feedback) == "" {
+		return ""
+	}
+	tokens := Fingerprint(f)
+	now := Now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.sweep(now)
+
+	recent := d.unexpired(d.recent[submitter], now)
+	for _, s := range recent {
+		if Similarity(tokens, s.tokens) >= d.similarity {
+			d.recent[submitter] = recent
+			return s.id
+		}
+	}
+	d.recent[submitter] = append(recent, &submission{id: f.ID, tokens: tokens, receivedAt: now})
+	return ""
+}
+
+// Forget removes the recent submission with the provided id, e.g. if it could not be stored,
+// so that its near-duplicates are not counted as occurrences of it
+func (d *Detector) Forget(id string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for submitter, recent := range d.recent {
+		for i, s := range recent {
+			if s.id == id {
+				d.recent[submitter] = append(recent[:i:i], recent[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// unexpired returns the provided submissions that were received within the window
+func (d *Detector) unexpired(recent []*submission, now time.Time) []*submission {
+	for i, s := range recent {
+		if now.Sub(s.receivedAt) < d.window {
+			return recent[i:]
+		}
+	}
+	return nil
+}
+
+// sweep removes the submitters without any unexpired submissions, so that they do not accumulate.
+// It runs at most once per window.
+func (d *Detector) sweep(now time.Time) {
+	if now.Sub(d.lastSweep) < d.window {
+		return
+	}
+	for submitter, recent := range d.recent {
+		if len(d.unexpired(recent, now)) == 0 {
+			delete(d.recent, submitter)
+		}
+	}
+	d.lastSweep = now
+}
+
+// Fingerprint returns the set of normalised tokens of the provided feedback: the lower case words of its description,
+// ignoring punctuation and repeated words, and the path segments of its URL
+func Fingerprint(f *models.Feedback) map[string]struct{} {
+	tokens := map[string]struct{}{}
+	words := strings.FieldsFunc(strings.ToLower(f.Feedback), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, w := range words {
+		tokens[w] = struct{}{}
+	}
+
+	if f.OnsURL != "" {
+		if u, err := url.Parse(models.NormaliseURL(strings.ToLower(f.OnsURL))); err == nil {
+			for _, segment := range strings.Split(u.Path, "/") {
+				if segment != "" {
+					tokens[urlTokenPrefix+segment] = struct{}{}
+				}
+			}
+		}
+	}
+	return tokens
+}
+
+// Similarity returns the Jaccard similarity of the provided sets of tokens, from 0 if they have nothing in common to 1 if they are equal
+func Similarity(a, b map[string]struct{}) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	common := 0
+	for t := range a {
+		if _, ok := b[t]; ok {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
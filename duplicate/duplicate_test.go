@@ -0,0 +1,119 @@
+package duplicate_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-feedback-api/config"
+	"github.com/ONSdigital/dp-feedback-api/duplicate"
+	"github.com/ONSdigital/dp-feedback-api/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var testTime = time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+
+// setClock makes the detectors use a clock that starts at testTime, and returns a function to move it forward
+func setClock(t *testing.T) func(d time.Duration) {
+	now := duplicate.Now
+	t.Cleanup(func() { duplicate.Now = now })
+
+	current := testTime
+	duplicate.Now = func() time.Time { return current }
+	return func(d time.Duration) { current = current.Add(d) }
+}
+
+// feedback returns feedback from the same submitter, identified by their email address
+func feedback(id, onsURL, description string) *models.Feedback {
+	return &models.Feedback{ID: id, OnsURL: onsURL, Feedback: description, EmailAddress: "someone@example.com"}
+}
+
+func TestCheck(t *testing.T) {
+	Convey("Given a detector with a window of 10 minutes and a similarity of 0.7", t, func() {
+		advance := setClock(t)
+		d := duplicate.New(&config.Duplicate{Window: 10 * time.Minute, Similarity: 0.7})
+
+		complaint := "I could not find the latest inflation figures anywhere on the website"
+		So(d.Check(feedback("1", "https://www.ons.gov.uk/economy/inflation", complaint)), ShouldBeEmpty)
+
+		Convey("Then the same feedback submitted again is a near-duplicate of the first one", func() {
+			So(d.Check(feedback("2", "https://www.ons.gov.uk/economy/inflation", complaint)), ShouldEqual, "1")
+		})
+
+		Convey("Then feedback that only differs in case, punctuation or a word is a near-duplicate", func() {
+			f := feedback("2", "www.ons.gov.uk/economy/inflation", "I could NOT find the latest inflation figures anywhere on this website!!")
+			So(d.Check(f), ShouldEqual, "1")
+		})
+
+		Convey("Then the same complaint pasted on another page is a near-duplicate", func() {
+			So(d.Check(feedback("2", "https://www.ons.gov.uk/census", complaint)), ShouldEqual, "1")
+		})
+
+		Convey("Then the same feedback with the email address in another case is a near-duplicate", func() {
+			f := feedback("2", "https://www.ons.gov.uk/economy/inflation", complaint)
+			f.EmailAddress = " Someone@Example.com"
+			So(d.Check(f), ShouldEqual, "1")
+		})
+
+		Convey("Then different feedback from the same submitter is not a near-duplicate, and is recorded", func() {
+			So(d.Check(feedback("2", "https://www.ons.gov.uk/economy/inflation", "The chart does not load")), ShouldBeEmpty)
+			So(d.Check(feedback("3", "https://www.ons.gov.uk/economy/inflation", "the chart does not load")), ShouldEqual, "2")
+		})
+
+		Convey("Then the same feedback from another email address is not a near-duplicate", func() {
+			f := feedback("2", "https://www.ons.gov.uk/economy/inflation", complaint)
+			f.EmailAddress = "someone.else@example.com"
+			So(d.Check(f), ShouldBeEmpty)
+		})
+
+		Convey("Then the same feedback received after the window is not a near-duplicate", func() {
+			advance(10 * time.Minute)
+			So(d.Check(feedback("2", "https://www.ons.gov.uk/economy/inflation", complaint)), ShouldBeEmpty)
+		})
+
+		Convey("Then the same feedback is not a near-duplicate once the first one is forgotten", func() {
+			d.Forget("1")
+			So(d.Check(feedback("2", "https://www.ons.gov.uk/economy/inflation", complaint)), ShouldBeEmpty)
+		})
+	})
+
+	Convey("Given a detector and some short feedback", t, func() {
+		setClock(t)
+		d := duplicate.New(&config.Duplicate{Window: 10 * time.Minute, Similarity: 0.7})
+		So(d.Check(feedback("1", "https://www.ons.gov.uk/economy/inflation", "broken link")), ShouldBeEmpty)
+
+		Convey("Then the same short feedback on another page is not a near-duplicate, as the pages are too different", func() {
+			So(d.Check(feedback("2", "https://www.ons.gov.uk/census", "broken link")), ShouldBeEmpty)
+		})
+
+		Convey("Then feedback without a description is never a near-duplicate", func() {
+			So(d.Check(feedback("2", "https://www.ons.gov.uk/census", "")), ShouldBeEmpty)
+			So(d.Check(feedback("3", "https://www.ons.gov.uk/census", "")), ShouldBeEmpty)
+		})
+	})
+
+	Convey("Given a detector and anonymous feedback", t, func() {
+		setClock(t)
+		d := duplicate.New(&config.Duplicate{Window: 10 * time.Minute, Similarity: 0.7})
+		f := feedback("1", "", "please add more regional data")
+		f.EmailAddress = ""
+		So(d.Check(f), ShouldBeEmpty)
+
+		Convey("Then the same anonymous feedback is never a near-duplicate, as it may have been sent by someone else", func() {
+			f := feedback("2", "", "please add more regional data")
+			f.EmailAddress = ""
+			So(d.Check(f), ShouldBeEmpty)
+		})
+	})
+}
+
+func TestSimilarity(t *testing.T) {
+	Convey("The similarity of fingerprints is the proportion of tokens they have in common", t, func() {
+		a := duplicate.Fingerprint(feedback("", "https://www.ons.gov.uk/economy", "the data is wrong"))
+		So(a, ShouldResemble, map[string]struct{}{"the": {}, "data": {}, "is": {}, "wrong": {}, "url:economy": {}})
+
+		So(duplicate.Similarity(a, a), ShouldEqual, 1)
+		b := duplicate.Fingerprint(feedback("", "https://www.ons.gov.uk/economy", "the data is right"))
+		So(duplicate.Similarity(a, b), ShouldEqual, 4.0/6)
+		So(duplicate.Similarity(a, map[string]struct{}{}), ShouldEqual, 0)
+	})
+}
@@ -0,0 +1,60 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/textproto"
+	"time"
+
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+)
+
+// CheckerName is the name of the SMTP server check reported by the healthcheck
+const CheckerName = "SMTP"
+
+// checkTimeout is the maximum duration of a check, if the provided context has no earlier deadline
+const checkTimeout = 10 * time.Second
+
+// Health check messages
+const (
+	MsgHealthy = "SMTP server is healthy"
+)
+
+// Checker checks the health of the SMTP server by connecting to it and going through the same handshake used to send emails:
+// EHLO, TLS according to the configured mode and AUTH, followed by NOOP and QUIT.
+// The state is CRITICAL if the server cannot be reached, and WARNING if it is reachable but the handshake fails.
+func (s *SMTPSender) Checker(ctx context.Context, state *healthcheck.CheckState) error {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	c, err := s.dial(ctx)
+	if err != nil {
+		var connErr *connectError
+		if errors.As(err, &connErr) {
+			return updateState(state, healthcheck.StatusCritical, err)
+		}
+		return updateState(state, healthcheck.StatusWarning, err)
+	}
+	defer c.Close()
+
+	if err := c.Noop(); err != nil {
+		return updateState(state, healthcheck.StatusWarning, fmt.Errorf("SMTP NOOP failed: %w", err))
+	}
+	if err := c.Quit(); err != nil {
+		return updateState(state, healthcheck.StatusWarning, fmt.Errorf("SMTP QUIT failed: %w", err))
+	}
+
+	return state.Update(healthcheck.StatusOK, MsgHealthy, 0)
+}
+
+// updateState updates the check state with the provided status and error,
+// using the SMTP reply code as status code when the server replied with an error
+func updateState(state *healthcheck.CheckState, status string, err error) error {
+	code := 0
+	var protoErr *textproto.Error
+	if errors.As(err, &protoErr) {
+		code = protoErr.Code
+	}
+	return state.Update(status, err.Error(), code)
+}
@@ -0,0 +1,118 @@
+package email_test
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"testing"
+
+	"github.com/ONSdigital/dp-feedback-api/config"
+	"github.com/ONSdigital/dp-feedback-api/email"
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var ctx = context.Background()
+
+func testMailConfig(port string) *config.Mail {
+	return &config.Mail{
+		Host:      "127.0.0.1",
+		Port:      port,
+		User:      "user",
+		Password:  "password",
+		Encrypted: true,
+		TLSMode:   config.TLSModeStartTLS,
+	}
+}
+
+func newTestSender(cfg *config.Mail) *email.SMTPSender {
+	sender, err := email.NewSMTPSender(cfg)
+	So(err, ShouldBeNil)
+	return sender
+}
+
+func newCheckState() *healthcheck.CheckState {
+	return healthcheck.NewCheckState(email.CheckerName)
+}
+
+func TestChecker(t *testing.T) {
+	Convey("Given a healthy SMTP server that supports AUTH", t, func() {
+		srv := newFakeSMTPServer(t, fakeSMTPConfig{Extensions: []string{"AUTH PLAIN CRAM-MD5"}})
+		state := newCheckState()
+
+		Convey("When the checker is run", func() {
+			err := newTestSender(testMailConfig(srv.Port())).Checker(ctx, state)
+			So(err, ShouldBeNil)
+
+			Convey("Then the state is OK, after going through the whole handshake", func() {
+				So(state.Status(), ShouldEqual, healthcheck.StatusOK)
+				So(state.Message(), ShouldEqual, email.MsgHealthy)
+				So(srv.Commands(), ShouldResemble, []string{"EHLO", "AUTH", "NOOP", "QUIT"})
+			})
+		})
+	})
+
+	Convey("Given an SMTP server that rejects the credentials", t, func() {
+		srv := newFakeSMTPServer(t, fakeSMTPConfig{Extensions: []string{"AUTH PLAIN"}, AuthReply: "535 authentication failed"})
+		state := newCheckState()
+
+		Convey("When the checker is run, then the state is WARNING, with the SMTP reply code", func() {
+			So(newTestSender(testMailConfig(srv.Port())).Checker(ctx, state), ShouldBeNil)
+			So(state.Status(), ShouldEqual, healthcheck.StatusWarning)
+			So(state.StatusCode(), ShouldEqual, 535)
+			So(state.Message(), ShouldContainSubstring, "SMTP AUTH failed")
+		})
+	})
+
+	Convey("Given an SMTP server that fails to reply to NOOP", t, func() {
+		srv := newFakeSMTPServer(t, fakeSMTPConfig{Extensions: []string{"AUTH PLAIN"}, NoopReply: "421 service not available"})
+		state := newCheckState()
+
+		Convey("When the checker is run, then the state is WARNING", func() {
+			So(newTestSender(testMailConfig(srv.Port())).Checker(ctx, state), ShouldBeNil)
+			So(state.Status(), ShouldEqual, healthcheck.StatusWarning)
+			So(state.StatusCode(), ShouldEqual, 421)
+			So(state.Message(), ShouldContainSubstring, "SMTP NOOP failed")
+		})
+	})
+
+	Convey("Given an SMTP server that does not support AUTH", t, func() {
+		srv := newFakeSMTPServer(t, fakeSMTPConfig{})
+		state := newCheckState()
+
+		Convey("When the checker is run, then the state is WARNING as emails could not be sent", func() {
+			So(newTestSender(testMailConfig(srv.Port())).Checker(ctx, state), ShouldBeNil)
+			So(state.Status(), ShouldEqual, healthcheck.StatusWarning)
+			So(state.Message(), ShouldContainSubstring, "server doesn't support AUTH")
+		})
+	})
+
+	Convey("Given an SMTP server that supports STARTTLS with a certificate that is not trusted", t, func() {
+		cert, _ := newTestCertificate(t)
+		srv := newFakeSMTPServer(t, fakeSMTPConfig{
+			Extensions: []string{"STARTTLS", "AUTH PLAIN"},
+			TLSConfig:  &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12},
+		})
+		state := newCheckState()
+
+		Convey("When the checker is run, then the state is WARNING", func() {
+			So(newTestSender(testMailConfig(srv.Port())).Checker(ctx, state), ShouldBeNil)
+			So(state.Status(), ShouldEqual, healthcheck.StatusWarning)
+			So(state.Message(), ShouldContainSubstring, "SMTP STARTTLS failed")
+		})
+	})
+
+	Convey("Given an SMTP server that cannot be reached", t, func() {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		So(err, ShouldBeNil)
+		_, port, _ := net.SplitHostPort(l.Addr().String())
+		So(l.Close(), ShouldBeNil)
+		state := newCheckState()
+
+		Convey("When the checker is run, then the state is CRITICAL", func() {
+			So(newTestSender(testMailConfig(port)).Checker(ctx, state), ShouldBeNil)
+			So(state.Status(), ShouldEqual, healthcheck.StatusCritical)
+			So(state.Message(), ShouldContainSubstring, "failed to connect to SMTP server")
+		})
+	})
+}
@@ -0,0 +1,99 @@
+package email
+
+import (
+	"context"
+	"net/smtp"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ONSdigital/dp-feedback-api/config"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// Credentials are the user and password used to authenticate with the mail server, some of which are read from files,
+// e.g. rendered by a secret store. The files are read again whenever they change, so that rotated credentials are used
+// for the next connections without restarting the service.
+type Credentials struct {
+	cfg config.Mail
+
+	mu       sync.Mutex
+	versions map[string]fileVersion
+	auth     smtp.Auth
+}
+
+// fileVersion identifies the content of a file by its modification time and size
+type fileVersion struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewCredentials returns the Credentials of the provided mail configuration, reading the credentials configured with a file
+func NewCredentials(cfg *config.Mail) (*Credentials, error) {
+	c := &Credentials{cfg: *cfg, versions: map[string]fileVersion{}}
+	if _, err := c.reload(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Auth returns the authentication to use with the mail server, after reading the credentials again if any of their files has changed.
+// If a file cannot be read, e.g. while it is being rotated, the previous credentials are used.
+func (c *Credentials) Auth() smtp.Auth {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	reloaded, err := c.reload()
+	if err != nil {
+		log.Error(context.Background(), "failed to reload mail credentials, the previous ones are used", err)
+	} else if reloaded {
+		log.Info(context.Background(), "reloaded mail credentials")
+	}
+	return c.auth
+}
+
+// reload reads the credentials files that have changed since they were last read, and returns true if any of them had changed
+func (c *Credentials) reload() (bool, error) {
+	user, password := c.cfg.User, c.cfg.Password
+	versions := map[string]fileVersion{}
+	changed := c.auth == nil
+	for _, s := range []struct {
+		file  string
+		value *string
+	}{
+		{c.cfg.UserFile, &user},
+		{c.cfg.PasswordFile, &password},
+	} {
+		if s.file == "" {
+			continue
+		}
+		info, err := os.Stat(s.file)
+		if err != nil {
+			return false, err
+		}
+		v := fileVersion{modTime: info.ModTime(), size: info.Size()}
+		if v != c.versions[s.file] {
+			if *s.value, err = config.ReadSecretFile(s.file); err != nil {
+				return false, err
+			}
+			changed = true
+		}
+		versions[s.file] = v
+	}
+	if !changed {
+		return false, nil
+	}
+
+	c.cfg.User, c.cfg.Password = user, password
+	c.versions = versions
+	c.auth = newAuth(&c.cfg)
+	return true, nil
+}
+
+// newAuth returns the authentication with the configured credentials: PLAIN if the mail encryption is enabled, or CRAM-MD5 otherwise
+func newAuth(cfg *config.Mail) smtp.Auth {
+	if cfg.Encrypted {
+		return smtp.PlainAuth("", cfg.User, cfg.Password, cfg.Host)
+	}
+	return smtp.CRAMMD5Auth(cfg.User, cfg.Password)
+}
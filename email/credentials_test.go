@@ -0,0 +1,70 @@
+package email_test
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-feedback-api/email"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// plainAuth returns the argument of the AUTH PLAIN command sent with the provided credentials
+func plainAuth(user, password string) string {
+	return "PLAIN " + base64.StdEncoding.EncodeToString([]byte("\x00"+user+"\x00"+password))
+}
+
+// writeSecret writes a secret file, with a modification time that differs from any previous version of the file
+func writeSecret(path, value string, version int) {
+	So(os.WriteFile(path, []byte(value+"\n"), 0o600), ShouldBeNil)
+	modTime := time.Date(2024, 3, 15, 10, 30, version, 0, time.UTC)
+	So(os.Chtimes(path, modTime, modTime), ShouldBeNil)
+}
+
+func TestCredentials(t *testing.T) {
+	Convey("Given an SMTP sender with its password read from a file", t, func() {
+		srv := newFakeSMTPServer(t, fakeSMTPConfig{Extensions: []string{"AUTH PLAIN"}})
+		passwordFile := filepath.Join(t.TempDir(), "password")
+		writeSecret(passwordFile, "password", 1)
+
+		cfg := testMailConfig(srv.Port())
+		cfg.Password = ""
+		cfg.PasswordFile = passwordFile
+		sender := newTestSender(cfg)
+
+		Convey("Then it authenticates with the password in the file", func() {
+			So(sender.Send("sender@mail.com", []string{"receiver@mail.com"}, testMsg), ShouldBeNil)
+			So(srv.Auths(), ShouldResemble, []string{plainAuth("user", "password")})
+		})
+
+		Convey("When the password is rotated", func() {
+			writeSecret(passwordFile, "rotated", 2)
+
+			Convey("Then the next connection authenticates with the new password", func() {
+				So(sender.Send("sender@mail.com", []string{"receiver@mail.com"}, testMsg), ShouldBeNil)
+				So(srv.Auths(), ShouldResemble, []string{plainAuth("user", "rotated")})
+			})
+		})
+
+		Convey("When the password file is removed", func() {
+			So(os.Remove(passwordFile), ShouldBeNil)
+
+			Convey("Then the previous password is still used", func() {
+				So(sender.Send("sender@mail.com", []string{"receiver@mail.com"}, testMsg), ShouldBeNil)
+				So(srv.Auths(), ShouldResemble, []string{plainAuth("user", "password")})
+			})
+		})
+	})
+
+	Convey("Given a mail configuration with a user file that does not exist", t, func() {
+		cfg := testMailConfig("1025")
+		cfg.UserFile = filepath.Join(t.TempDir(), "missing")
+
+		Convey("Then an SMTP sender cannot be created", func() {
+			_, err := email.NewSMTPSender(cfg)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,87 @@
+package email
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ONSdigital/dp-feedback-api/models"
+)
+
+// DigestData is the data available to the digest email templates
+type DigestData struct {
+	Route string
+	Count int
+	// Start and End are the times the oldest and the newest feedback in the digest were received
+	Start time.Time
+	End   time.Time
+	// Pages group the feedback by page, the pages with the most feedback first
+	Pages []*DigestPage
+}
+
+// DigestPage is the feedback in a digest about the same page, or all the general feedback
+type DigestPage struct {
+	IsGeneralFeedback bool
+	PageURL           string
+	PagePath          string
+	Count             int
+	Feedback          []*FeedbackData
+}
+
+// NewDigestData returns the template data for a digest of the provided feedback, which is expected to be sorted oldest first
+func NewDigestData(route string, feedback []*models.Feedback) *DigestData {
+	d := &DigestData{Route: route, Count: len(feedback)}
+
+	pages := map[string]*DigestPage{}
+	for _, f := range feedback {
+		data := NewFeedbackData(f)
+		if d.Start.IsZero() || data.ReceivedAt.Before(d.Start) {
+			d.Start = data.ReceivedAt
+		}
+		if data.ReceivedAt.After(d.End) {
+			d.End = data.ReceivedAt
+		}
+
+		key := pageKey(data)
+		page, ok := pages[key]
+		if !ok {
+			page = &DigestPage{IsGeneralFeedback: data.IsGeneralFeedback, PageURL: data.PageURL, PagePath: data.PagePath}
+			pages[key] = page
+			d.Pages = append(d.Pages, page)
+		}
+		page.Count++
+		page.Feedback = append(page.Feedback, data)
+	}
+
+	sort.SliceStable(d.Pages, func(i, j int) bool {
+		if d.Pages[i].Count != d.Pages[j].Count {
+			return d.Pages[i].Count > d.Pages[j].Count
+		}
+		return d.Pages[i].PageURL < d.Pages[j].PageURL
+	})
+	return d
+}
+
+// pageKey returns the key used to group feedback about the same page, regardless of the scheme and trailing slash of its URL
+func pageKey(data *FeedbackData) string {
+	if data.IsGeneralFeedback {
+		return ""
+	}
+	key := strings.TrimPrefix(strings.TrimPrefix(data.PageURL, "https://"), "http://")
+	return "page:" + strings.ToLower(strings.TrimSuffix(key, "/"))
+}
+
+// DigestMessage renders the digest templates for the feedback of a route and returns the resulting message.
+// The feedback is sanitized in the subject and the text body, while the html template escapes it itself.
+func (t *Templates) DigestMessage(route string, feedback []*models.Feedback, from string, to []string) (*Message, error) {
+	sanitized := make([]*models.Feedback, 0, len(feedback))
+	for _, f := range feedback {
+		sanitized = append(sanitized, t.sanitized(f))
+	}
+	msg, err := t.digest.render(NewDigestData(route, sanitized), NewDigestData(route, feedback), from, to)
+	if err != nil {
+		return nil, err
+	}
+	msg.MessageID = NewMessageID("", from)
+	return msg, nil
+}
@@ -0,0 +1,109 @@
+package email_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-feedback-api/config"
+	"github.com/ONSdigital/dp-feedback-api/email"
+	"github.com/ONSdigital/dp-feedback-api/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var expectedDigestText = `3 feedback submissions received between 15 Mar 2024 10:30 UTC and 15 Mar 2024 11:30 UTC
+
+https://testhost:1234/sub/path (2)
+
+- Received: 15 Mar 2024 10:30 UTC
+  Description: very nice and useful website!
+  Name: Mr Feedback reporter
+  Email address: feedback@reporter.com
+
+- Received: 15 Mar 2024 11:30 UTC
+  Description: broken link
+
+General feedback (1)
+
+- Received: 15 Mar 2024 11:00 UTC
+  Description: very nice and useful website!
+  Name: Mr Feedback reporter
+  Email address: feedback@reporter.com
+`
+
+// testDigestFeedback returns feedback received at 10:30, 11:00 and 11:30, where the first and the last are about the same page
+func testDigestFeedback() []*models.Feedback {
+	first, general, last := testFeedback(), testGeneralFeedback(), testFeedback()
+	times := []time.Time{
+		time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC),
+		time.Date(2024, 3, 15, 11, 0, 0, 0, time.UTC),
+		time.Date(2024, 3, 15, 11, 30, 0, 0, time.UTC),
+	}
+	first.ID, first.ReceivedAt = "first", &times[0]
+	general.ID, general.ReceivedAt = "general", &times[1]
+	last.ID, last.ReceivedAt = "last", &times[2]
+	last.OnsURL = "testhost:1234/sub/path/"
+	last.Feedback, last.Name, last.EmailAddress = "broken link", "", ""
+	return []*models.Feedback{first, general, last}
+}
+
+func TestDigestMessage(t *testing.T) {
+	Convey("Given the default templates", t, func() {
+		templates, err := email.LoadTemplates(&config.EmailTemplates{}, nil)
+		So(err, ShouldBeNil)
+
+		Convey("The expected digest is generated, with the feedback grouped by page", func() {
+			msg, err := templates.DigestMessage("census", testDigestFeedback(), "sender@mail.com", []string{"census@mail.com"})
+			So(err, ShouldBeNil)
+			So(msg.From, ShouldEqual, "sender@mail.com")
+			So(msg.To, ShouldResemble, []string{"census@mail.com"})
+			So(msg.ReplyTo, ShouldBeNil)
+			So(msg.MessageID, ShouldEndWith, "@mail.com>")
+			So(msg.Subject, ShouldEqual, "Feedback digest - census - 3 submissions")
+			So(msg.Text, ShouldEqual, expectedDigestText)
+			So(msg.HTML, ShouldContainSubstring, `<h3><a href="https://testhost:1234/sub/path">https://testhost:1234/sub/path</a> (2)</h3>`)
+			So(msg.HTML, ShouldContainSubstring, `<h3>General feedback (1)</h3>`)
+			So(msg.HTML, ShouldContainSubstring, `<tr><th align="left">Description</th><td>broken link</td></tr>`)
+		})
+	})
+
+	Convey("Given the default templates with sanitization enabled", t, func() {
+		templates, err := email.LoadTemplates(&config.EmailTemplates{}, &config.Sanitize{HTML: true})
+		So(err, ShouldBeNil)
+
+		Convey("The feedback is sanitized in the text body, and only escaped once in the HTML body, by its template", func() {
+			feedback := testDigestFeedback()
+			feedback[2].Feedback = "<b>broken</b> link"
+			msg, err := templates.DigestMessage("census", feedback, "sender@mail.com", []string{"census@mail.com"})
+			So(err, ShouldBeNil)
+			So(msg.Text, ShouldContainSubstring, "Description: &lt;b&gt;broken&lt;/b&gt; link")
+			So(msg.HTML, ShouldContainSubstring, "<td>&lt;b&gt;broken&lt;/b&gt; link</td>")
+		})
+	})
+
+	Convey("Given custom digest templates", t, func() {
+		dir := t.TempDir()
+		templates, err := email.LoadTemplates(&config.EmailTemplates{
+			DigestSubjectPath: writeTemplate(dir, "subject.tmpl", "{{.Count}} new feedback for {{.Route}}"),
+			DigestTextPath:    writeTemplate(dir, "text.tmpl", "{{range .Pages}}{{.PagePath}}: {{.Count}}\n{{end}}"),
+		}, nil)
+		So(err, ShouldBeNil)
+
+		Convey("The digest is generated from the custom templates", func() {
+			msg, err := templates.DigestMessage("census", testDigestFeedback(), "sender@mail.com", []string{"census@mail.com"})
+			So(err, ShouldBeNil)
+			So(msg.Subject, ShouldEqual, "3 new feedback for census")
+			So(msg.Text, ShouldEqual, "/sub/path: 2\n: 1\n")
+		})
+	})
+
+	Convey("Given a digest template that cannot be parsed", t, func() {
+		_, err := email.LoadTemplates(&config.EmailTemplates{
+			DigestHTMLPath: writeTemplate(t.TempDir(), "html.tmpl", "{{.Count"),
+		}, nil)
+
+		Convey("Loading the templates fails", func() {
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldStartWith, "failed to parse digest html template")
+		})
+	})
+}
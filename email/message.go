@@ -0,0 +1,138 @@
+package email
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// Message represents a feedback email with a plain text body and its HTML alternative
+type Message struct {
+	MessageID string
+	Date      time.Time
+	From      string
+	To        []string
+	ReplyTo   *mail.Address
+	Subject   string
+	Text      string
+	HTML      string
+}
+
+// NewMessageID returns a new unique RFC 5322 message id, in the domain of the provided sender address.
+// The id of the feedback the message is about is included in it, when provided, to help tracing.
+func NewMessageID(feedbackID, from string) string {
+	random := make([]byte, 8)
+	_, _ = rand.Read(random)
+
+	local := hex.EncodeToString(random)
+	if feedbackID != "" {
+		local = feedbackID + "." + local
+	}
+
+	domain := "dp-feedback-api"
+	if addr, err := mail.ParseAddress(from); err == nil {
+		if i := strings.LastIndex(addr.Address, "@"); i >= 0 && i < len(addr.Address)-1 {
+			domain = addr.Address[i+1:]
+		}
+	}
+
+	return fmt.Sprintf("<%s@%s>", local, domain)
+}
+
+// Bytes returns the message formatted as a multipart/alternative MIME message, ready to be sent.
+// Header values are RFC 2047 encoded when they contain non-ASCII characters, and all lines end with CRLF.
+func (m *Message) Bytes() ([]byte, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	// parts are in increasing order of preference, so that clients that can display HTML show it instead of the text
+	if err := writePart(mw, "text/plain; charset=UTF-8", m.Text); err != nil {
+		return nil, fmt.Errorf("failed to write text part: %w", err)
+	}
+	if err := writePart(mw, "text/html; charset=UTF-8", m.HTML); err != nil {
+		return nil, fmt.Errorf("failed to write html part: %w", err)
+	}
+	if err := mw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close multipart message: %w", err)
+	}
+
+	date := m.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+	messageID := m.MessageID
+	if messageID == "" {
+		messageID = NewMessageID("", m.From)
+	}
+
+	to := make([]string, len(m.To))
+	for i, addr := range m.To {
+		to[i] = formatAddress(addr)
+	}
+
+	var b bytes.Buffer
+	writeHeader(&b, "Date", date.Format(time.RFC1123Z))
+	writeHeader(&b, "Message-ID", messageID)
+	writeHeader(&b, "From", formatAddress(m.From))
+	if m.ReplyTo != nil {
+		writeHeader(&b, "Reply-To", addressString(m.ReplyTo))
+	}
+	writeHeader(&b, "To", strings.Join(to, ", "))
+	writeHeader(&b, "Subject", mime.QEncoding.Encode("UTF-8", m.Subject))
+	writeHeader(&b, "MIME-Version", "1.0")
+	writeHeader(&b, "Content-Type", mime.FormatMediaType("multipart/alternative", map[string]string{"boundary": mw.Boundary()}))
+	b.WriteString("\r\n")
+	b.Write(body.Bytes())
+
+	return b.Bytes(), nil
+}
+
+// writeHeader writes a header line, removing any line breaks from its value to prevent header injection
+func writeHeader(b *bytes.Buffer, name, value string) {
+	value = strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+	fmt.Fprintf(b, "%s: %s\r\n", name, value)
+}
+
+// formatAddress returns the provided address formatted for a header, with any display name RFC 2047 encoded.
+// Values that cannot be parsed as an address are returned unchanged.
+func formatAddress(addr string) string {
+	parsed, err := mail.ParseAddress(addr)
+	if err != nil {
+		return addr
+	}
+	return addressString(parsed)
+}
+
+// addressString returns the address formatted for a header, using the bare address when there is no display name
+func addressString(addr *mail.Address) string {
+	if addr.Name == "" {
+		return addr.Address
+	}
+	return addr.String()
+}
+
+// writePart writes a quoted-printable encoded part with the provided content type to the multipart writer
+func writePart(mw *multipart.Writer, contentType, content string) error {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", contentType)
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+
+	pw, err := mw.CreatePart(header)
+	if err != nil {
+		return err
+	}
+
+	qw := quotedprintable.NewWriter(pw)
+	if _, err := qw.Write([]byte(content)); err != nil {
+		return err
+	}
+	return qw.Close()
+}
@@ -0,0 +1,143 @@
+package email_test
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-feedback-api/email"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMessageBytes(t *testing.T) {
+	Convey("Given a message with a text and an HTML body", t, func() {
+		msg := &email.Message{
+			MessageID: "<123.abc@mail.com>",
+			Date:      time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC),
+			From:      "sender@mail.com",
+			To:        []string{"receiver@mail.com", "other@mail.com"},
+			ReplyTo:   &mail.Address{Name: "Mr Reporter", Address: "reporter@mail.com"},
+			Subject:   "Feedback received - A specific page - /sub/path",
+			Text:      "Description: café\n",
+			HTML:      "<p>Description: café</p>",
+		}
+
+		Convey("When it is formatted", func() {
+			b, err := msg.Bytes()
+			So(err, ShouldBeNil)
+
+			Convey("Then the headers are set", func() {
+				m, err := mail.ReadMessage(bytes.NewReader(b))
+				So(err, ShouldBeNil)
+				So(m.Header.Get("From"), ShouldEqual, "sender@mail.com")
+				So(m.Header.Get("To"), ShouldEqual, "receiver@mail.com, other@mail.com")
+				So(m.Header.Get("Subject"), ShouldEqual, "Feedback received - A specific page - /sub/path")
+				So(m.Header.Get("MIME-Version"), ShouldEqual, "1.0")
+				So(m.Header.Get("Date"), ShouldEqual, "Fri, 15 Mar 2024 10:30:00 +0000")
+				So(m.Header.Get("Message-ID"), ShouldEqual, "<123.abc@mail.com>")
+				So(m.Header.Get("Reply-To"), ShouldEqual, `"Mr Reporter" <reporter@mail.com>`)
+			})
+
+			Convey("Then all the header lines end with CRLF", func() {
+				headers := string(b[:bytes.Index(b, []byte("\r\n\r\n"))])
+				for _, line := range strings.Split(headers, "\r\n") {
+					So(line, ShouldNotContainSubstring, "\n")
+				}
+			})
+
+			Convey("Then the body is a multipart/alternative with the text part followed by the HTML part", func() {
+				m, err := mail.ReadMessage(bytes.NewReader(b))
+				So(err, ShouldBeNil)
+				mediaType, params, err := mime.ParseMediaType(m.Header.Get("Content-Type"))
+				So(err, ShouldBeNil)
+				So(mediaType, ShouldEqual, "multipart/alternative")
+
+				mr := multipart.NewReader(m.Body, params["boundary"])
+				text, err := mr.NextPart()
+				So(err, ShouldBeNil)
+				So(text.Header.Get("Content-Type"), ShouldEqual, "text/plain; charset=UTF-8")
+				content, err := io.ReadAll(text)
+				So(err, ShouldBeNil)
+				So(string(content), ShouldEqual, "Description: café\r\n")
+
+				html, err := mr.NextPart()
+				So(err, ShouldBeNil)
+				So(html.Header.Get("Content-Type"), ShouldEqual, "text/html; charset=UTF-8")
+				content, err = io.ReadAll(html)
+				So(err, ShouldBeNil)
+				So(string(content), ShouldEqual, "<p>Description: café</p>")
+
+				_, err = mr.NextPart()
+				So(err, ShouldEqual, io.EOF)
+			})
+		})
+	})
+
+	Convey("Given a message with non-ASCII names and subject", t, func() {
+		msg := &email.Message{
+			From:    "Tîm Adborth <sender@mail.com>",
+			To:      []string{"receiver@mail.com"},
+			ReplyTo: &mail.Address{Name: "Zoë Smith", Address: "zoe@mail.com"},
+			Subject: "Feedback received - A specific page - /économie",
+		}
+
+		Convey("When it is formatted", func() {
+			b, err := msg.Bytes()
+			So(err, ShouldBeNil)
+
+			Convey("Then the headers are RFC 2047 encoded and decode to the original values", func() {
+				So(string(b), ShouldContainSubstring, "Subject: =?UTF-8?q?")
+				So(string(b), ShouldContainSubstring, "Reply-To: =?utf-8?q?Zo=C3=AB_Smith?= <zoe@mail.com>")
+
+				m, err := mail.ReadMessage(bytes.NewReader(b))
+				So(err, ShouldBeNil)
+				dec := new(mime.WordDecoder)
+				subject, err := dec.DecodeHeader(m.Header.Get("Subject"))
+				So(err, ShouldBeNil)
+				So(subject, ShouldEqual, "Feedback received - A specific page - /économie")
+				from, err := m.Header.AddressList("From")
+				So(err, ShouldBeNil)
+				So(from[0].Name, ShouldEqual, "Tîm Adborth")
+			})
+
+			Convey("Then a date and a message id are generated", func() {
+				m, err := mail.ReadMessage(bytes.NewReader(b))
+				So(err, ShouldBeNil)
+				_, err = m.Header.Date()
+				So(err, ShouldBeNil)
+				So(m.Header.Get("Message-ID"), ShouldEndWith, "@mail.com>")
+			})
+		})
+	})
+
+	Convey("Given a subject containing line breaks", t, func() {
+		msg := &email.Message{From: "sender@mail.com", To: []string{"receiver@mail.com"}, Subject: "Feedback\r\nBcc: someone@mail.com"}
+
+		Convey("Then no header can be injected", func() {
+			b, err := msg.Bytes()
+			So(err, ShouldBeNil)
+			m, err := mail.ReadMessage(bytes.NewReader(b))
+			So(err, ShouldBeNil)
+			So(m.Header.Get("Bcc"), ShouldBeEmpty)
+		})
+	})
+}
+
+func TestNewMessageID(t *testing.T) {
+	Convey("A message id contains the feedback id and the domain of the sender", t, func() {
+		id := email.NewMessageID("feedback-1", "sender@mail.com")
+		So(id, ShouldStartWith, "<feedback-1.")
+		So(id, ShouldEndWith, "@mail.com>")
+		So(email.NewMessageID("feedback-1", "sender@mail.com"), ShouldNotEqual, id)
+	})
+
+	Convey("A message id is generated for a sender that is not a valid address", t, func() {
+		id := email.NewMessageID("", "not an address")
+		So(id, ShouldEndWith, "@dp-feedback-api>")
+	})
+}
@@ -0,0 +1,173 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"context"
+	"github.com/ONSdigital/dp-feedback-api/email"
+	"github.com/ONSdigital/dp-feedback-api/models"
+	"sync"
+)
+
+// Ensure, that QueueMock does implement email.Queue.
+// If this is not the case, regenerate this file with moq.
+var _ email.Queue = &QueueMock{}
+
+// QueueMock is a mock implementation of email.Queue.
+//
+//	func TestSomethingThatUsesQueue(t *testing.T) {
+//
+//		// make and configure a mocked email.Queue
+//		mockedQueue := &QueueMock{
+//			EnqueueFunc: func(ctx context.Context, from string, to []string, msg []byte) error {
+//				panic("mock out the Enqueue method")
+//			},
+//		}
+//
+//		// use mockedQueue in code that requires email.Queue
+//		// and then make assertions.
+//
+//	}
+type QueueMock struct {
+	// EnqueueFunc mocks the Enqueue method.
+	EnqueueFunc func(ctx context.Context, from string, to []string, msg []byte) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Enqueue holds details about calls to the Enqueue method.
+		Enqueue []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// From is the from argument value.
+			From string
+			// To is the to argument value.
+			To []string
+			// Msg is the msg argument value.
+			Msg []byte
+		}
+	}
+	lockEnqueue sync.RWMutex
+}
+
+// Enqueue calls EnqueueFunc.
+func (mock *QueueMock) Enqueue(ctx context.Context, from string, to []string, msg []byte) error {
+	if mock.EnqueueFunc == nil {
+		panic("QueueMock.EnqueueFunc: method is nil but Queue.Enqueue was just called")
+	}
+	callInfo := struct {
+		Ctx  context.Context
+		From string
+		To   []string
+		Msg  []byte
+	}{
+		Ctx:  ctx,
+		From: from,
+		To:   to,
+		Msg:  msg,
+	}
+	mock.lockEnqueue.Lock()
+	mock.calls.Enqueue = append(mock.calls.Enqueue, callInfo)
+	mock.lockEnqueue.Unlock()
+	return mock.EnqueueFunc(ctx, from, to, msg)
+}
+
+// EnqueueCalls gets all the calls that were made to Enqueue.
+// Check the length with:
+//
+//	len(mockedQueue.EnqueueCalls())
+func (mock *QueueMock) EnqueueCalls() []struct {
+	Ctx  context.Context
+	From string
+	To   []string
+	Msg  []byte
+} {
+	var calls []struct {
+		Ctx  context.Context
+		From string
+		To   []string
+		Msg  []byte
+	}
+	mock.lockEnqueue.RLock()
+	calls = mock.calls.Enqueue
+	mock.lockEnqueue.RUnlock()
+	return calls
+}
+
+// Ensure, that DigestQueueMock does implement email.DigestQueue.
+// If this is not the case, regenerate this file with moq.
+var _ email.DigestQueue = &DigestQueueMock{}
+
+// DigestQueueMock is a mock implementation of email.DigestQueue.
+//
+//	func TestSomethingThatUsesDigestQueue(t *testing.T) {
+//
+//		// make and configure a mocked email.DigestQueue
+//		mockedDigestQueue := &DigestQueueMock{
+//			AddFunc: func(ctx context.Context, route string, f *models.Feedback) error {
+//				panic("mock out the Add method")
+//			},
+//		}
+//
+//		// use mockedDigestQueue in code that requires email.DigestQueue
+//		// and then make assertions.
+//
+//	}
+type DigestQueueMock struct {
+	// AddFunc mocks the Add method.
+	AddFunc func(ctx context.Context, route string, f *models.Feedback) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Add holds details about calls to the Add method.
+		Add []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Route is the route argument value.
+			Route string
+			// F is the f argument value.
+			F *models.Feedback
+		}
+	}
+	lockAdd sync.RWMutex
+}
+
+// Add calls AddFunc.
+func (mock *DigestQueueMock) Add(ctx context.Context, route string, f *models.Feedback) error {
+	if mock.AddFunc == nil {
+		panic("DigestQueueMock.AddFunc: method is nil but DigestQueue.Add was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Route string
+		F     *models.Feedback
+	}{
+		Ctx:   ctx,
+		Route: route,
+		F:     f,
+	}
+	mock.lockAdd.Lock()
+	mock.calls.Add = append(mock.calls.Add, callInfo)
+	mock.lockAdd.Unlock()
+	return mock.AddFunc(ctx, route, f)
+}
+
+// AddCalls gets all the calls that were made to Add.
+// Check the length with:
+//
+//	len(mockedDigestQueue.AddCalls())
+func (mock *DigestQueueMock) AddCalls() []struct {
+	Ctx   context.Context
+	Route string
+	F     *models.Feedback
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Route string
+		F     *models.Feedback
+	}
+	mock.lockAdd.RLock()
+	calls = mock.calls.Add
+	mock.lockAdd.RUnlock()
+	return calls
+}
@@ -0,0 +1,65 @@
+package email
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ONSdigital/dp-feedback-api/models"
+	"github.com/ONSdigital/dp-feedback-api/routing"
+)
+
+//go:generate moq -out mock/queue.go -pkg mock . Queue DigestQueue
+
+// Queue defines the required methods to queue emails, which are then delivered in the background
+type Queue interface {
+	Enqueue(ctx context.Context, from string, to []string, msg []byte) error
+}
+
+// DigestQueue defines the required methods to add feedback to the digest emails of a route, which are sent periodically
+type DigestQueue interface {
+	Add(ctx context.Context, route string, f *models.Feedback) error
+}
+
+// Notifier notifies of new feedback by email.
+// The email is queued for the recipients given by the routing table, or the feedback is added to the digests of the routes configured with one.
+type Notifier struct {
+	Templates *Templates
+	Routes    *routing.Table
+	Queue     Queue
+	Digests   DigestQueue
+	From      string
+}
+
+// Notify queues the email for the provided feedback, and adds it to the digests of the routes it matches
+func (n *Notifier) Notify(ctx context.Context, f *models.Feedback) error {
+	if to := n.Routes.Recipients(f); len(to) > 0 {
+		if err := n.queueFeedbackEmail(ctx, f, to); err != nil {
+			return err
+		}
+	}
+
+	// the feedback of routes with a digest is sent later, in a single email with the rest of the feedback of the route
+	for _, route := range n.Routes.Digests(f) {
+		if err := n.Digests.Add(ctx, route.Name, f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// queueFeedbackEmail generates the email for the provided feedback from the templates, and queues it for the provided recipients.
+// The email is delivered in the background, so that the feedback is not lost if the mail server is unavailable.
+func (n *Notifier) queueFeedbackEmail(ctx context.Context, f *models.Feedback, to []string) error {
+	msg, err := n.Templates.FeedbackMessage(f, n.From, to)
+	if err != nil {
+		return fmt.Errorf("failed to generate message: %w", err)
+	}
+	b, err := msg.Bytes()
+	if err != nil {
+		return fmt.Errorf("failed to generate message: %w", err)
+	}
+	if err := n.Queue.Enqueue(ctx, msg.From, msg.To, b); err != nil {
+		return fmt.Errorf("failed to queue message: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package email_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ONSdigital/dp-feedback-api/config"
+	"github.com/ONSdigital/dp-feedback-api/email"
+	"github.com/ONSdigital/dp-feedback-api/email/mock"
+	"github.com/ONSdigital/dp-feedback-api/models"
+	"github.com/ONSdigital/dp-feedback-api/routing"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNotify(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given an email notifier with a routing table", t, func() {
+		templates, err := email.LoadTemplates(&config.EmailTemplates{}, nil)
+		So(err, ShouldBeNil)
+		routesFile := filepath.Join(t.TempDir(), "routes.json")
+		So(os.WriteFile(routesFile, []byte(`{
+			"urgent_keywords": ["urgent"],
+			"routes": [
+				{"name": "census", "path_prefixes": ["/census"], "to": ["census@mail.com"]},
+				{"name": "releases", "path_prefixes": ["/releases"], "digest": "hourly", "to": ["releases@mail.com"]}
+			]
+		}`), 0o600), ShouldBeNil)
+		routes, err := routing.Load(routesFile, "receiver@mail.com")
+		So(err, ShouldBeNil)
+		queueMock := &mock.QueueMock{
+			EnqueueFunc: func(ctx context.Context, from string, to []string, msg []byte) error { return nil },
+		}
+		digestMock := &mock.DigestQueueMock{
+			AddFunc: func(ctx context.Context, route string, f *models.Feedback) error { return nil },
+		}
+		n := &email.Notifier{Templates: templates, Routes: routes, Queue: queueMock, Digests: digestMock, From: "sender@mail.com"}
+
+		feedback := func(url, description string) *models.Feedback {
+			f := testFeedback()
+			f.ID = "test-id"
+			f.OnsURL = url
+			f.Feedback = description
+			return f
+		}
+
+		Convey("When it is notified of feedback that does not match any route", func() {
+			So(n.Notify(ctx, feedback("https://testhost/sub/path", "broken link")), ShouldBeNil)
+
+			Convey("Then the email generated from the templates is queued for the default recipient", func() {
+				So(queueMock.EnqueueCalls(), ShouldHaveLength, 1)
+				call := queueMock.EnqueueCalls()[0]
+				So(call.From, ShouldEqual, "sender@mail.com")
+				So(call.To, ShouldResemble, []string{"receiver@mail.com"})
+				So(string(call.Msg), ShouldContainSubstring, "Subject: Feedback received - A specific page - /sub/path\r\n")
+				So(string(call.Msg), ShouldContainSubstring, "Content-Type: multipart/alternative;")
+				So(digestMock.AddCalls(), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When it is notified of feedback that matches a route", func() {
+			So(n.Notify(ctx, feedback("https://testhost/census/data", "broken link")), ShouldBeNil)
+
+			Convey("Then the email is queued for the recipients of the route", func() {
+				So(queueMock.EnqueueCalls(), ShouldHaveLength, 1)
+				So(queueMock.EnqueueCalls()[0].To, ShouldResemble, []string{"census@mail.com"})
+				So(string(queueMock.EnqueueCalls()[0].Msg), ShouldContainSubstring, "To: census@mail.com\r\n")
+			})
+		})
+
+		Convey("When it is notified of feedback that matches a route with a digest", func() {
+			So(n.Notify(ctx, feedback("https://testhost/releases/calendar", "wrong date")), ShouldBeNil)
+
+			Convey("Then the feedback is added to the digest of the route, and no email is queued", func() {
+				So(queueMock.EnqueueCalls(), ShouldBeEmpty)
+				So(digestMock.AddCalls(), ShouldHaveLength, 1)
+				So(digestMock.AddCalls()[0].Route, ShouldEqual, "releases")
+				So(digestMock.AddCalls()[0].F.ID, ShouldEqual, "test-id")
+			})
+		})
+
+		Convey("When it is notified of urgent feedback that matches a route with a digest", func() {
+			So(n.Notify(ctx, feedback("https://testhost/releases/calendar", "urgent: wrong date")), ShouldBeNil)
+
+			Convey("Then the email is queued straight away for the recipients of the route", func() {
+				So(digestMock.AddCalls(), ShouldBeEmpty)
+				So(queueMock.EnqueueCalls(), ShouldHaveLength, 1)
+				So(queueMock.EnqueueCalls()[0].To, ShouldResemble, []string{"releases@mail.com"})
+			})
+		})
+
+		Convey("When adding the feedback to a digest fails", func() {
+			digestMock.AddFunc = func(ctx context.Context, route string, f *models.Feedback) error {
+				return errors.New("store error")
+			}
+
+			Convey("Then notifying fails", func() {
+				So(n.Notify(ctx, feedback("https://testhost/releases", "wrong date")), ShouldNotBeNil)
+			})
+		})
+
+		Convey("When queueing the email fails", func() {
+			queueMock.EnqueueFunc = func(ctx context.Context, from string, to []string, msg []byte) error {
+				return errors.New("store error")
+			}
+
+			Convey("Then notifying fails", func() {
+				err := n.Notify(ctx, feedback("https://testhost/sub/path", "broken link"))
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldStartWith, "failed to queue message")
+			})
+		})
+	})
+}
@@ -0,0 +1,175 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"sync"
+	"time"
+)
+
+// quitTimeout is the maximum duration of closing an idle connection with QUIT
+const quitTimeout = 5 * time.Second
+
+// ErrSenderClosed is returned when an email is sent after the sender has been closed
+var ErrSenderClosed = errors.New("email sender is closed")
+
+// PooledSender sends emails through a bounded pool of connections to the SMTP server, which are kept open and reused
+// between emails, so that connecting, negotiating TLS and authenticating is not repeated for every email.
+// Connections are reset with RSET before being reused, and replaced with a new connection if the reset fails.
+type PooledSender struct {
+	*SMTPSender
+	IdleTimeout time.Duration
+
+	slots  chan struct{}
+	mu     sync.Mutex
+	idle   []*pooledConn
+	closed bool
+}
+
+// pooledConn is a connection to the SMTP server that is ready to send emails
+type pooledConn struct {
+	client    *smtp.Client
+	conn      net.Conn
+	idleSince time.Time
+}
+
+// NewPooledSender returns a PooledSender that sends emails with the provided sender, through at most size connections.
+// Connections that have been idle for longer than idleTimeout are closed instead of being reused.
+func NewPooledSender(sender *SMTPSender, size int, idleTimeout time.Duration) *PooledSender {
+	if size < 1 {
+		size = 1
+	}
+	return &PooledSender{
+		SMTPSender:  sender,
+		IdleTimeout: idleTimeout,
+		slots:       make(chan struct{}, size),
+	}
+}
+
+// Send sends an email through a pooled connection to the SMTP server, waiting for a connection to be available if all of them are in use.
+// The connection is returned to the pool once the email is sent, or rejected by the server, and closed on any other failure.
+func (p *PooledSender) Send(from string, to []string, msg []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return fmt.Errorf("no SMTP connection available: %w", ctx.Err())
+	}
+	defer func() { <-p.slots }()
+
+	pc, err := p.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = sendMessage(pc.client, from, to, msg)
+	var protoErr *textproto.Error
+	if err == nil || errors.As(err, &protoErr) {
+		p.put(pc)
+	} else {
+		_ = pc.client.Close()
+	}
+	return err
+}
+
+// Close closes all the idle connections with QUIT. Connections in use are closed as soon as their email is sent,
+// and any email sent after closing fails with ErrSenderClosed.
+func (p *PooledSender) Close(ctx context.Context) error {
+	p.mu.Lock()
+	p.closed = true
+	idle := p.idle
+	p.idle = nil
+	p.mu.Unlock()
+
+	for _, pc := range idle {
+		quit(ctx, pc)
+	}
+	return nil
+}
+
+// get returns an idle connection that has been successfully reset, or a new connection if there is none
+func (p *PooledSender) get(ctx context.Context) (*pooledConn, error) {
+	for {
+		pc, err := p.popIdle(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if pc == nil {
+			break
+		}
+		if deadline, ok := ctx.Deadline(); ok {
+			_ = pc.conn.SetDeadline(deadline)
+		}
+		if err := pc.client.Reset(); err == nil {
+			return pc, nil
+		}
+		// the server has most likely closed the connection, so try the next one
+		_ = pc.client.Close()
+	}
+
+	c, conn, err := p.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &pooledConn{client: c, conn: conn}, nil
+}
+
+// popIdle returns the most recently used idle connection, if any, closing the connections that have expired
+func (p *PooledSender) popIdle(ctx context.Context) (*pooledConn, error) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, ErrSenderClosed
+	}
+
+	var pc *pooledConn
+	var expired []*pooledConn
+	for len(p.idle) > 0 && pc == nil {
+		last := p.idle[len(p.idle)-1]
+		p.idle = p.idle[:len(p.idle)-1]
+		if p.IdleTimeout > 0 && time.Since(last.idleSince) > p.IdleTimeout {
+			expired = append(expired, last)
+			continue
+		}
+		pc = last
+	}
+	p.mu.Unlock()
+
+	for _, e := range expired {
+		quit(ctx, e)
+	}
+	return pc, nil
+}
+
+// put returns a connection to the pool, or closes it if the sender has been closed
+func (p *PooledSender) put(pc *pooledConn) {
+	_ = pc.conn.SetDeadline(time.Time{})
+	pc.idleSince = time.Now()
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		quit(context.Background(), pc)
+		return
+	}
+	p.idle = append(p.idle, pc)
+	p.mu.Unlock()
+}
+
+// quit closes a connection with QUIT, or forcibly if the server does not reply in time
+func quit(ctx context.Context, pc *pooledConn) {
+	deadline := time.Now().Add(quitTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = pc.conn.SetDeadline(deadline)
+	if err := pc.client.Quit(); err != nil {
+		_ = pc.client.Close()
+	}
+}
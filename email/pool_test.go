@@ -0,0 +1,121 @@
+package email_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-feedback-api/email"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPooledSender(t *testing.T) {
+	Convey("Given a pooled sender for a server that supports AUTH", t, func() {
+		srv := newFakeSMTPServer(t, fakeSMTPConfig{Extensions: []string{"AUTH PLAIN CRAM-MD5"}})
+		sender := email.NewPooledSender(newTestSender(testMailConfig(srv.Port())), 2, time.Minute)
+
+		Convey("When two emails are sent one after the other", func() {
+			So(sender.Send("sender@mail.com", []string{"receiver@mail.com"}, testMsg), ShouldBeNil)
+			So(sender.Send("sender@mail.com", []string{"receiver@mail.com"}, testMsg), ShouldBeNil)
+
+			Convey("Then both are delivered through the same connection, which is reset with RSET between emails", func() {
+				So(srv.Messages(), ShouldHaveLength, 2)
+				So(srv.Connections(), ShouldEqual, 1)
+				So(srv.Commands(), ShouldResemble, []string{
+					"EHLO", "AUTH", "MAIL", "RCPT", "DATA",
+					"RSET", "MAIL", "RCPT", "DATA",
+				})
+			})
+
+			Convey("Then closing the sender closes the idle connection with QUIT", func() {
+				So(sender.Close(ctx), ShouldBeNil)
+				So(srv.Commands()[len(srv.Commands())-1], ShouldEqual, "QUIT")
+
+				Convey("And any further email fails without connecting to the server", func() {
+					err := sender.Send("sender@mail.com", []string{"receiver@mail.com"}, testMsg)
+					So(err, ShouldEqual, email.ErrSenderClosed)
+					So(srv.Connections(), ShouldEqual, 1)
+				})
+			})
+		})
+
+		Convey("When the server drops the idle connection between two emails", func() {
+			So(sender.Send("sender@mail.com", []string{"receiver@mail.com"}, testMsg), ShouldBeNil)
+			srv.DropConnections()
+			err := sender.Send("sender@mail.com", []string{"receiver@mail.com"}, testMsg)
+
+			Convey("Then the sender reconnects and the second email is delivered", func() {
+				So(err, ShouldBeNil)
+				So(srv.Messages(), ShouldHaveLength, 2)
+				So(srv.Connections(), ShouldEqual, 2)
+			})
+		})
+
+		Convey("When many emails are sent concurrently", func() {
+			var wg sync.WaitGroup
+			errs := make(chan error, 10)
+			for i := 0; i < 10; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					errs <- sender.Send("sender@mail.com", []string{"receiver@mail.com"}, testMsg)
+				}()
+			}
+			wg.Wait()
+			close(errs)
+
+			Convey("Then all of them are delivered through at most the configured number of connections", func() {
+				for err := range errs {
+					So(err, ShouldBeNil)
+				}
+				So(srv.Messages(), ShouldHaveLength, 10)
+				So(srv.Connections(), ShouldBeBetweenOrEqual, 1, 2)
+			})
+		})
+
+		Reset(func() {
+			_ = sender.Close(context.Background())
+		})
+	})
+
+	Convey("Given a pooled sender with a short idle timeout", t, func() {
+		srv := newFakeSMTPServer(t, fakeSMTPConfig{Extensions: []string{"AUTH PLAIN CRAM-MD5"}})
+		sender := email.NewPooledSender(newTestSender(testMailConfig(srv.Port())), 1, time.Millisecond)
+		defer sender.Close(ctx)
+
+		Convey("When an email is sent after the connection has been idle for longer than the timeout", func() {
+			So(sender.Send("sender@mail.com", []string{"receiver@mail.com"}, testMsg), ShouldBeNil)
+			time.Sleep(10 * time.Millisecond)
+			So(sender.Send("sender@mail.com", []string{"receiver@mail.com"}, testMsg), ShouldBeNil)
+
+			Convey("Then the idle connection is closed with QUIT and a new connection is used", func() {
+				So(srv.Connections(), ShouldEqual, 2)
+				So(srv.Commands(), ShouldContain, "QUIT")
+				So(srv.Messages(), ShouldHaveLength, 2)
+			})
+		})
+	})
+
+	Convey("Given a pooled sender for a server that rejects the recipient", t, func() {
+		srv := newFakeSMTPServer(t, fakeSMTPConfig{
+			Extensions: []string{"AUTH PLAIN CRAM-MD5"},
+			RcptReply:  "550 mailbox unavailable",
+		})
+		sender := email.NewPooledSender(newTestSender(testMailConfig(srv.Port())), 1, time.Minute)
+		defer sender.Close(ctx)
+
+		Convey("When two emails are sent", func() {
+			err1 := sender.Send("sender@mail.com", []string{"unknown@mail.com"}, testMsg)
+			err2 := sender.Send("sender@mail.com", []string{"unknown@mail.com"}, testMsg)
+
+			Convey("Then both fail with the server reply, and the connection is kept for the next email", func() {
+				So(err1, ShouldNotBeNil)
+				So(err1.Error(), ShouldContainSubstring, "SMTP RCPT failed: 550")
+				So(err2, ShouldNotBeNil)
+				So(srv.Connections(), ShouldEqual, 1)
+				So(srv.Commands(), ShouldResemble, []string{"EHLO", "AUTH", "MAIL", "RCPT", "RSET", "MAIL", "RCPT"})
+			})
+		})
+	})
+}
@@ -1,43 +1,209 @@
 package email
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
+	"net"
 	"net/smtp"
+	"os"
+	"time"
 
 	"github.com/ONSdigital/dp-feedback-api/config"
 )
 
+// sendTimeout is the maximum duration of sending a single email, including connecting to the server
+const sendTimeout = time.Minute
+
+// SMTPSender sends emails through the configured SMTP server
 type SMTPSender struct {
-	Addr string
-	Auth smtp.Auth
+	Host        string
+	Addr        string
+	Credentials *Credentials
+	TLSMode     string
+	TLSConfig   *tls.Config
+}
+
+// connectError is returned when the SMTP server cannot be reached, or does not greet the client
+type connectError struct {
+	err error
+}
+
+func (e *connectError) Error() string {
+	return fmt.Sprintf("failed to connect to SMTP server: %s", e.err)
+}
+
+func (e *connectError) Unwrap() error {
+	return e.err
+}
+
+// Send sends an email through a new connection to the SMTP server, which uses TLS according to the configured mode
+func (s *SMTPSender) Send(from string, to []string, msg []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+
+	c, err := s.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if err := sendMessage(c, from, to, msg); err != nil {
+		return err
+	}
+	return c.Quit()
 }
 
-func (s SMTPSender) Send(from string, to []string, msg []byte) error {
-	return smtp.SendMail(
-		s.Addr,
-		s.Auth,
-		from,
-		to,
-		msg)
+// Close is a no-op, as SMTPSender does not keep any connection open between emails
+func (s *SMTPSender) Close(ctx context.Context) error {
+	return nil
+}
+
+// sendMessage sends a single email through a client that is ready to send emails
+func sendMessage(c *smtp.Client, from string, to []string, msg []byte) error {
+	if err := c.Mail(from); err != nil {
+		return fmt.Errorf("SMTP MAIL failed: %w", err)
+	}
+	for _, addr := range to {
+		if err := c.Rcpt(addr); err != nil {
+			return fmt.Errorf("SMTP RCPT failed: %w", err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP DATA failed: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("SMTP DATA failed: %w", err)
+	}
+	return nil
+}
+
+// dial connects to the SMTP server and returns a client that is ready to send emails, once it has
+// greeted the server, negotiated TLS according to the configured mode and authenticated.
+// The connection fails closed: no email is sent if the required TLS cannot be negotiated.
+func (s *SMTPSender) dial(ctx context.Context) (*smtp.Client, error) {
+	c, _, err := s.connect(ctx)
+	return c, err
+}
+
+// connect is like dial, but also returns the underlying connection, so that its deadline can be extended by the caller.
+// The deadline of the connection is set to the deadline of the context, if any.
+func (s *SMTPSender) connect(ctx context.Context) (*smtp.Client, net.Conn, error) {
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", s.Addr)
+	if err != nil {
+		return nil, nil, &connectError{err}
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if s.TLSMode == config.TLSModeImplicitTLS {
+		tlsConn := tls.Client(conn, s.TLSConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+			return nil, nil, fmt.Errorf("TLS handshake failed: %w", err)
+		}
+		conn = tlsConn
+	}
+
+	c, err := smtp.NewClient(conn, s.Host)
+	if err != nil {
+		_ = conn.Close()
+		return nil, nil, &connectError{err}
+	}
+
+	if err := s.handshake(c); err != nil {
+		_ = c.Close()
+		return nil, nil, err
+	}
+	return c, conn, nil
+}
+
+// handshake greets the server, upgrades the connection with STARTTLS according to the configured mode and authenticates
+func (s *SMTPSender) handshake(c *smtp.Client) error {
+	if err := c.Hello("localhost"); err != nil {
+		return fmt.Errorf("SMTP EHLO failed: %w", err)
+	}
+
+	if s.TLSMode == config.TLSModeStartTLS || s.TLSMode == config.TLSModeStartTLSRequired {
+		ok, _ := c.Extension("STARTTLS")
+		switch {
+		case ok:
+			if err := c.StartTLS(s.TLSConfig); err != nil {
+				return fmt.Errorf("SMTP STARTTLS failed: %w", err)
+			}
+		case s.TLSMode == config.TLSModeStartTLSRequired:
+			return errors.New("SMTP STARTTLS failed: server doesn't support STARTTLS")
+		}
+	}
+
+	if s.Credentials != nil {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return errors.New("SMTP AUTH failed: server doesn't support AUTH")
+		}
+		if err := c.Auth(s.Credentials.Auth()); err != nil {
+			return fmt.Errorf("SMTP AUTH failed: %w", err)
+		}
+	}
+	return nil
 }
 
 // NewSMTPSender returns a new SMTPSender according to the provided mail configuration
-func NewSMTPSender(cfg *config.Mail) *SMTPSender {
-	var auth smtp.Auth
-	if cfg.Encrypted {
-		auth = smtp.PlainAuth(
-			"",
-			cfg.User,
-			cfg.Password,
-			cfg.Host,
-		)
-	} else {
-		auth = smtp.CRAMMD5Auth(cfg.User, cfg.Password)
+func NewSMTPSender(cfg *config.Mail) (*SMTPSender, error) {
+	switch cfg.TLSMode {
+	case config.TLSModeNone, config.TLSModeStartTLS, config.TLSModeStartTLSRequired, config.TLSModeImplicitTLS:
+	default:
+		return nil, fmt.Errorf("invalid mail tls mode '%s'", cfg.TLSMode)
+	}
+
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials, err := NewCredentials(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mail credentials: %w", err)
 	}
 	mailAddr := fmt.Sprintf("%s:%s", cfg.Host, cfg.Port)
 
 	return &SMTPSender{
-		Addr: mailAddr,
-		Auth: auth,
+		Host:        cfg.Host,
+		Addr:        mailAddr,
+		Credentials: credentials,
+		TLSMode:     cfg.TLSMode,
+		TLSConfig:   tlsConfig,
+	}, nil
+}
+
+// newTLSConfig returns the TLS configuration used to connect to the mail server. The server certificate is verified
+// against the configured CA bundle, or the system roots if none is configured, for the configured server name or host.
+func newTLSConfig(cfg *config.Mail) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName: cfg.Host,
+		MinVersion: tls.VersionTLS12,
+	}
+	if cfg.TLSServerName != "" {
+		tlsConfig.ServerName = cfg.TLSServerName
 	}
+
+	if cfg.TLSCAFile != "" {
+		pem, err := os.ReadFile(cfg.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read mail tls ca file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in mail tls ca file '%s'", cfg.TLSCAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
 }
@@ -0,0 +1,165 @@
+package email_test
+
+import (
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ONSdigital/dp-feedback-api/config"
+	"github.com/ONSdigital/dp-feedback-api/email"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var testMsg = []byte("Subject: test\r\n\r\nbody\r\n")
+
+// newTLSServer returns a fake SMTP server using a self-signed certificate, and the path of a CA file that trusts it
+func newTLSServer(t *testing.T, implicitTLS bool) (srv *fakeSMTPServer, caFile string) {
+	cert, certPEM := newTestCertificate(t)
+	caFile = filepath.Join(t.TempDir(), "ca.pem")
+	So(os.WriteFile(caFile, certPEM, 0o600), ShouldBeNil)
+
+	cfg := fakeSMTPConfig{
+		ImplicitTLS: implicitTLS,
+		TLSConfig:   &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12},
+		Extensions:  []string{"AUTH PLAIN"},
+	}
+	if !implicitTLS {
+		cfg.Extensions = append(cfg.Extensions, "STARTTLS")
+	}
+	return newFakeSMTPServer(t, cfg), caFile
+}
+
+func TestSMTPSender(t *testing.T) {
+	Convey("Given an SMTP sender for a server that supports AUTH", t, func() {
+		srv := newFakeSMTPServer(t, fakeSMTPConfig{Extensions: []string{"AUTH PLAIN CRAM-MD5"}})
+		sender := newTestSender(testMailConfig(srv.Port()))
+
+		Convey("When an email is sent", func() {
+			err := sender.Send("sender@mail.com", []string{"receiver@mail.com"}, testMsg)
+
+			Convey("Then it is delivered to the server", func() {
+				So(err, ShouldBeNil)
+				So(srv.Messages(), ShouldResemble, []string{string(testMsg)})
+				So(srv.Commands(), ShouldResemble, []string{"EHLO", "AUTH", "MAIL", "RCPT", "DATA", "QUIT"})
+			})
+		})
+	})
+}
+
+func TestSMTPSenderTLSModes(t *testing.T) {
+	Convey("Given a server that supports STARTTLS with a certificate signed by the configured CA", t, func() {
+		srv, caFile := newTLSServer(t, false)
+		cfg := testMailConfig(srv.Port())
+		cfg.TLSCAFile = caFile
+
+		Convey("Then an email is sent over TLS in starttls-required mode", func() {
+			cfg.TLSMode = config.TLSModeStartTLSRequired
+			So(newTestSender(cfg).Send("sender@mail.com", []string{"receiver@mail.com"}, testMsg), ShouldBeNil)
+			So(srv.Messages(), ShouldHaveLength, 1)
+			So(srv.Commands(), ShouldResemble, []string{"EHLO", "STARTTLS", "EHLO", "AUTH", "MAIL", "RCPT", "DATA", "QUIT"})
+		})
+
+		Convey("Then an email is sent over TLS in starttls mode", func() {
+			cfg.TLSMode = config.TLSModeStartTLS
+			So(newTestSender(cfg).Send("sender@mail.com", []string{"receiver@mail.com"}, testMsg), ShouldBeNil)
+			So(srv.Commands(), ShouldContain, "STARTTLS")
+		})
+
+		Convey("Then STARTTLS is not used in none mode", func() {
+			cfg.TLSMode = config.TLSModeNone
+			So(newTestSender(cfg).Send("sender@mail.com", []string{"receiver@mail.com"}, testMsg), ShouldBeNil)
+			So(srv.Commands(), ShouldNotContain, "STARTTLS")
+		})
+
+		Convey("Then the configured server name is used to verify the certificate", func() {
+			cfg.TLSMode = config.TLSModeStartTLSRequired
+			cfg.TLSServerName = "localhost"
+			So(newTestSender(cfg).Send("sender@mail.com", []string{"receiver@mail.com"}, testMsg), ShouldBeNil)
+
+			cfg.TLSServerName = "mail.example.com"
+			So(newTestSender(cfg).Send("sender@mail.com", []string{"receiver@mail.com"}, testMsg), ShouldNotBeNil)
+			So(srv.Messages(), ShouldHaveLength, 1)
+		})
+
+		Convey("Then sending fails, without sending any email, if the certificate is not trusted", func() {
+			cfg.TLSMode = config.TLSModeStartTLSRequired
+			cfg.TLSCAFile = ""
+			err := newTestSender(cfg).Send("sender@mail.com", []string{"receiver@mail.com"}, testMsg)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "SMTP STARTTLS failed")
+			So(srv.Messages(), ShouldBeEmpty)
+		})
+	})
+
+	Convey("Given a server that does not support STARTTLS", t, func() {
+		srv := newFakeSMTPServer(t, fakeSMTPConfig{Extensions: []string{"AUTH PLAIN"}})
+		cfg := testMailConfig(srv.Port())
+
+		Convey("Then sending fails closed in starttls-required mode", func() {
+			cfg.TLSMode = config.TLSModeStartTLSRequired
+			err := newTestSender(cfg).Send("sender@mail.com", []string{"receiver@mail.com"}, testMsg)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "server doesn't support STARTTLS")
+			So(srv.Commands(), ShouldResemble, []string{"EHLO"})
+			So(srv.Messages(), ShouldBeEmpty)
+		})
+
+		Convey("Then the email is sent without TLS in starttls mode", func() {
+			cfg.TLSMode = config.TLSModeStartTLS
+			So(newTestSender(cfg).Send("sender@mail.com", []string{"receiver@mail.com"}, testMsg), ShouldBeNil)
+			So(srv.Messages(), ShouldHaveLength, 1)
+		})
+
+		Convey("Then sending fails in implicit-tls mode", func() {
+			cfg.TLSMode = config.TLSModeImplicitTLS
+			err := newTestSender(cfg).Send("sender@mail.com", []string{"receiver@mail.com"}, testMsg)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "TLS handshake failed")
+			So(srv.Messages(), ShouldBeEmpty)
+		})
+	})
+
+	Convey("Given a server that uses implicit TLS with a certificate signed by the configured CA", t, func() {
+		srv, caFile := newTLSServer(t, true)
+		cfg := testMailConfig(srv.Port())
+		cfg.TLSCAFile = caFile
+		cfg.TLSMode = config.TLSModeImplicitTLS
+
+		Convey("Then an email is sent in implicit-tls mode", func() {
+			So(newTestSender(cfg).Send("sender@mail.com", []string{"receiver@mail.com"}, testMsg), ShouldBeNil)
+			So(srv.Messages(), ShouldResemble, []string{string(testMsg)})
+			So(srv.Commands(), ShouldResemble, []string{"EHLO", "AUTH", "MAIL", "RCPT", "DATA", "QUIT"})
+		})
+
+		Convey("Then the checker reports the server as healthy", func() {
+			state := newCheckState()
+			So(newTestSender(cfg).Checker(ctx, state), ShouldBeNil)
+			So(state.Status(), ShouldEqual, "OK")
+		})
+	})
+}
+
+func TestNewSMTPSender(t *testing.T) {
+	Convey("Creating a sender with an invalid tls mode fails", t, func() {
+		cfg := testMailConfig("25")
+		cfg.TLSMode = "always"
+		_, err := email.NewSMTPSender(cfg)
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Creating a sender with a CA file that does not exist fails", t, func() {
+		cfg := testMailConfig("25")
+		cfg.TLSCAFile = filepath.Join(t.TempDir(), "missing.pem")
+		_, err := email.NewSMTPSender(cfg)
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Creating a sender with a CA file that contains no certificates fails", t, func() {
+		cfg := testMailConfig("25")
+		cfg.TLSCAFile = filepath.Join(t.TempDir(), "ca.pem")
+		So(os.WriteFile(cfg.TLSCAFile, []byte("not a certificate"), 0o600), ShouldBeNil)
+		_, err := email.NewSMTPSender(cfg)
+		So(err, ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,244 @@
+package email_test
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSMTPConfig configures the behaviour of a fakeSMTPServer
+type fakeSMTPConfig struct {
+	// ImplicitTLS makes the server use TLS from the start of every connection, with TLSConfig
+	ImplicitTLS bool
+	// Extensions are advertised in reply to EHLO, e.g. "AUTH PLAIN CRAM-MD5" or "STARTTLS"
+	Extensions []string
+	// TLSConfig is used to upgrade the connection on STARTTLS, or from the start with ImplicitTLS
+	TLSConfig *tls.Config
+	// AuthReply is the reply to AUTH, which succeeds by default
+	AuthReply string
+	// NoopReply is the reply to NOOP, which succeeds by default
+	NoopReply string
+	// RcptReply is the reply to RCPT, which succeeds by default
+	RcptReply string
+}
+
+// fakeSMTPServer is a minimal SMTP server, listening on a local port, used to test the SMTP client code
+type fakeSMTPServer struct {
+	fakeSMTPConfig
+	listener net.Listener
+
+	mu          sync.Mutex
+	commands    []string
+	auths       []string
+	messages    []string
+	conns       map[net.Conn]struct{}
+	connections int
+}
+
+// newFakeSMTPServer starts a fake SMTP server with the provided configuration, that is closed when the test finishes
+func newFakeSMTPServer(t *testing.T, cfg fakeSMTPConfig) *fakeSMTPServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	s := &fakeSMTPServer{fakeSMTPConfig: cfg, listener: l, conns: map[net.Conn]struct{}{}}
+	t.Cleanup(func() { _ = l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+// Addr returns the address the server is listening on
+func (s *fakeSMTPServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Port returns the port the server is listening on
+func (s *fakeSMTPServer) Port() string {
+	_, port, _ := net.SplitHostPort(s.Addr())
+	return port
+}
+
+// Commands returns the verbs of all the commands received, in order
+func (s *fakeSMTPServer) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.commands...)
+}
+
+// Auths returns the arguments of all the AUTH commands received, e.g. "PLAIN AHVzZXIAcGFzc3dvcmQ="
+func (s *fakeSMTPServer) Auths() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.auths...)
+}
+
+// Messages returns the data of all the messages received
+func (s *fakeSMTPServer) Messages() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.messages...)
+}
+
+// Connections returns the number of connections accepted
+func (s *fakeSMTPServer) Connections() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connections
+}
+
+// DropConnections closes all the open connections, without replying to the clients
+func (s *fakeSMTPServer) DropConnections() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+}
+
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	s.mu.Lock()
+	s.conns[conn] = struct{}{}
+	s.connections++
+	s.mu.Unlock()
+	defer func(conn net.Conn) {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		_ = conn.Close()
+	}(conn)
+	if s.ImplicitTLS {
+		tlsConn := tls.Server(conn, s.TLSConfig)
+		if err := tlsConn.Handshake(); err != nil {
+			return
+		}
+		conn = tlsConn
+	}
+	r := bufio.NewReader(conn)
+	reply := func(lines ...string) {
+		for _, line := range lines {
+			_, _ = conn.Write([]byte(line + "\r\n"))
+		}
+	}
+
+	reply("220 fake ESMTP ready")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		s.mu.Lock()
+		s.commands = append(s.commands, verb)
+		s.mu.Unlock()
+
+		switch verb {
+		case "EHLO":
+			lines := []string{"250-fake"}
+			for _, ext := range s.Extensions {
+				lines = append(lines, "250-"+ext)
+			}
+			lines = append(lines, "250 8BITMIME")
+			reply(lines...)
+		case "HELO", "MAIL", "RSET":
+			reply("250 OK")
+		case "RCPT":
+			if s.RcptReply != "" {
+				reply(s.RcptReply)
+			} else {
+				reply("250 OK")
+			}
+		case "STARTTLS":
+			reply("220 ready to start TLS")
+			tlsConn := tls.Server(conn, s.TLSConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+			r = bufio.NewReader(conn)
+		case "AUTH":
+			s.mu.Lock()
+			s.auths = append(s.auths, strings.TrimSpace(line[len(verb):]))
+			s.mu.Unlock()
+			if s.AuthReply != "" {
+				reply(s.AuthReply)
+			} else {
+				reply("235 authentication succeeded")
+			}
+		case "NOOP":
+			if s.NoopReply != "" {
+				reply(s.NoopReply)
+			} else {
+				reply("250 OK")
+			}
+		case "DATA":
+			reply("354 end data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, data.String())
+			s.mu.Unlock()
+			reply("250 OK queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 command not implemented")
+		}
+	}
+}
+
+// newTestCertificate returns a self-signed certificate for 127.0.0.1 and localhost, and the PEM encoding of the certificate
+func newTestCertificate(t *testing.T) (tls.Certificate, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
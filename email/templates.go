@@ -0,0 +1,214 @@
+package email
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"net/mail"
+	"net/url"
+	"os"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/ONSdigital/dp-feedback-api/config"
+	"github.com/ONSdigital/dp-feedback-api/models"
+)
+
+// Feedback types, as provided to the email templates
+const (
+	TypeSpecificPage = "A specific page"
+	TypeGeneral      = "General feedback"
+)
+
+// Names of the default templates, embedded in the binary
+const (
+	defaultSubjectTemplate       = "templates/subject.tmpl"
+	defaultTextTemplate          = "templates/text.tmpl"
+	defaultHTMLTemplate          = "templates/html.tmpl"
+	defaultDigestSubjectTemplate = "templates/digest_subject.tmpl"
+	defaultDigestTextTemplate    = "templates/digest_text.tmpl"
+	defaultDigestHTMLTemplate    = "templates/digest_html.tmpl"
+)
+
+//go:embed templates/*.tmpl
+var defaultTemplates embed.FS
+
+// FeedbackData is the data available to the email templates
+type FeedbackData struct {
+	ID                string
+	FeedbackType      string
+	IsGeneralFeedback bool
+	IsPageUseful      bool
+	PageURL           string
+	PagePath          string
+	Description       string
+	Name              string
+	EmailAddress      string
+	ReceivedAt        time.Time
+	PossibleSpam      bool
+}
+
+// NewFeedbackData returns the template data for the provided feedback
+func NewFeedbackData(f *models.Feedback) *FeedbackData {
+	d := &FeedbackData{
+		ID:           f.ID,
+		FeedbackType: TypeSpecificPage,
+		PageURL:      f.OnsURL,
+		Description:  f.Feedback,
+		Name:         f.Name,
+		EmailAddress: f.EmailAddress,
+		PossibleSpam: f.IsPossibleSpam(),
+	}
+	if f.ReceivedAt != nil {
+		d.ReceivedAt = *f.ReceivedAt
+	}
+	if f.IsGeneralFeedback != nil && *f.IsGeneralFeedback {
+		d.IsGeneralFeedback = true
+		d.FeedbackType = TypeGeneral
+	}
+	if f.IsPageUseful != nil {
+		d.IsPageUseful = *f.IsPageUseful
+	}
+	if f.OnsURL != "" {
+		if u, err := url.Parse(models.NormaliseURL(f.OnsURL)); err == nil {
+			d.PagePath = u.EscapedPath()
+		}
+	}
+	return d
+}
+
+// Templates holds the parsed templates used to generate the feedback and digest emails, and the sanitization
+// applied to the feedback in them
+type Templates struct {
+	feedback *messageTemplates
+	digest   *messageTemplates
+	sanitize *config.Sanitize
+}
+
+// messageTemplates are the templates used to generate the subject and the bodies of a kind of email
+type messageTemplates struct {
+	prefix  string
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+// LoadTemplates parses the template files provided in the configuration.
+// Any template without a configured file uses the default template embedded in the binary.
+// The feedback is sanitized according to the provided configuration, if any, when the subject and text templates are rendered.
+// The html templates are always rendered with the feedback as it was submitted, as html/template escapes it.
+func LoadTemplates(cfg *config.EmailTemplates, sanitize *config.Sanitize) (*Templates, error) {
+	feedback, err := loadMessageTemplates("", cfg.SubjectPath, cfg.TextPath, cfg.HTMLPath,
+		defaultSubjectTemplate, defaultTextTemplate, defaultHTMLTemplate)
+	if err != nil {
+		return nil, err
+	}
+	digest, err := loadMessageTemplates("digest ", cfg.DigestSubjectPath, cfg.DigestTextPath, cfg.DigestHTMLPath,
+		defaultDigestSubjectTemplate, defaultDigestTextTemplate, defaultDigestHTMLTemplate)
+	if err != nil {
+		return nil, err
+	}
+	return &Templates{feedback: feedback, digest: digest, sanitize: sanitize}, nil
+}
+
+// loadMessageTemplates parses the subject, text and html templates of a kind of email, named by the provided prefix in errors
+func loadMessageTemplates(prefix, subjectPath, textPath, htmlPath, defaultSubject, defaultText, defaultHTML string) (*messageTemplates, error) {
+	subject, err := readTemplate(subjectPath, defaultSubject)
+	if err != nil {
+		return nil, err
+	}
+	text, err := readTemplate(textPath, defaultText)
+	if err != nil {
+		return nil, err
+	}
+	html, err := readTemplate(htmlPath, defaultHTML)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &messageTemplates{prefix: prefix}
+	if m.subject, err = texttemplate.New("subject").Option("missingkey=error").Parse(subject); err != nil {
+		return nil, fmt.Errorf("failed to parse %ssubject template: %w", prefix, err)
+	}
+	if m.text, err = texttemplate.New("text").Option("missingkey=error").Parse(text); err != nil {
+		return nil, fmt.Errorf("failed to parse %stext template: %w", prefix, err)
+	}
+	if m.html, err = htmltemplate.New("html").Option("missingkey=error").Parse(html); err != nil {
+		return nil, fmt.Errorf("failed to parse %shtml template: %w", prefix, err)
+	}
+	return m, nil
+}
+
+// render executes the subject and text templates with the provided text data, and the html template with the provided html data,
+// and returns a message with the resulting subject and bodies
+func (m *messageTemplates) render(textData, htmlData any, from string, to []string) (*Message, error) {
+	var subject, text, html bytes.Buffer
+	if err := m.subject.Execute(&subject, textData); err != nil {
+		return nil, fmt.Errorf("failed to execute %ssubject template: %w", m.prefix, err)
+	}
+	if err := m.text.Execute(&text, textData); err != nil {
+		return nil, fmt.Errorf("failed to execute %stext template: %w", m.prefix, err)
+	}
+	if err := m.html.Execute(&html, htmlData); err != nil {
+		return nil, fmt.Errorf("failed to execute %shtml template: %w", m.prefix, err)
+	}
+
+	return &Message{
+		Date: time.Now(),
+		From: from,
+		To:   to,
+		// the subject is a single header line, so any line breaks and repeated spaces are collapsed
+		Subject: strings.Join(strings.Fields(subject.String()), " "),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+		HTML:    html.String(),
+	}, nil
+}
+
+// readTemplate returns the contents of the template file in path, or of the default template if path is empty
+func readTemplate(path, defaultName string) (string, error) {
+	if path == "" {
+		b, err := defaultTemplates.ReadFile(defaultName)
+		if err != nil {
+			return "", fmt.Errorf("failed to read default template %s: %w", defaultName, err)
+		}
+		return string(b), nil
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read template file: %w", err)
+	}
+	return string(b), nil
+}
+
+// FeedbackMessage renders the templates for the provided feedback and returns the resulting message.
+// The feedback is sanitized in the subject and the text body, while the html template escapes it itself.
+func (t *Templates) FeedbackMessage(f *models.Feedback, from string, to []string) (*Message, error) {
+	msg, err := t.feedback.render(NewFeedbackData(t.sanitized(f)), NewFeedbackData(f), from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	msg.MessageID = NewMessageID(f.ID, from)
+	if f.ReceivedAt != nil {
+		msg.Date = *f.ReceivedAt
+	}
+	// replies go straight to the submitter, when they provided their email address
+	if f.EmailAddress != "" {
+		msg.ReplyTo = &mail.Address{Name: f.Name, Address: f.EmailAddress}
+	}
+	return msg, nil
+}
+
+// sanitized returns a copy of the provided feedback sanitized according to the configuration, as the feedback is stored
+// as it was submitted, and only sanitized where it is output as text
+func (t *Templates) sanitized(f *models.Feedback) *models.Feedback {
+	if t.sanitize == nil {
+		return f
+	}
+	sanitized := *f
+	sanitized.Sanitize(t.sanitize)
+	return &sanitized
+}
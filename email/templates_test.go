@@ -0,0 +1,188 @@
+package email_test
+
+import (
+	"net/mail"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-feedback-api/config"
+	"github.com/ONSdigital/dp-feedback-api/email"
+	"github.com/ONSdigital/dp-feedback-api/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var (
+	isPageUseful      = false
+	isGeneralFeedback = true
+	isSpecificPage    = false
+)
+
+var expectedText = `Feedback Type: A specific page
+Page URL: https://testhost:1234/sub/path
+Description: very nice and useful website!
+Name: Mr Feedback reporter
+Email address: feedback@reporter.com
+`
+
+var expectedGeneralText = `Description: very nice and useful website!
+Name: Mr Feedback reporter
+Email address: feedback@reporter.com
+`
+
+func testFeedback() *models.Feedback {
+	return &models.Feedback{
+		IsPageUseful:      &isPageUseful,
+		IsGeneralFeedback: &isSpecificPage,
+		OnsURL:            "https://testhost:1234/sub/path",
+		Feedback:          "very nice and useful website!",
+		Name:              "Mr Feedback reporter",
+		EmailAddress:      "feedback@reporter.com",
+	}
+}
+
+func testGeneralFeedback() *models.Feedback {
+	return &models.Feedback{
+		IsPageUseful:      &isPageUseful,
+		IsGeneralFeedback: &isGeneralFeedback,
+		Feedback:          "very nice and useful website!",
+		Name:              "Mr Feedback reporter",
+		EmailAddress:      "feedback@reporter.com",
+	}
+}
+
+func writeTemplate(dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	So(os.WriteFile(path, []byte(content), 0o600), ShouldBeNil)
+	return path
+}
+
+func TestFeedbackMessage(t *testing.T) {
+	Convey("Given the default templates", t, func() {
+		templates, err := email.LoadTemplates(&config.EmailTemplates{}, nil)
+		So(err, ShouldBeNil)
+
+		Convey("The expected message is generated from feedback about a specific page", func() {
+			msg, err := templates.FeedbackMessage(testFeedback(), "sender@mail.com", []string{"receiver@mail.com"})
+			So(err, ShouldBeNil)
+			So(msg.From, ShouldEqual, "sender@mail.com")
+			So(msg.To, ShouldResemble, []string{"receiver@mail.com"})
+			So(msg.Subject, ShouldEqual, "Feedback received - A specific page - /sub/path")
+			So(msg.ReplyTo, ShouldResemble, &mail.Address{Name: "Mr Feedback reporter", Address: "feedback@reporter.com"})
+			So(msg.MessageID, ShouldEndWith, "@mail.com>")
+			So(msg.Text, ShouldEqual, expectedText)
+			So(msg.HTML, ShouldContainSubstring, `<tr><th align="left">Page URL</th><td><a href="https://testhost:1234/sub/path">https://testhost:1234/sub/path</a></td></tr>`)
+			So(msg.HTML, ShouldContainSubstring, `<tr><th align="left">Description</th><td>very nice and useful website!</td></tr>`)
+		})
+
+		Convey("The expected message is generated from general feedback", func() {
+			msg, err := templates.FeedbackMessage(testGeneralFeedback(), "sender@mail.com", []string{"receiver@mail.com"})
+			So(err, ShouldBeNil)
+			So(msg.Subject, ShouldEqual, "Feedback received - General feedback")
+			So(msg.Text, ShouldEqual, expectedGeneralText)
+			So(msg.HTML, ShouldNotContainSubstring, "Feedback Type")
+		})
+
+		Convey("The subject of feedback tagged as possible spam is prefixed with a warning", func() {
+			f := testGeneralFeedback()
+			f.Spam = &models.Spam{Score: 3, Reasons: []string{"links"}, Action: models.SpamActionTag}
+			msg, err := templates.FeedbackMessage(f, "sender@mail.com", []string{"receiver@mail.com"})
+			So(err, ShouldBeNil)
+			So(msg.Subject, ShouldEqual, "[Possible spam] Feedback received - General feedback")
+		})
+
+		Convey("The subject of feedback with a spam score below the tag threshold is not prefixed", func() {
+			f := testGeneralFeedback()
+			f.Spam = &models.Spam{Score: 1, Reasons: []string{"links"}}
+			msg, err := templates.FeedbackMessage(f, "sender@mail.com", []string{"receiver@mail.com"})
+			So(err, ShouldBeNil)
+			So(msg.Subject, ShouldEqual, "Feedback received - General feedback")
+		})
+
+		Convey("The message is dated when the feedback was received, and has no Reply-To if no email address is provided", func() {
+			f := testGeneralFeedback()
+			receivedAt := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+			f.ReceivedAt = &receivedAt
+			f.EmailAddress = ""
+			msg, err := templates.FeedbackMessage(f, "sender@mail.com", []string{"receiver@mail.com"})
+			So(err, ShouldBeNil)
+			So(msg.Date, ShouldEqual, receivedAt)
+			So(msg.ReplyTo, ShouldBeNil)
+		})
+
+		Convey("Feedback values are escaped in the HTML body", func() {
+			f := testGeneralFeedback()
+			f.Feedback = "<script>alert(1)</script>"
+			msg, err := templates.FeedbackMessage(f, "sender@mail.com", []string{"receiver@mail.com"})
+			So(err, ShouldBeNil)
+			So(msg.HTML, ShouldNotContainSubstring, "<script>")
+			So(msg.HTML, ShouldContainSubstring, "&lt;script&gt;")
+		})
+	})
+
+	Convey("Given the default templates with sanitization enabled", t, func() {
+		templates, err := email.LoadTemplates(&config.EmailTemplates{}, &config.Sanitize{HTML: true, SQL: true})
+		So(err, ShouldBeNil)
+
+		Convey("The feedback is sanitized in the message, without modifying the provided feedback", func() {
+			f := testGeneralFeedback()
+			f.Feedback = "don't <b>"
+			msg, err := templates.FeedbackMessage(f, "sender@mail.com", []string{"receiver@mail.com"})
+			So(err, ShouldBeNil)
+			So(msg.Text, ShouldStartWith, `Description: don\&#39;t &lt;b&gt;`)
+			So(f.Feedback, ShouldEqual, "don't <b>")
+		})
+
+		Convey("The feedback is only escaped once in the HTML body, by its template", func() {
+			f := testGeneralFeedback()
+			f.Feedback = "don't <b>"
+			msg, err := templates.FeedbackMessage(f, "sender@mail.com", []string{"receiver@mail.com"})
+			So(err, ShouldBeNil)
+			So(msg.HTML, ShouldContainSubstring, `<td>don&#39;t &lt;b&gt;</td>`)
+		})
+	})
+
+	Convey("Given templates loaded from the configured files", t, func() {
+		dir := t.TempDir()
+		cfg := &config.EmailTemplates{
+			SubjectPath: writeTemplate(dir, "subject.tmpl", "[{{.FeedbackType}}]\n{{.PagePath}}\n"),
+			TextPath:    writeTemplate(dir, "text.tmpl", "{{.Description}} ({{.ID}})"),
+		}
+		templates, err := email.LoadTemplates(cfg, nil)
+		So(err, ShouldBeNil)
+
+		Convey("The message is generated from the configured templates, and the default for the missing one", func() {
+			f := testFeedback()
+			f.ID = "123"
+			msg, err := templates.FeedbackMessage(f, "sender@mail.com", []string{"receiver@mail.com"})
+			So(err, ShouldBeNil)
+			So(msg.Subject, ShouldEqual, "[A specific page] /sub/path")
+			So(msg.Text, ShouldEqual, "very nice and useful website! (123)\n")
+			So(msg.HTML, ShouldContainSubstring, "<table>")
+		})
+	})
+
+	Convey("Given a template that refers to a field that does not exist", t, func() {
+		cfg := &config.EmailTemplates{SubjectPath: writeTemplate(t.TempDir(), "subject.tmpl", "{{.Unknown}}")}
+		templates, err := email.LoadTemplates(cfg, nil)
+		So(err, ShouldBeNil)
+
+		Convey("Generating a message fails", func() {
+			_, err := templates.FeedbackMessage(testFeedback(), "sender@mail.com", []string{"receiver@mail.com"})
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestLoadTemplates(t *testing.T) {
+	Convey("Loading a template file that does not exist fails", t, func() {
+		_, err := email.LoadTemplates(&config.EmailTemplates{TextPath: filepath.Join(t.TempDir(), "missing.tmpl")}, nil)
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Loading a template file that cannot be parsed fails", t, func() {
+		_, err := email.LoadTemplates(&config.EmailTemplates{HTMLPath: writeTemplate(t.TempDir(), "html.tmpl", "{{if}}")}, nil)
+		So(err, ShouldNotBeNil)
+	})
+}
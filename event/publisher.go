@@ -0,0 +1,134 @@
+// Package event publishes the events about feedback, which are consumed by the analytics pipeline
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ONSdigital/dp-feedback-api/models"
+	"github.com/ONSdigital/dp-feedback-api/schema"
+	"github.com/ONSdigital/dp-feedback-api/worker"
+	"github.com/ONSdigital/dp-kafka/v4/avro"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// CheckerName is the name of the Kafka producer check reported by the healthcheck
+const CheckerName = "Kafka producer"
+
+var (
+	// ErrNotInitialised is returned when an event is published while the producer cannot reach the brokers
+	ErrNotInitialised = errors.New("kafka producer is not initialised")
+	// ErrQueueFull is returned when an event is published while the queue of pending events is full
+	ErrQueueFull = errors.New("event queue is full")
+	// ErrPublisherClosed is returned when an event is published after the publisher has been closed
+	ErrPublisherClosed = errors.New("event publisher is closed")
+)
+
+// Producer defines the required methods to send a message to the feedback-received topic
+type Producer interface {
+	IsInitialised() bool
+	Send(ctx context.Context, schema *avro.Schema, event interface{}) error
+}
+
+// Publisher publishes the feedback-received events with the provided producer from a background worker,
+// so that requests are not held up by the brokers.
+// The producer blocks until it can reach the brokers, so events are skipped rather than sent while it is not initialised.
+// Pending events are kept in memory only, and the ones that cannot be sent are logged and dropped.
+type Publisher struct {
+	producer Producer
+
+	mu     sync.RWMutex
+	closed bool
+	queue  chan *models.FeedbackReceived
+	worker *worker.Worker
+}
+
+// NewPublisher returns a new Publisher that sends the events with the provided producer, queueing up to queueSize events
+func NewPublisher(producer Producer, queueSize int) *Publisher {
+	return &Publisher{
+		producer: producer,
+		queue:    make(chan *models.FeedbackReceived, queueSize),
+		worker:   worker.New(),
+	}
+}
+
+// PublishFeedbackReceived queues the feedback-received event for the provided feedback to be sent by the worker, without blocking.
+// It fails if the producer is not initialised, so that the event is not held until the brokers can be reached.
+func (p *Publisher) PublishFeedbackReceived(ctx context.Context, f *models.Feedback) error {
+	if !p.producer.IsInitialised() {
+		return ErrNotInitialised
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return ErrPublisherClosed
+	}
+	select {
+	case p.queue <- models.NewFeedbackReceived(f):
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Start runs the worker in a new go-routine, which sends the queued events until the publisher is closed
+func (p *Publisher) Start(ctx context.Context) {
+	p.worker.Start(func(stop <-chan struct{}) {
+		p.run(ctx, stop)
+	})
+}
+
+func (p *Publisher) run(ctx context.Context, stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case e := <-p.queue:
+			p.send(ctx, e)
+		}
+	}
+}
+
+// SendQueued sends all the queued events, until the queue is empty or the context is done
+func (p *Publisher) SendQueued(ctx context.Context) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		select {
+		case e := <-p.queue:
+			p.send(ctx, e)
+		default:
+			return nil
+		}
+	}
+}
+
+// send sends the provided event marshalled with its Avro schema, and logs any failure, as there is nobody to report it to
+func (p *Publisher) send(ctx context.Context, e *models.FeedbackReceived) {
+	logData := log.Data{"feedback_id": e.ID}
+	if !p.producer.IsInitialised() {
+		log.Error(ctx, "failed to publish feedback-received event", ErrNotInitialised, logData)
+		return
+	}
+	if err := p.producer.Send(ctx, schema.FeedbackReceivedEvent, e); err != nil {
+		log.Error(ctx, "failed to publish feedback-received event", fmt.Errorf("failed to send event: %w", err), logData)
+	}
+}
+
+// Close stops accepting events and stops the worker, and then sends any queued events, until the queue is empty or the context is done
+func (p *Publisher) Close(ctx context.Context) error {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+
+	// wait for the worker to finish any send in progress
+	if err := p.worker.Stop(ctx); err != nil {
+		return err
+	}
+	return p.SendQueued(ctx)
+}
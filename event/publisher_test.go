@@ -0,0 +1,156 @@
+package event_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-feedback-api/event"
+	"github.com/ONSdigital/dp-feedback-api/models"
+	"github.com/ONSdigital/dp-feedback-api/schema"
+	"github.com/ONSdigital/dp-kafka/v4/avro"
+	"github.com/ONSdigital/dp-kafka/v4/kafkatest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var (
+	ctx         = context.Background()
+	errProducer = errors.New("producer error")
+)
+
+// producer is a kafka producer mock that records the messages it sends, marshalled with their schema
+type producer struct {
+	*kafkatest.IProducerMock
+	mu       sync.Mutex
+	messages [][]byte
+	sent     chan struct{}
+}
+
+func newProducer(initialised bool) *producer {
+	p := &producer{sent: make(chan struct{}, 10)}
+	p.IProducerMock = &kafkatest.IProducerMock{
+		IsInitialisedFunc: func() bool { return initialised },
+		SendFunc: func(ctx context.Context, s *avro.Schema, event interface{}) error {
+			msg, err := s.Marshal(event)
+			if err != nil {
+				return err
+			}
+			p.mu.Lock()
+			p.messages = append(p.messages, msg)
+			p.mu.Unlock()
+			p.sent <- struct{}{}
+			return nil
+		},
+	}
+	return p
+}
+
+func (p *producer) Messages() [][]byte {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.messages
+}
+
+func testFeedback() *models.Feedback {
+	isPageUseful, isGeneralFeedback := true, false
+	receivedAt := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+	return &models.Feedback{
+		ID:                "feedback-1",
+		ReceivedAt:        &receivedAt,
+		IsPageUseful:      &isPageUseful,
+		IsGeneralFeedback: &isGeneralFeedback,
+		OnsURL:            "https://localhost/subpath",
+		Feedback:          "very useful",
+		Name:              "Mr Reporter",
+		EmailAddress:      "feedback@reporter.com",
+	}
+}
+
+func TestPublishFeedbackReceived(t *testing.T) {
+	Convey("Given a started publisher with an initialised producer", t, func() {
+		producer := newProducer(true)
+		p := event.NewPublisher(producer, 2)
+		p.Start(ctx)
+
+		Convey("When a feedback-received event is published", func() {
+			So(p.PublishFeedbackReceived(ctx, testFeedback()), ShouldBeNil)
+
+			Convey("Then the event is sent by the worker marshalled with its schema, without the contact details of the user", func() {
+				select {
+				case <-producer.sent:
+				case <-time.After(5 * time.Second):
+					t.Fatal("timed out waiting for the event to be sent")
+				}
+				So(producer.Messages(), ShouldHaveLength, 1)
+				e := &models.FeedbackReceived{}
+				So(schema.FeedbackReceivedEvent.Unmarshal(producer.Messages()[0], e), ShouldBeNil)
+				So(e, ShouldResemble, &models.FeedbackReceived{
+					ID:                "feedback-1",
+					ReceivedAt:        "2024-03-15T10:30:00Z",
+					IsPageUseful:      true,
+					IsGeneralFeedback: false,
+					OnsURL:            "https://localhost/subpath",
+					Feedback:          "very useful",
+				})
+				So(p.Close(ctx), ShouldBeNil)
+			})
+		})
+	})
+
+	Convey("Given a publisher with a producer that blocks", t, func() {
+		sending, unblock := make(chan struct{}, 1), make(chan struct{})
+		producer := newProducer(true)
+		producer.SendFunc = func(ctx context.Context, s *avro.Schema, event interface{}) error {
+			sending <- struct{}{}
+			<-unblock
+			return errProducer
+		}
+		p := event.NewPublisher(producer, 1)
+		p.Start(ctx)
+
+		Convey("When an event is being sent", func() {
+			So(p.PublishFeedbackReceived(ctx, testFeedback()), ShouldBeNil)
+			select {
+			case <-sending:
+			case <-time.After(5 * time.Second):
+				t.Fatal("timed out waiting for the event to be sent")
+			}
+
+			Convey("Then publishing does not wait for the producer, and fails once the queue is full", func() {
+				So(p.PublishFeedbackReceived(ctx, testFeedback()), ShouldBeNil)
+				So(p.PublishFeedbackReceived(ctx, testFeedback()), ShouldEqual, event.ErrQueueFull)
+
+				close(unblock)
+				So(p.Close(ctx), ShouldBeNil)
+			})
+		})
+	})
+
+	Convey("Given a publisher with a producer that is not initialised", t, func() {
+		producer := newProducer(false)
+		p := event.NewPublisher(producer, 2)
+
+		Convey("Then the event is not queued, and the producer is not used", func() {
+			So(p.PublishFeedbackReceived(ctx, testFeedback()), ShouldEqual, event.ErrNotInitialised)
+			So(p.Close(ctx), ShouldBeNil)
+			So(producer.SendCalls(), ShouldBeEmpty)
+		})
+	})
+
+	Convey("Given a publisher that was not started", t, func() {
+		producer := newProducer(true)
+		p := event.NewPublisher(producer, 2)
+		So(p.PublishFeedbackReceived(ctx, testFeedback()), ShouldBeNil)
+
+		Convey("When it is closed", func() {
+			So(p.Close(ctx), ShouldBeNil)
+
+			Convey("Then the queued events are sent, and no more events are accepted", func() {
+				So(producer.Messages(), ShouldHaveLength, 1)
+				So(p.PublishFeedbackReceived(ctx, testFeedback()), ShouldEqual, event.ErrPublisherClosed)
+			})
+		})
+	})
+}
@@ -14,12 +14,27 @@ Feature: Feedback
           "email_address": "feedback@reporter.com"
         }
       """
-    Then I should receive a 201 status code with an empty body response
+    Then I should receive the following JSON response with status "201":
+      """
+        {
+          "id": "feedback-1",
+          "received_at": "2024-03-15T10:30:00Z",
+          "is_page_useful": false,
+          "is_general_feedback": false,
+          "ons_url": "https://localhost/subpath/one",
+          "feedback": "very nice and useful website!",
+          "name": "Mr Reporter",
+          "email_address": "feedback@reporter.com"
+        }
+      """
+    And the response header "Location" should be "/feedback/feedback-1"
     And the following email is sent
       """
         From: sender@feedback.com
         To: receiver@feedback.com
-        Subject: Feedback received
+        Reply-To: "Mr Reporter" <feedback@reporter.com>
+        Date: Fri, 15 Mar 2024 10:30:00 +0000
+        Subject: Feedback received - A specific page - /subpath/one
 
         Feedback Type: A specific page
         Page URL: https://localhost/subpath/one
@@ -27,6 +42,147 @@ Feature: Feedback
         Name: Mr Reporter
         Email address: feedback@reporter.com
       """
+    And the sent email HTML body contains "<a href="https://localhost/subpath/one">https://localhost/subpath/one</a>"
+    And the following feedback is posted to the webhook
+      """
+        {
+          "id": "feedback-1",
+          "received_at": "2024-03-15T10:30:00Z",
+          "is_page_useful": false,
+          "is_general_feedback": false,
+          "ons_url": "https://localhost/subpath/one",
+          "feedback": "very nice and useful website!",
+          "name": "Mr Reporter",
+          "email_address": "feedback@reporter.com"
+        }
+      """
+    And the following feedback is stored
+      """
+        {
+          "is_page_useful": false,
+          "is_general_feedback": false,
+          "ons_url": "https://localhost/subpath/one",
+          "feedback": "very nice and useful website!",
+          "name": "Mr Reporter",
+          "email_address": "feedback@reporter.com"
+        }
+      """
+    And the following feedback-received event is published
+      """
+        {
+          "id": "feedback-1",
+          "received_at": "2024-03-15T10:30:00Z",
+          "is_page_useful": false,
+          "is_general_feedback": false,
+          "ons_url": "https://localhost/subpath/one",
+          "feedback": "very nice and useful website!"
+        }
+      """
+
+
+  Scenario: Posting feedback for a page that matches a route
+    Given I am authorised
+    When I POST "/feedback"
+      """
+        {
+          "is_page_useful": false,
+          "is_general_feedback": false,
+          "ons_url": "https://localhost/census/2021",
+          "feedback": "could not find the data"
+        }
+      """
+    Then the HTTP status code should be "201"
+    And the following email is sent
+      """
+        From: sender@feedback.com
+        To: census@feedback.com
+        Subject: Feedback received - A specific page - /census/2021
+
+        Feedback Type: A specific page
+        Page URL: https://localhost/census/2021
+        Description: could not find the data
+      """
+
+
+  Scenario: Posting feedback for pages that match a route with a digest
+    Given I am authorised
+    And I POST "/feedback"
+      """
+        {
+          "is_page_useful": false,
+          "is_general_feedback": false,
+          "ons_url": "https://localhost/releases/calendar",
+          "feedback": "the release date is wrong"
+        }
+      """
+    And I POST "/feedback"
+      """
+        {
+          "is_page_useful": false,
+          "is_general_feedback": false,
+          "ons_url": "https://localhost/releases/calendar",
+          "feedback": "cannot find the release",
+          "name": "Mr Reporter"
+        }
+      """
+    And I POST "/feedback"
+      """
+        {
+          "is_page_useful": false,
+          "is_general_feedback": false,
+          "ons_url": "https://localhost/releases",
+          "feedback": "page is slow"
+        }
+      """
+    When 30 minutes have passed
+    Then no email is sent
+    When 30 minutes have passed
+    Then the following email is sent
+      """
+        From: sender@feedback.com
+        To: releases@feedback.com
+        Subject: Feedback digest - releases - 3 submissions
+
+        3 feedback submissions received between 15 Mar 2024 10:30 UTC and 15 Mar 2024 10:30 UTC
+
+        https://localhost/releases/calendar (2)
+
+        - Received: 15 Mar 2024 10:30 UTC
+          Description: the release date is wrong
+
+        - Received: 15 Mar 2024 10:30 UTC
+          Description: cannot find the release
+          Name: Mr Reporter
+
+        https://localhost/releases (1)
+
+        - Received: 15 Mar 2024 10:30 UTC
+          Description: page is slow
+      """
+
+
+  Scenario: Posting urgent feedback for a page that matches a route with a digest
+    Given I am authorised
+    When I POST "/feedback"
+      """
+        {
+          "is_page_useful": false,
+          "is_general_feedback": false,
+          "ons_url": "https://localhost/releases/calendar",
+          "feedback": "urgent: the release date is wrong"
+        }
+      """
+    Then the HTTP status code should be "201"
+    And the following email is sent
+      """
+        From: sender@feedback.com
+        To: releases@feedback.com
+        Subject: Feedback received - A specific page - /releases/calendar
+
+        Feedback Type: A specific page
+        Page URL: https://localhost/releases/calendar
+        Description: urgent: the release date is wrong
+      """
 
 
   Scenario: Posting valid useful page feedback
@@ -38,8 +194,36 @@ Feature: Feedback
           "is_general_feedback": false
         }
       """
-    Then I should receive a 201 status code with an empty body response
+    Then I should receive the following JSON response with status "201":
+      """
+        {
+          "id": "feedback-1",
+          "received_at": "2024-03-15T10:30:00Z",
+          "is_page_useful": true,
+          "is_general_feedback": false
+        }
+      """
+    And the response header "Location" should be "/feedback/feedback-1"
     And no email is sent
+    And no feedback is posted to the webhook
+    And the following feedback is stored
+      """
+        {
+          "is_page_useful": true,
+          "is_general_feedback": false
+        }
+      """
+    And the following feedback-received event is published
+      """
+        {
+          "id": "feedback-1",
+          "received_at": "2024-03-15T10:30:00Z",
+          "is_page_useful": true,
+          "is_general_feedback": false,
+          "ons_url": "",
+          "feedback": ""
+        }
+      """
 
 
   Scenario: Posting valid general feedback
@@ -54,12 +238,24 @@ Feature: Feedback
           "email_address": "feedback@reporter.com"
         }
       """
-    Then I should receive a 201 status code with an empty body response
+    Then I should receive the following JSON response with status "201":
+      """
+        {
+          "id": "feedback-1",
+          "received_at": "2024-03-15T10:30:00Z",
+          "is_page_useful": false,
+          "is_general_feedback": true,
+          "feedback": "very nice and useful website!",
+          "name": "Mr Reporter",
+          "email_address": "feedback@reporter.com"
+        }
+      """
+    And the response header "Location" should be "/feedback/feedback-1"
     And the following email is sent
       """
         From: sender@feedback.com
         To: receiver@feedback.com
-        Subject: Feedback received
+        Subject: Feedback received - General feedback
 
         Description: very nice and useful website!
         Name: Mr Reporter
@@ -76,11 +272,16 @@ Feature: Feedback
           "is_general_feedback": true
         }
       """
-    Then I should receive a 400 status code with an the following body response
+    Then I should receive the following JSON response with status "400":
       """
-        description is required if page is not useful
+        {
+          "code": "invalid_request",
+          "message": "description is required if page is not useful"
+        }
       """
-      And no email is sent
+    And no email is sent
+    And no feedback is stored
+    And no feedback-received event is published
 
 
   Scenario: Posting valid feedback with only required fields
@@ -93,12 +294,22 @@ Feature: Feedback
           "feedback": "very nice and useful page!"
         }
       """
-    Then I should receive a 201 status code with an empty body response
+    Then I should receive the following JSON response with status "201":
+      """
+        {
+          "id": "feedback-1",
+          "received_at": "2024-03-15T10:30:00Z",
+          "is_page_useful": false,
+          "is_general_feedback": false,
+          "feedback": "very nice and useful page!"
+        }
+      """
+    And the response header "Location" should be "/feedback/feedback-1"
     And the following email is sent
       """
         From: sender@feedback.com
         To: receiver@feedback.com
-        Subject: Feedback received
+        Subject: Feedback received - A specific page
 
         Feedback Type: A specific page
         Description: very nice and useful page!
@@ -115,13 +326,69 @@ Feature: Feedback
           "ons_url": "https://attacker/subpath/one"
         }
       """
-    Then I should receive a 400 status code with an the following body response
+    Then I should receive the following JSON response with status "400":
       """
-        Key: 'Feedback.OnsURL' Error:Field validation for 'OnsURL' failed on the 'ons_url' tag
+        {
+          "code": "validation_failed",
+          "message": "request body failed validation",
+          "errors": [
+            {
+              "field": "OnsURL",
+              "json_name": "ons_url",
+              "rule": "ons_url",
+              "reason": "host 'attacker' does not match any allowed domain"
+            }
+          ]
+        }
       """
     And no email is sent
 
 
+  Scenario: Posting feedback about the pages of several allowed domains
+    Given I am authorised
+    And the allowed domains are "localhost,*.beta.localhost,census.localhost/2021"
+    When I POST "/feedback"
+      """
+        {
+          "is_page_useful": true,
+          "is_general_feedback": false,
+          "ons_url": "https://census.localhost/2021/results"
+        }
+      """
+    Then the HTTP status code should be "201"
+    When I POST "/feedback"
+      """
+        {
+          "is_page_useful": true,
+          "is_general_feedback": false,
+          "ons_url": "https://census.localhost/2011/results"
+        }
+      """
+    Then I should receive the following JSON response with status "400":
+      """
+        {
+          "code": "validation_failed",
+          "message": "request body failed validation",
+          "errors": [
+            {
+              "field": "OnsURL",
+              "json_name": "ons_url",
+              "rule": "ons_url",
+              "reason": "path '/2011/results' is not allowed for domain 'census.localhost'"
+            }
+          ]
+        }
+      """
+    And the following feedback is stored
+      """
+        {
+          "is_page_useful": true,
+          "is_general_feedback": false,
+          "ons_url": "https://census.localhost/2021/results"
+        }
+      """
+
+
   Scenario: Posting feedback with an invalid email address
     Given I am authorised
     When I POST "/feedback"
@@ -132,14 +399,24 @@ Feature: Feedback
           "email_address": "wrong.format"
         }
       """
-    Then I should receive a 400 status code with an the following body response
+    Then I should receive the following JSON response with status "400":
       """
-        Key: 'Feedback.EmailAddress' Error:Field validation for 'EmailAddress' failed on the 'email' tag
+        {
+          "code": "validation_failed",
+          "message": "request body failed validation",
+          "errors": [
+            {
+              "field": "EmailAddress",
+              "json_name": "email_address",
+              "rule": "email"
+            }
+          ]
+        }
       """
     And no email is sent
 
 
-  Scenario: Posting feedback with unsafe strings
+  Scenario: Posting feedback with unsafe strings, which are stored as submitted and sanitized in emails
     Given I am authorised
     When I POST "/feedback"
       """
@@ -150,14 +427,573 @@ Feature: Feedback
           "feedback": "<script>document.getElementById('demo').innerHTML = 'Hello JavaScript!'';</script>"
         }
       """
-    Then I should receive a 201 status code with an empty body response
+    Then I should receive the following JSON response with status "201":
+      """
+        {
+          "id": "feedback-1",
+          "received_at": "2024-03-15T10:30:00Z",
+          "is_page_useful": false,
+          "is_general_feedback": false,
+          "ons_url": "https://localhost/subpath/one",
+          "feedback": "<script>document.getElementById('demo').innerHTML = 'Hello JavaScript!'';</script>"
+        }
+      """
+    And the response header "Location" should be "/feedback/feedback-1"
     And the following email is sent
       """
         From: sender@feedback.com
         To: receiver@feedback.com
-        Subject: Feedback received
+        Subject: Feedback received - A specific page - /subpath/one
 
         Feedback Type: A specific page
         Page URL: https://localhost/subpath/one
         Description: &lt;script&gt;document.getElementById(\&#39;demo\&#39;).innerHTML = \&#39;Hello JavaScript!\&#39;\&#39;;&lt;/script&gt;
       """
+
+
+  Scenario: Posting feedback without authorisation
+    Given I am not authorised
+    When I POST "/feedback"
+      """
+        {
+          "is_page_useful": false,
+          "is_general_feedback": true,
+          "feedback": "very nice and useful website!"
+        }
+      """
+    Then I should receive the following JSON response with status "401":
+      """
+        {
+          "code": "unauthorised",
+          "message": "missing or malformed authorization header"
+        }
+      """
+    And no email is sent
+
+
+  Scenario: Listing stored feedback with filters and pagination
+    Given I am authorised
+    And I POST "/feedback"
+      """
+        {
+          "is_page_useful": true,
+          "is_general_feedback": false,
+          "ons_url": "https://localhost/economy/inflation"
+        }
+      """
+    And I POST "/feedback"
+      """
+        {
+          "is_page_useful": true,
+          "is_general_feedback": false,
+          "ons_url": "https://localhost/economy/gdp"
+        }
+      """
+    And I POST "/feedback"
+      """
+        {
+          "is_page_useful": false,
+          "is_general_feedback": false,
+          "ons_url": "https://localhost/census",
+          "feedback": "could not find the data"
+        }
+      """
+    When I GET "/v1/feedback?is_page_useful=true&ons_url=localhost/economy&limit=1"
+    Then I should receive a list of 1 feedback items out of 2
+
+
+  Scenario: Getting stored feedback by id
+    Given I am authorised
+    And I POST "/feedback"
+      """
+        {
+          "is_page_useful": true,
+          "is_general_feedback": false,
+          "ons_url": "https://localhost/economy"
+        }
+      """
+    When I GET "/v1/feedback/feedback-1"
+    Then I should receive the following JSON response with status "200":
+      """
+        {
+          "id": "feedback-1",
+          "received_at": "2024-03-15T10:30:00Z",
+          "is_page_useful": true,
+          "is_general_feedback": false,
+          "ons_url": "https://localhost/economy"
+        }
+      """
+
+
+  Scenario: Getting feedback that does not exist
+    Given I am authorised
+    When I GET "/v1/feedback/unknown"
+    Then I should receive the following JSON response with status "404":
+      """
+        {
+          "code": "not_found",
+          "message": "feedback not found"
+        }
+      """
+
+
+  Scenario: Getting stored feedback as a caller that is not a feedback reader
+    Given I am authorised
+    And the feedback readers are "dp-feedback-dashboard"
+    When I GET "/v1/feedback/feedback-1"
+    Then I should receive the following JSON response with status "403":
+      """
+        {
+          "code": "forbidden",
+          "message": "caller is not allowed to read feedback: component-test-service"
+        }
+      """
+
+
+  Scenario: Getting the page usefulness summary
+    Given I am authorised
+    And I POST "/feedback"
+      """
+        {
+          "is_page_useful": true,
+          "is_general_feedback": false,
+          "ons_url": "https://localhost/economy"
+        }
+      """
+    And I POST "/feedback"
+      """
+        {
+          "is_page_useful": false,
+          "is_general_feedback": false,
+          "ons_url": "https://localhost/census/",
+          "feedback": "could not find the data"
+        }
+      """
+    And I POST "/feedback"
+      """
+        {
+          "is_page_useful": true,
+          "is_general_feedback": false,
+          "ons_url": "localhost/census"
+        }
+      """
+    When I GET "/v1/feedback/summary?interval=week"
+    Then I should receive the following JSON response with status "200":
+      """
+        {
+          "items": [
+            {
+              "ons_url": "localhost/census",
+              "period_start": "2024-03-11",
+              "useful": 1,
+              "not_useful": 1,
+              "total": 2,
+              "useful_percentage": 50,
+              "not_useful_percentage": 50
+            },
+            {
+              "ons_url": "localhost/economy",
+              "period_start": "2024-03-11",
+              "useful": 1,
+              "not_useful": 0,
+              "total": 1,
+              "useful_percentage": 100,
+              "not_useful_percentage": 0
+            }
+          ],
+          "count": 2,
+          "offset": 0,
+          "limit": 20,
+          "total_count": 2
+        }
+      """
+
+
+  Scenario: Posting feedback while the mail server is unavailable
+    Given I am authorised
+    And the mail server is unavailable
+    When I POST "/feedback"
+      """
+        {
+          "is_page_useful": false,
+          "is_general_feedback": true,
+          "feedback": "very nice and useful website!"
+        }
+      """
+    Then the HTTP status code should be "201"
+    And the following feedback is stored
+      """
+        {
+          "is_page_useful": false,
+          "is_general_feedback": true,
+          "feedback": "very nice and useful website!"
+        }
+      """
+    And 1 email is waiting in the outbox to be retried
+
+
+  Scenario: Posting feedback too often from the same client IP
+    Given I am authorised
+    And the rate limit per client IP is 2 requests per minute
+    And I POST "/feedback"
+      """
+        {
+          "is_page_useful": true,
+          "is_general_feedback": false,
+          "ons_url": "https://localhost/economy"
+        }
+      """
+    And I POST "/feedback"
+      """
+        {
+          "is_page_useful": true,
+          "is_general_feedback": false,
+          "ons_url": "https://localhost/economy"
+        }
+      """
+    When I POST "/feedback"
+      """
+        {
+          "is_page_useful": true,
+          "is_general_feedback": false,
+          "ons_url": "https://localhost/economy"
+        }
+      """
+    Then I should receive the following JSON response with status "429":
+      """
+        {
+          "code": "too_many_requests",
+          "message": "too many requests, please try again later"
+        }
+      """
+    And the response header "Retry-After" should be "30"
+    When 1 minutes have passed
+    And I POST "/feedback"
+      """
+        {
+          "is_page_useful": true,
+          "is_general_feedback": false,
+          "ons_url": "https://localhost/economy"
+        }
+      """
+    Then the HTTP status code should be "201"
+
+
+  Scenario: Retrying feedback with an idempotency key
+    Given I am authorised
+    And I set the "Idempotency-Key" header to "3f6c1b0e-8a4d-4d59-9b1e-2f0a7c5d9e41"
+    And I POST "/feedback"
+      """
+        {
+          "is_page_useful": false,
+          "is_general_feedback": true,
+          "feedback": "The search does not work"
+        }
+      """
+    When I POST "/feedback"
+      """
+        {
+          "is_page_useful": false,
+          "is_general_feedback": true,
+          "feedback": "The search does not work"
+        }
+      """
+    Then I should receive the following JSON response with status "201":
+      """
+        {
+          "id": "feedback-1",
+          "received_at": "2024-03-15T10:30:00Z",
+          "is_page_useful": false,
+          "is_general_feedback": true,
+          "feedback": "The search does not work"
+        }
+      """
+    And the response header "Location" should be "/feedback/feedback-1"
+    And the response header "Idempotent-Replayed" should be "true"
+    And the following feedback is stored
+      """
+        {
+          "is_page_useful": false,
+          "is_general_feedback": true,
+          "feedback": "The search does not work"
+        }
+      """
+    And the following email is sent
+      """
+        From: sender@feedback.com
+        To: receiver@feedback.com
+        Subject: Feedback received - General feedback
+
+        Description: The search does not work
+      """
+    When I POST "/feedback"
+      """
+        {
+          "is_page_useful": false,
+          "is_general_feedback": true,
+          "feedback": "The search still does not work"
+        }
+      """
+    Then I should receive the following JSON response with status "422":
+      """
+        {
+          "code": "unprocessable_request",
+          "message": "the idempotency key was already used by a different request"
+        }
+      """
+
+
+  Scenario: Posting a near-duplicate of recent feedback
+    Given I am authorised
+    And I POST "/feedback"
+      """
+        {
+          "is_page_useful": false,
+          "is_general_feedback": false,
+          "ons_url": "https://localhost/economy/inflation",
+          "feedback": "I could not find the latest inflation figures anywhere",
+          "email_address": "someone@example.com"
+        }
+      """
+    When I POST "/feedback"
+      """
+        {
+          "is_page_useful": false,
+          "is_general_feedback": false,
+          "ons_url": "https://localhost/economy/inflation",
+          "feedback": "I could not find the latest inflation figures anywhere!",
+          "name": "Someone",
+          "email_address": "Someone@example.com"
+        }
+      """
+    Then I should receive the following JSON response with status "201":
+      """
+        {
+          "id": "feedback-1",
+          "received_at": "2024-03-15T10:30:00Z",
+          "is_page_useful": false,
+          "is_general_feedback": false,
+          "ons_url": "https://localhost/economy/inflation",
+          "feedback": "I could not find the latest inflation figures anywhere!",
+          "name": "Someone",
+          "email_address": "Someone@example.com",
+          "occurrences": 2
+        }
+      """
+    And the response header "Location" should be "/feedback/feedback-1"
+    And the following feedback is stored
+      """
+        {
+          "is_page_useful": false,
+          "is_general_feedback": false,
+          "ons_url": "https://localhost/economy/inflation",
+          "feedback": "I could not find the latest inflation figures anywhere",
+          "email_address": "someone@example.com"
+        }
+      """
+    And the following email is sent
+      """
+        From: sender@feedback.com
+        To: receiver@feedback.com
+        Subject: Feedback received - A specific page - /economy/inflation
+
+        Feedback Type: A specific page
+        Page URL: https://localhost/economy/inflation
+        Description: I could not find the latest inflation figures anywhere
+        Email address: someone@example.com
+      """
+    When 10 minutes have passed
+    And I POST "/feedback"
+      """
+        {
+          "is_page_useful": false,
+          "is_general_feedback": false,
+          "ons_url": "https://localhost/economy/inflation",
+          "feedback": "I could not find the latest inflation figures anywhere"
+        }
+      """
+    Then the HTTP status code should be "201"
+    And the response header "Location" should be "/feedback/feedback-3"
+
+
+  Scenario: Posting feedback with personal information in its description
+    Given I am authorised
+    When I POST "/feedback"
+      """
+        {
+          "is_page_useful": false,
+          "is_general_feedback": true,
+          "feedback": "Please call me on 07700 900123 or write to SW1A 1AA"
+        }
+      """
+    Then I should receive the following JSON response with status "201":
+      """
+        {
+          "id": "feedback-1",
+          "received_at": "2024-03-15T10:30:00Z",
+          "is_page_useful": false,
+          "is_general_feedback": true,
+          "feedback": "Please call me on [REDACTED PHONE NUMBER] or write to [REDACTED POSTCODE]",
+          "redactions": [
+            {"type": "phone_number", "count": 1},
+            {"type": "postcode", "count": 1}
+          ]
+        }
+      """
+    And the following email is sent
+      """
+        From: sender@feedback.com
+        To: receiver@feedback.com
+        Subject: Feedback received - General feedback
+
+        Description: Please call me on [REDACTED PHONE NUMBER] or write to [REDACTED POSTCODE]
+      """
+    And the following feedback is stored
+      """
+        {
+          "is_page_useful": false,
+          "is_general_feedback": true,
+          "feedback": "Please call me on [REDACTED PHONE NUMBER] or write to [REDACTED POSTCODE]",
+          "redactions": [
+            {"type": "phone_number", "count": 1},
+            {"type": "postcode", "count": 1}
+          ]
+        }
+      """
+
+
+  Scenario: Posting feedback that looks like spam, without returning its spam result
+    Given I am authorised
+    When I POST "/feedback"
+      """
+        {
+          "is_page_useful": false,
+          "is_general_feedback": true,
+          "feedback": "more statistics at www.example.com"
+        }
+      """
+    Then I should receive the following JSON response with status "201":
+      """
+        {
+          "id": "feedback-1",
+          "received_at": "2024-03-15T10:30:00Z",
+          "is_page_useful": false,
+          "is_general_feedback": true,
+          "feedback": "more statistics at www.example.com"
+        }
+      """
+    And the following email is sent
+      """
+        From: sender@feedback.com
+        To: receiver@feedback.com
+        Subject: [Possible spam] Feedback received - General feedback
+
+        Description: more statistics at www.example.com
+      """
+    And the following feedback is stored
+      """
+        {
+          "is_page_useful": false,
+          "is_general_feedback": true,
+          "feedback": "more statistics at www.example.com",
+          "spam": {
+            "score": 3,
+            "reasons": ["links", "external_links"],
+            "action": "tag"
+          }
+        }
+      """
+
+
+  Scenario: Posting feedback that is quarantined as spam
+    Given I am authorised
+    When I POST "/feedback"
+      """
+        {
+          "is_page_useful": false,
+          "is_general_feedback": true,
+          "feedback": "buy now at www.example.com"
+        }
+      """
+    Then the HTTP status code should be "201"
+    And no email is sent
+    And no feedback is posted to the webhook
+    And the following feedback is stored
+      """
+        {
+          "is_page_useful": false,
+          "is_general_feedback": true,
+          "feedback": "buy now at www.example.com",
+          "spam": {
+            "score": 6,
+            "reasons": ["links", "external_links", "spam_phrases"],
+            "action": "quarantine"
+          }
+        }
+      """
+
+
+  Scenario: Posting feedback with the honeypot field filled in
+    Given I am authorised
+    When I POST "/feedback"
+      """
+        {
+          "is_page_useful": false,
+          "is_general_feedback": true,
+          "feedback": "very nice and useful website!",
+          "honeypot": "https://example.com"
+        }
+      """
+    Then I should receive the following JSON response with status "201":
+      """
+        {
+          "id": "feedback-1",
+          "received_at": "2024-03-15T10:30:00Z",
+          "is_page_useful": false,
+          "is_general_feedback": true,
+          "feedback": "very nice and useful website!"
+        }
+      """
+    And no feedback is stored
+    And no email is sent
+    And no feedback is posted to the webhook
+    And no feedback-received event is published
+
+
+  Scenario: Posting feedback faster than a person could fill in the form
+    Given I am authorised
+    When I POST "/feedback"
+      """
+        {
+          "is_page_useful": false,
+          "is_general_feedback": true,
+          "feedback": "very nice and useful website!",
+          "form_rendered_at": "2024-03-15T10:29:59Z"
+        }
+      """
+    Then the HTTP status code should be "201"
+    And no feedback is stored
+    And no email is sent
+
+
+  Scenario: Posting feedback after filling in the form
+    Given I am authorised
+    When I POST "/feedback"
+      """
+        {
+          "is_page_useful": false,
+          "is_general_feedback": true,
+          "feedback": "very nice and useful website!",
+          "form_rendered_at": "2024-03-15T10:29:00Z"
+        }
+      """
+    Then the HTTP status code should be "201"
+    And the following feedback is stored
+      """
+        {
+          "is_page_useful": false,
+          "is_general_feedback": true,
+          "feedback": "very nice and useful website!",
+          "form_rendered_at": "2024-03-15T10:29:00Z"
+        }
+      """
@@ -2,15 +2,30 @@ package steps
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"strconv"
+	"sync"
 	"time"
 
+	"github.com/ONSdigital/dp-api-clients-go/v2/identity"
 	componenttest "github.com/ONSdigital/dp-component-test"
+	"github.com/ONSdigital/dp-feedback-api/api"
 	"github.com/ONSdigital/dp-feedback-api/config"
+	"github.com/ONSdigital/dp-feedback-api/digest"
+	"github.com/ONSdigital/dp-feedback-api/duplicate"
+	"github.com/ONSdigital/dp-feedback-api/idempotency"
+	"github.com/ONSdigital/dp-feedback-api/ratelimit"
 	"github.com/ONSdigital/dp-feedback-api/service"
 	"github.com/ONSdigital/dp-feedback-api/service/mock"
+	"github.com/ONSdigital/dp-feedback-api/store"
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+	"github.com/ONSdigital/dp-kafka/v4/avro"
+	"github.com/ONSdigital/dp-kafka/v4/kafkatest"
+	dprequest "github.com/ONSdigital/dp-net/v3/request"
 )
 
 var (
@@ -19,6 +34,25 @@ var (
 	Version   = "component test version"
 )
 
+const (
+	// ServiceAuthToken is the token set by the 'I am authorised' step from dp-component-test
+	ServiceAuthToken = "SomeFakeToken"
+	// ServiceIdentifier is the identity of the caller for requests authorised with ServiceAuthToken
+	ServiceIdentifier = "component-test-service"
+)
+
+// WebhookSecret is the secret used to sign the requests to the webhook receiver
+const WebhookSecret = "component-test-secret"
+
+// ReceivedAt is the time assigned to all the feedback received during the component tests
+var ReceivedAt = time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+
+// webhookRequest is a request received by the webhook receiver
+type webhookRequest struct {
+	header http.Header
+	body   []byte
+}
+
 type Component struct {
 	componenttest.ErrorFeature
 	svc             *service.Service
@@ -26,8 +60,27 @@ type Component struct {
 	Config          *config.Config
 	HTTPServer      *http.Server
 	EmailSenderMock *mock.EmailSenderMock
+	IdentityMock    *mock.IdentityClientMock
+	StoreMock       *mock.FeedbackStoreMock
+	KafkaProducer   *kafkatest.IProducerMock
 	ServiceRunning  bool
 	apiFeature      *componenttest.APIFeature
+	// messages sent by the kafka producer mock, marshalled with their schema
+	kafkaMu       sync.Mutex
+	kafkaMessages [][]byte
+	// webhook receiver, which records the requests posted by the webhook notifier
+	webhookServer   *httptest.Server
+	webhookMu       sync.Mutex
+	webhookRequests []*webhookRequest
+	// rate limiter shared by all the requests of a scenario, as the service is initialised for every request
+	rateLimitCfg config.RateLimit
+	rateLimiter  service.RateLimiter
+	// idempotency cache and duplicate detector shared by all the requests of a scenario, for the same reason
+	idempotencyCache service.IdempotencyCache
+	duplicates       service.DuplicateDetector
+	// elapsed is added to ReceivedAt to give the current time of the digest scheduler, the rate limiter, the idempotency cache
+	// and the duplicate detector
+	elapsed time.Duration
 }
 
 func NewComponent() (*Component, error) {
@@ -41,10 +94,19 @@ func NewComponent() (*Component, error) {
 	c.Config, err = config.Get()
 	c.Config.FeedbackFrom = "sender@feedback.com"
 	c.Config.FeedbackTo = "receiver@feedback.com"
+	c.Config.FeedbackRoutesFile = "features/testdata/routes.json"
+	c.Config.FeedbackReaders = []string{ServiceIdentifier}
 	if err != nil {
 		return nil, err
 	}
 
+	c.webhookServer = httptest.NewServer(http.HandlerFunc(c.receiveWebhook))
+	c.Config.Notifiers = []string{config.NotifierEmail, config.NotifierWebhook}
+	c.Config.Webhook.URL = c.webhookServer.URL
+	c.Config.Webhook.Secret = WebhookSecret
+	c.Config.Kafka.Enabled = true
+	c.rateLimitCfg = *c.Config.RateLimit
+
 	c.apiFeature = componenttest.NewAPIFeature(c.Router)
 	c.setInitialiserMock()
 	c.svc = service.New()
@@ -74,10 +136,71 @@ func (c *Component) Close() error {
 		c.svc.Close(context.Background())
 		c.ServiceRunning = false
 	}
+	c.webhookServer.Close()
 	return nil
 }
 
+// receiveWebhook records the requests posted to the webhook receiver
+func (c *Component) receiveWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	c.webhookMu.Lock()
+	c.webhookRequests = append(c.webhookRequests, &webhookRequest{header: r.Header.Clone(), body: body})
+	c.webhookMu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// sendKafkaMessage records the provided event marshalled with its schema, as the kafka producer would publish it
+func (c *Component) sendKafkaMessage(ctx context.Context, s *avro.Schema, event interface{}) error {
+	msg, err := s.Marshal(event)
+	if err != nil {
+		return err
+	}
+	c.kafkaMu.Lock()
+	c.kafkaMessages = append(c.kafkaMessages, msg)
+	c.kafkaMu.Unlock()
+	return nil
+}
+
+// publishedKafkaMessages sends the events queued by the event publisher, as its worker is not started in the component tests,
+// and returns the messages sent by the kafka producer mock, in order
+func (c *Component) publishedKafkaMessages() ([][]byte, error) {
+	if c.svc.Events != nil {
+		if err := c.svc.Events.SendQueued(context.Background()); err != nil {
+			return nil, fmt.Errorf("failed to send queued events: %w", err)
+		}
+	}
+	c.kafkaMu.Lock()
+	defer c.kafkaMu.Unlock()
+	return append([][]byte(nil), c.kafkaMessages...), nil
+}
+
 func (c *Component) setInitialiserMock() {
+	// deterministic ids and timestamps, so that they can be validated: 'feedback-1', 'feedback-2', ...
+	idCount := 0
+	api.NewID = func() string {
+		idCount++
+		return fmt.Sprintf("feedback-%d", idCount)
+	}
+	api.Now = func() time.Time {
+		return ReceivedAt
+	}
+	digest.Now = func() time.Time {
+		return ReceivedAt.Add(c.elapsed)
+	}
+	ratelimit.Now = func() time.Time {
+		return ReceivedAt.Add(c.elapsed)
+	}
+	idempotency.Now = func() time.Time {
+		return ReceivedAt.Add(c.elapsed)
+	}
+	duplicate.Now = func() time.Time {
+		return ReceivedAt.Add(c.elapsed)
+	}
+
 	service.GetHTTPServer = func(bindAddr string, router http.Handler) service.HTTPServer {
 		return &http.Server{Addr: bindAddr, Handler: router} //nolint:gosec //Not live code
 	}
@@ -86,9 +209,93 @@ func (c *Component) setInitialiserMock() {
 		SendFunc: func(from string, to []string, msg []byte) error {
 			return nil
 		},
+		CloseFunc: func(ctx context.Context) error {
+			return nil
+		},
+	}
+	service.GetEmailSender = func(*config.Mail) (service.EmailSender, error) {
+		return c.EmailSenderMock, nil
+	}
+
+	// identity stand-in that only recognises the service token used by the component tests
+	c.IdentityMock = &mock.IdentityClientMock{
+		CheckRequestFunc: func(req *http.Request, florenceToken, serviceAuthToken string) (context.Context, int, identity.AuthFailure, error) {
+			if serviceAuthToken != ServiceAuthToken {
+				return req.Context(), http.StatusUnauthorized, errors.New("unexpected status code returned from AuthAPI"), nil
+			}
+			return dprequest.SetCaller(req.Context(), ServiceIdentifier), http.StatusOK, nil, nil
+		},
+		CheckerFunc: func(ctx context.Context, state *healthcheck.CheckState) error {
+			return state.Update(healthcheck.StatusOK, "zebedee is healthy", 0)
+		},
+	}
+	service.GetIdentityClient = func(*config.Config) service.IdentityClient {
+		return c.IdentityMock
+	}
+
+	// kafka producer mock, which records the published events so that they can be validated
+	c.KafkaProducer = &kafkatest.IProducerMock{
+		IsInitialisedFunc: func() bool {
+			return true
+		},
+		SendFunc: c.sendKafkaMessage,
+		CheckerFunc: func(ctx context.Context, state *healthcheck.CheckState) error {
+			return state.Update(healthcheck.StatusOK, "kafka producer is healthy", 0)
+		},
+		CloseFunc: func(ctx context.Context) error {
+			return nil
+		},
+	}
+	service.GetKafkaProducer = func(context.Context, *config.Kafka) (service.KafkaProducer, error) {
+		return c.KafkaProducer, nil
+	}
+
+	// the rate limiter is only created by the first initialisation, so that its buckets are kept between requests
+	service.GetRateLimiter = func(*config.RateLimit) (service.RateLimiter, error) {
+		if c.rateLimiter == nil {
+			rateLimiter, err := ratelimit.New(&c.rateLimitCfg)
+			if err != nil {
+				return nil, err
+			}
+			c.rateLimiter = rateLimiter
+		}
+		return c.rateLimiter, nil
+	}
+
+	// the idempotency cache is only created by the first initialisation, so that the responses are kept between requests
+	service.GetIdempotencyCache = func(window time.Duration) service.IdempotencyCache {
+		if c.idempotencyCache == nil {
+			c.idempotencyCache = idempotency.NewCache(window)
+		}
+		return c.idempotencyCache
+	}
+
+	// the duplicate detector is only created by the first initialisation, so that the recent submissions are kept between requests
+	service.GetDuplicateDetector = func(cfg *config.Duplicate) service.DuplicateDetector {
+		if c.duplicates == nil {
+			c.duplicates = duplicate.New(cfg)
+		}
+		return c.duplicates
+	}
+
+	// in-memory store, wrapped by a mock so that the stored feedback and queued emails can be validated
+	memStore := store.NewMemory()
+	c.StoreMock = &mock.FeedbackStoreMock{
+		AddFeedbackFunc:              memStore.AddFeedback,
+		GetFeedbackFunc:              memStore.GetFeedback,
+		AddOccurrenceFunc:            memStore.AddOccurrence,
+		GetFeedbackListFunc:          memStore.GetFeedbackList,
+		AddOutboxMessageFunc:         memStore.AddOutboxMessage,
+		UpdateOutboxMessageFunc:      memStore.UpdateOutboxMessage,
+		DeleteOutboxMessageFunc:      memStore.DeleteOutboxMessage,
+		GetPendingOutboxMessagesFunc: memStore.GetPendingOutboxMessages,
+		AddDigestEntryFunc:           memStore.AddDigestEntry,
+		GetDigestEntriesFunc:         memStore.GetDigestEntries,
+		DeleteDigestEntriesFunc:      memStore.DeleteDigestEntries,
+		CloseFunc:                    memStore.Close,
 	}
-	service.GetEmailSender = func(*config.Mail) service.EmailSender {
-		return c.EmailSenderMock
+	service.GetFeedbackStore = func(context.Context, *config.Store) (service.FeedbackStore, error) {
+		return c.StoreMock, nil
 	}
 }
 
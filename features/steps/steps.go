@@ -1,11 +1,22 @@
 package steps
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"strconv"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/mail"
 	"strings"
+	"time"
 
+	"github.com/ONSdigital/dp-feedback-api/models"
+	"github.com/ONSdigital/dp-feedback-api/schema"
+	"github.com/ONSdigital/dp-feedback-api/webhook"
 	"github.com/cucumber/godog"
 	"github.com/stretchr/testify/assert"
 )
@@ -13,57 +24,270 @@ import (
 func (c *Component) RegisterSteps(ctx *godog.ScenarioContext) {
 	c.apiFeature.RegisterSteps(ctx)
 
-	ctx.Step(`^I should receive a (\d+) status code with an empty body response`, c.iShouldReceiveAnEmptyResponse)
-	ctx.Step(`^I should receive a (\d+) status code with an the following body response$`, c.iShouldReceiveResponse)
+	ctx.Step(`^the mail server is unavailable$`, c.theMailServerIsUnavailable)
 	ctx.Step(`^the following email is sent$`, c.theFollowingEmailIsSent)
+	ctx.Step(`^the sent email HTML body contains "(.*)"$`, c.theSentEmailHTMLContains)
 	ctx.Step(`^no email is sent`, c.noEmailIsSent)
+	ctx.Step(`^(\d+) minutes have passed$`, c.minutesHavePassed)
+	ctx.Step(`^(\d+) emails? (?:is|are) waiting in the outbox to be retried$`, c.emailsAreWaitingInTheOutbox)
+	ctx.Step(`^the following feedback is posted to the webhook$`, c.theFollowingFeedbackIsPostedToTheWebhook)
+	ctx.Step(`^no feedback is posted to the webhook$`, c.noFeedbackIsPostedToTheWebhook)
+	ctx.Step(`^the following feedback-received event is published$`, c.theFollowingFeedbackReceivedEventIsPublished)
+	ctx.Step(`^no feedback-received event is published$`, c.noFeedbackReceivedEventIsPublished)
+	ctx.Step(`^the following feedback is stored$`, c.theFollowingFeedbackIsStored)
+	ctx.Step(`^no feedback is stored`, c.noFeedbackIsStored)
+	ctx.Step(`^the rate limit per client IP is (\d+) requests? per minute$`, c.theRateLimitPerClientIPIs)
+	ctx.Step(`^the allowed domains are "(.*)"$`, c.theAllowedDomainsAre)
+	ctx.Step(`^the feedback readers are "(.*)"$`, c.theFeedbackReadersAre)
+	ctx.Step(`^I should receive a list of (\d+) feedback items out of (\d+)$`, c.iShouldReceiveAFeedbackList)
 }
 
-func (c *Component) iShouldReceiveAnEmptyResponse(code string) error {
-	return c.iShouldReceiveResponse(
-		code,
-		&godog.DocString{
-			Content: "",
-		},
-	)
+// deliverQueuedEmails delivers the emails queued in the outbox, as the outbox worker is not started in the component tests
+func (c *Component) deliverQueuedEmails() error {
+	if err := c.svc.Outbox.DeliverPending(context.Background()); err != nil {
+		return fmt.Errorf("failed to deliver queued emails: %w", err)
+	}
+	return nil
+}
+
+// minutesHavePassed moves the clock of the digest scheduler forward, and sends the digests that are due,
+// as the digest scheduler is not started in the component tests
+func (c *Component) minutesHavePassed(minutes int) error {
+	c.elapsed += time.Duration(minutes) * time.Minute
+	if err := c.svc.Digests.SendDue(context.Background()); err != nil {
+		return fmt.Errorf("failed to send digests: %w", err)
+	}
+	return nil
+}
+
+// theRateLimitPerClientIPIs sets the number of requests per minute, and the burst, allowed for every client IP.
+// The rate limiter is created again with the new limits by the next request.
+func (c *Component) theRateLimitPerClientIPIs(requests int) error {
+	c.rateLimitCfg.IPPerMinute = requests
+	c.rateLimitCfg.IPBurst = requests
+	c.rateLimiter = nil
+	return nil
+}
+
+// theAllowedDomainsAre sets the comma-separated list of domains that feedback is allowed from, used by the next request
+func (c *Component) theAllowedDomainsAre(domains string) error {
+	c.Config.AllowedDomains = strings.Split(domains, ",")
+	return nil
+}
+
+// theFeedbackReadersAre sets the comma-separated list of the callers allowed to read the stored feedback, used by the next request
+func (c *Component) theFeedbackReadersAre(readers string) error {
+	c.Config.FeedbackReaders = strings.Split(readers, ",")
+	return nil
+}
+
+// deliverQueuedWebhooks posts the feedback queued by the webhook notifier, as its worker is not started in the component tests
+func (c *Component) deliverQueuedWebhooks() ([]*webhookRequest, error) {
+	if err := c.svc.Webhook.DeliverQueued(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to deliver queued webhooks: %w", err)
+	}
+	c.webhookMu.Lock()
+	defer c.webhookMu.Unlock()
+	return c.webhookRequests, nil
 }
 
-func (c *Component) iShouldReceiveResponse(code string, documentJSON *godog.DocString) error {
-	// Validate status code
-	statusCode := c.apiFeature.HTTPResponse.StatusCode
-	expectedCode, err := strconv.Atoi(code)
+func (c *Component) theMailServerIsUnavailable() error {
+	c.EmailSenderMock.SendFunc = func(from string, to []string, msg []byte) error {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+// theFollowingEmailIsSent checks the headers and the plain text part of the only email sent.
+// The expected email is provided as its headers, followed by a blank line and the expected text body.
+func (c *Component) theFollowingEmailIsSent(documentJSON *godog.DocString) error {
+	if err := c.deliverQueuedEmails(); err != nil {
+		return err
+	}
+	assert.Equal(c, len(c.EmailSenderMock.SendCalls()), 1)
+	if len(c.EmailSenderMock.SendCalls()) == 0 {
+		return c.StepError()
+	}
+
+	expected, err := mail.ReadMessage(strings.NewReader(trimLines(documentJSON.Content)))
+	if err != nil {
+		return fmt.Errorf("cannot parse expected email: %w", err)
+	}
+	expectedBody, err := io.ReadAll(expected.Body)
 	if err != nil {
-		return fmt.Errorf("cannot parse expected code: %w", err)
+		return fmt.Errorf("cannot read expected email body: %w", err)
 	}
-	assert.Equal(c, expectedCode, statusCode)
 
-	// Validate body
-	var expectedBody = trimLines(documentJSON.Content)
-	responseBody := c.apiFeature.HTTPResponse.Body
-	body, err := io.ReadAll(responseBody)
+	sent, err := mail.ReadMessage(bytes.NewReader(c.EmailSenderMock.SendCalls()[0].Msg))
 	if err != nil {
-		return fmt.Errorf("cannot read body from response: %w", err)
+		return fmt.Errorf("cannot parse sent email: %w", err)
+	}
+	for name := range expected.Header {
+		assert.Equal(c, expected.Header.Get(name), sent.Header.Get(name), name)
 	}
-	assert.Equal(c, expectedBody, trimLines(string(body)))
+	text, err := emailPart(sent, "text/plain")
+	if err != nil {
+		return err
+	}
+	assert.Equal(c, string(expectedBody), trimLines(text))
 
 	return c.StepError()
 }
 
-func (c *Component) theFollowingEmailIsSent(documentJSON *godog.DocString) error {
+func (c *Component) theSentEmailHTMLContains(expected string) error {
+	if err := c.deliverQueuedEmails(); err != nil {
+		return err
+	}
 	assert.Equal(c, len(c.EmailSenderMock.SendCalls()), 1)
+	if len(c.EmailSenderMock.SendCalls()) == 0 {
+		return c.StepError()
+	}
 
-	var expectedEmail = trimLines(documentJSON.Content)
-	sentEmail := c.EmailSenderMock.SendCalls()[0].Msg
-	assert.Equal(c, expectedEmail, trimLines(string(sentEmail)))
+	sent, err := mail.ReadMessage(bytes.NewReader(c.EmailSenderMock.SendCalls()[0].Msg))
+	if err != nil {
+		return fmt.Errorf("cannot parse sent email: %w", err)
+	}
+	html, err := emailPart(sent, "text/html")
+	if err != nil {
+		return err
+	}
+	assert.Contains(c, html, expected)
 
 	return c.StepError()
 }
 
 func (c *Component) noEmailIsSent() error {
+	if err := c.deliverQueuedEmails(); err != nil {
+		return err
+	}
 	assert.Equal(c, len(c.EmailSenderMock.SendCalls()), 0)
 	return c.StepError()
 }
 
+func (c *Component) emailsAreWaitingInTheOutbox(count int) error {
+	if err := c.deliverQueuedEmails(); err != nil {
+		return err
+	}
+
+	// failed emails are retried later, so they are not pending straight away
+	msgs, err := c.StoreMock.GetPendingOutboxMessages(context.Background(), time.Now().Add(24*time.Hour), 0)
+	if err != nil {
+		return fmt.Errorf("failed to get queued emails: %w", err)
+	}
+	assert.Equal(c, count, len(msgs))
+	for _, msg := range msgs {
+		assert.Equal(c, 1, msg.Attempts)
+		assert.Equal(c, "connection refused", msg.LastError)
+	}
+
+	return c.StepError()
+}
+
+func (c *Component) theFollowingFeedbackIsPostedToTheWebhook(documentJSON *godog.DocString) error {
+	requests, err := c.deliverQueuedWebhooks()
+	if err != nil {
+		return err
+	}
+	if len(requests) != 1 {
+		return fmt.Errorf("expected 1 webhook request, got %d", len(requests))
+	}
+	req := requests[0]
+
+	expected := &models.Feedback{}
+	if err := json.Unmarshal([]byte(documentJSON.Content), expected); err != nil {
+		return fmt.Errorf("cannot unmarshal expected feedback: %w", err)
+	}
+	payload := &webhook.Payload{}
+	if err := json.Unmarshal(req.body, payload); err != nil {
+		return fmt.Errorf("cannot unmarshal webhook payload: %w", err)
+	}
+
+	assert.Equal(c, webhook.EventFeedbackReceived, payload.Event)
+	assert.Equal(c, expected, payload.Feedback)
+	assert.Equal(c, webhook.Sign(WebhookSecret, req.body), req.header.Get(webhook.SignatureHeader))
+
+	return c.StepError()
+}
+
+func (c *Component) noFeedbackIsPostedToTheWebhook() error {
+	requests, err := c.deliverQueuedWebhooks()
+	if err != nil {
+		return err
+	}
+	assert.Equal(c, 0, len(requests))
+	return c.StepError()
+}
+
+func (c *Component) theFollowingFeedbackReceivedEventIsPublished(documentJSON *godog.DocString) error {
+	messages, err := c.publishedKafkaMessages()
+	if err != nil {
+		return err
+	}
+	if len(messages) != 1 {
+		return fmt.Errorf("expected 1 kafka message, got %d", len(messages))
+	}
+
+	expected := &models.FeedbackReceived{}
+	if err := json.Unmarshal([]byte(documentJSON.Content), expected); err != nil {
+		return fmt.Errorf("cannot unmarshal expected event: %w", err)
+	}
+	published := &models.FeedbackReceived{}
+	if err := schema.FeedbackReceivedEvent.Unmarshal(messages[0], published); err != nil {
+		return fmt.Errorf("cannot unmarshal published event: %w", err)
+	}
+	assert.Equal(c, expected, published)
+
+	return c.StepError()
+}
+
+func (c *Component) noFeedbackReceivedEventIsPublished() error {
+	messages, err := c.publishedKafkaMessages()
+	if err != nil {
+		return err
+	}
+	assert.Equal(c, 0, len(messages))
+	return c.StepError()
+}
+
+func (c *Component) theFollowingFeedbackIsStored(documentJSON *godog.DocString) error {
+	assert.Equal(c, 1, len(c.StoreMock.AddFeedbackCalls()))
+
+	expected := &models.Feedback{}
+	if err := json.Unmarshal([]byte(documentJSON.Content), expected); err != nil {
+		return fmt.Errorf("cannot unmarshal expected feedback: %w", err)
+	}
+
+	stored := *c.StoreMock.AddFeedbackCalls()[0].F
+	assert.NotEmpty(c, stored.ID)
+	assert.NotNil(c, stored.ReceivedAt)
+
+	// ID and received timestamp are generated, so they are ignored in the comparison
+	stored.ID = ""
+	stored.ReceivedAt = nil
+	assert.Equal(c, expected, &stored)
+
+	return c.StepError()
+}
+
+func (c *Component) noFeedbackIsStored() error {
+	assert.Equal(c, 0, len(c.StoreMock.AddFeedbackCalls()))
+	return c.StepError()
+}
+
+func (c *Component) iShouldReceiveAFeedbackList(count, totalCount int) error {
+	assert.Equal(c, http.StatusOK, c.apiFeature.HTTPResponse.StatusCode)
+
+	list := &models.FeedbackList{}
+	if err := json.NewDecoder(c.apiFeature.HTTPResponse.Body).Decode(list); err != nil {
+		return fmt.Errorf("cannot decode feedback list from response: %w", err)
+	}
+	assert.Equal(c, count, list.Count)
+	assert.Equal(c, count, len(list.Items))
+	assert.Equal(c, totalCount, list.TotalCount)
+
+	return c.StepError()
+}
+
 func trimLines(in string) string {
 	var sb strings.Builder
 	for _, line := range strings.Split(strings.TrimSpace(in), "\n") {
@@ -72,3 +296,23 @@ func trimLines(in string) string {
 	}
 	return sb.String()
 }
+
+// emailPart returns the decoded content of the part of the multipart email with the provided media type
+func emailPart(msg *mail.Message, mediaType string) (string, error) {
+	_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		return "", fmt.Errorf("cannot parse email content type: %w", err)
+	}
+
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err != nil {
+			return "", fmt.Errorf("cannot find %s part in email: %w", mediaType, err)
+		}
+		if strings.HasPrefix(part.Header.Get("Content-Type"), mediaType) {
+			b, err := io.ReadAll(part)
+			return strings.ReplaceAll(string(b), "\r\n", "\n"), err
+		}
+	}
+}
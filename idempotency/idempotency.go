@@ -0,0 +1,115 @@
+// Package idempotency caches the responses to the requests sent with an idempotency key for a window of time,
+// so that the retries of a request get its original response instead of being processed again
+package idempotency
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Now returns the current time, used to expire the cached responses
+var Now = time.Now
+
+// sweepInterval is the minimum time between the removals of the expired entries
+const sweepInterval = time.Minute
+
+var (
+	// ErrInProgress is returned when a request with the same key is still being processed
+	ErrInProgress = errors.New("a request with the same idempotency key is in progress")
+	// ErrKeyReused is returned when the key was used by a request with a different fingerprint
+	ErrKeyReused = errors.New("the idempotency key was already used by a different request")
+)
+
+// Response is a response cached for an idempotency key
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+type entry struct {
+	fingerprint string
+	response    *Response // nil while the request is in progress
+	expiresAt   time.Time
+}
+
+// Cache keeps the responses to the requests with an idempotency key in memory, for the configured window
+// since they were first received
+type Cache struct {
+	window time.Duration
+
+	mu        sync.Mutex
+	entries   map[string]*entry
+	lastSweep time.Time
+}
+
+// NewCache returns a Cache that keeps every response for the provided window
+func NewCache(window time.Duration) *Cache {
+	return &Cache{
+		window:  window,
+		entries: map[string]*entry{},
+	}
+}
+
+// Begin starts a request with the provided key, and the fingerprint of its content, e.g. a hash of its body.
+// If a previous request with the same key and fingerprint has completed, it returns its response, which must be
+// returned instead of processing the request again. Otherwise, the key is reserved until the request is completed or cancelled.
+// It fails with ErrInProgress if a request with the same key is still in progress,
+// and with ErrKeyReused if the key was used by a request with a different fingerprint.
+func (c *Cache) Begin(key, fingerprint string) (*Response, error) {
+	now := Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sweep(now)
+
+	e, ok := c.entries[key]
+	if !ok || !now.Before(e.expiresAt) {
+		c.entries[key] = &entry{fingerprint: fingerprint, expiresAt: now.Add(c.window)}
+		return nil, nil
+	}
+	if e.fingerprint != fingerprint {
+		return nil, ErrKeyReused
+	}
+	if e.response == nil {
+		return nil, ErrInProgress
+	}
+	return e.response, nil
+}
+
+// Complete caches the response to the request started with the provided key
+func (c *Cache) Complete(key string, resp *Response) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok {
+		e.response = resp
+	}
+}
+
+// Cancel releases the key of a request that did not complete, so that it can be retried
+func (c *Cache) Cancel(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok && e.response == nil {
+		delete(c.entries, key)
+	}
+}
+
+// sweep removes the expired entries, so that the keys that are not retried do not accumulate.
+// It runs at most once per sweepInterval.
+func (c *Cache) sweep(now time.Time) {
+	if now.Sub(c.lastSweep) < sweepInterval {
+		return
+	}
+	for key, e := range c.entries {
+		if !now.Before(e.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+	c.lastSweep = now
+}
@@ -0,0 +1,89 @@
+package idempotency_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-feedback-api/idempotency"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var testTime = time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+
+// setClock makes the caches use a clock that starts at testTime, and returns a function to move it forward
+func setClock(t *testing.T) func(d time.Duration) {
+	now := idempotency.Now
+	t.Cleanup(func() { idempotency.Now = now })
+
+	current := testTime
+	idempotency.Now = func() time.Time { return current }
+	return func(d time.Duration) { current = current.Add(d) }
+}
+
+func TestCache(t *testing.T) {
+	Convey("Given a cache with a window of 1 hour", t, func() {
+		advance := setClock(t)
+		c := idempotency.NewCache(time.Hour)
+		resp := &idempotency.Response{Status: http.StatusCreated, Header: http.Header{"Location": {"/feedback/1"}}, Body: []byte(`{"id":"1"}`)}
+
+		Convey("When a request is begun with a new key", func() {
+			cached, err := c.Begin("key", "fingerprint")
+
+			Convey("Then it has no cached response", func() {
+				So(err, ShouldBeNil)
+				So(cached, ShouldBeNil)
+			})
+
+			Convey("Then a request with the same key fails while it is in progress", func() {
+				_, err := c.Begin("key", "fingerprint")
+				So(err, ShouldEqual, idempotency.ErrInProgress)
+			})
+
+			Convey("Then a request with a different key is not affected", func() {
+				cached, err := c.Begin("other", "fingerprint")
+				So(err, ShouldBeNil)
+				So(cached, ShouldBeNil)
+			})
+
+			Convey("And it is completed", func() {
+				c.Complete("key", resp)
+
+				Convey("Then a retry with the same key gets the cached response", func() {
+					cached, err := c.Begin("key", "fingerprint")
+					So(err, ShouldBeNil)
+					So(cached, ShouldEqual, resp)
+				})
+
+				Convey("Then a request with the same key and a different fingerprint fails", func() {
+					_, err := c.Begin("key", "other fingerprint")
+					So(err, ShouldEqual, idempotency.ErrKeyReused)
+				})
+
+				Convey("Then a request with the same key is processed again once the window has passed", func() {
+					advance(time.Hour)
+					cached, err := c.Begin("key", "other fingerprint")
+					So(err, ShouldBeNil)
+					So(cached, ShouldBeNil)
+				})
+
+				Convey("Then it is not released by a cancellation", func() {
+					c.Cancel("key")
+					cached, err := c.Begin("key", "fingerprint")
+					So(err, ShouldBeNil)
+					So(cached, ShouldEqual, resp)
+				})
+			})
+
+			Convey("And it is cancelled", func() {
+				c.Cancel("key")
+
+				Convey("Then a retry with the same key is processed again", func() {
+					cached, err := c.Begin("key", "fingerprint")
+					So(err, ShouldBeNil)
+					So(cached, ShouldBeNil)
+				})
+			})
+		})
+	})
+}
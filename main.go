@@ -51,6 +51,9 @@ func run(ctx context.Context) error {
 	if err != nil {
 		return errors.Wrap(err, "error getting configuration")
 	}
+	if err := cfg.Validate(); err != nil {
+		return errors.Wrap(err, "invalid configuration")
+	}
 	log.Info(ctx, "config on startup", log.Data{"config": cfg, "build_time": BuildTime, "git-commit": GitCommit})
 
 	// Make sure that context is cancelled when 'run' finishes its execution.
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// DigestEntry is feedback waiting to be included in the next digest email of a route
+type DigestEntry struct {
+	ID       string    `json:"id"`
+	Route    string    `json:"route"`
+	Feedback *Feedback `json:"feedback"`
+	AddedAt  time.Time `json:"added_at"`
+}
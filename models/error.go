@@ -0,0 +1,105 @@
+package models
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Error codes returned in error responses
+const (
+	ErrCodeInvalidRequest   = "invalid_request"
+	ErrCodeValidationFailed = "validation_failed"
+	ErrCodeUnauthorised     = "unauthorised"
+	ErrCodeForbidden        = "forbidden"
+	ErrCodeNotFound         = "not_found"
+	ErrCodeConflict         = "conflict"
+	ErrCodeUnprocessable    = "unprocessable_request"
+	ErrCodeTooManyRequests  = "too_many_requests"
+	ErrCodeInternal         = "internal_error"
+)
+
+// ErrorResponse represents the JSON body returned by the API when a request fails
+type ErrorResponse struct {
+	Code    string       `json:"code"`
+	Message string       `json:"message"`
+	Errors  []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError represents a validation rule that a field of the request body did not satisfy, and the reason why, if known
+type FieldError struct {
+	Field    string `json:"field"`
+	JSONName string `json:"json_name"`
+	Rule     string `json:"rule"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// NewErrorResponse creates an error response for the provided error and HTTP status code.
+// If the error contains validation errors for the Feedback model, they are included as field errors.
+func NewErrorResponse(err error, status int) *ErrorResponse {
+	resp := &ErrorResponse{
+		Code:    errorCode(status),
+		Message: err.Error(),
+	}
+
+	var validationErrs validator.ValidationErrors
+	var reasons map[string]string
+	var feedbackErr *ValidationError
+	if errors.As(err, &feedbackErr) {
+		reasons = feedbackErr.Reasons
+	}
+	if errors.As(err, &validationErrs) {
+		resp.Code = ErrCodeValidationFailed
+		resp.Message = "request body failed validation"
+		resp.Errors = make([]FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			resp.Errors = append(resp.Errors, FieldError{
+				Field:    fe.StructField(),
+				JSONName: jsonFieldName(reflect.TypeOf(Feedback{}), fe.StructField()),
+				Rule:     fe.Tag(),
+				Reason:   reasons[fe.StructField()],
+			})
+		}
+	}
+
+	return resp
+}
+
+// errorCode returns the error code corresponding to the provided HTTP status code
+func errorCode(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return ErrCodeInvalidRequest
+	case http.StatusUnauthorized:
+		return ErrCodeUnauthorised
+	case http.StatusForbidden:
+		return ErrCodeForbidden
+	case http.StatusNotFound:
+		return ErrCodeNotFound
+	case http.StatusConflict:
+		return ErrCodeConflict
+	case http.StatusUnprocessableEntity:
+		return ErrCodeUnprocessable
+	case http.StatusTooManyRequests:
+		return ErrCodeTooManyRequests
+	default:
+		return ErrCodeInternal
+	}
+}
+
+// jsonFieldName returns the name of the provided struct field in its JSON representation,
+// or the struct field name if it does not have a json tag
+func jsonFieldName(t reflect.Type, structField string) string {
+	f, ok := t.FieldByName(structField)
+	if !ok {
+		return structField
+	}
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return structField
+	}
+	return name
+}
@@ -0,0 +1,49 @@
+package models_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/ONSdigital/dp-feedback-api/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNewErrorResponse(t *testing.T) {
+	Convey("Given a Feedback model that fails validation for more than one field", t, func() {
+		f := validFeedbackModel()
+		f.OnsURL = "https://somedomain/sub/path"
+		f.IsPageUseful = nil
+		err := fmt.Errorf("wrapped: %w", f.Validate(cfg))
+
+		Convey("Then the error response contains a field error for each failed field, with the reason why, if known", func() {
+			resp := models.NewErrorResponse(err, http.StatusBadRequest)
+			So(resp, ShouldResemble, &models.ErrorResponse{
+				Code:    models.ErrCodeValidationFailed,
+				Message: "request body failed validation",
+				Errors: []models.FieldError{
+					{Field: "IsPageUseful", JSONName: "is_page_useful", Rule: "required"},
+					{Field: "OnsURL", JSONName: "ons_url", Rule: "ons_url", Reason: "host 'somedomain' does not match any allowed domain"},
+				},
+			})
+		})
+	})
+
+	Convey("Given an error that is not a validation error", t, func() {
+		err := errors.New("something went wrong")
+
+		Convey("Then the error response contains its message and the code corresponding to the status", func() {
+			So(models.NewErrorResponse(err, http.StatusBadRequest), ShouldResemble, &models.ErrorResponse{
+				Code: models.ErrCodeInvalidRequest, Message: "something went wrong",
+			})
+			So(models.NewErrorResponse(err, http.StatusUnauthorized).Code, ShouldEqual, models.ErrCodeUnauthorised)
+			So(models.NewErrorResponse(err, http.StatusForbidden).Code, ShouldEqual, models.ErrCodeForbidden)
+			So(models.NewErrorResponse(err, http.StatusNotFound).Code, ShouldEqual, models.ErrCodeNotFound)
+			So(models.NewErrorResponse(err, http.StatusConflict).Code, ShouldEqual, models.ErrCodeConflict)
+			So(models.NewErrorResponse(err, http.StatusUnprocessableEntity).Code, ShouldEqual, models.ErrCodeUnprocessable)
+			So(models.NewErrorResponse(err, http.StatusTooManyRequests).Code, ShouldEqual, models.ErrCodeTooManyRequests)
+			So(models.NewErrorResponse(err, http.StatusInternalServerError).Code, ShouldEqual, models.ErrCodeInternal)
+		})
+	})
+}
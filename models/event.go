@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// FeedbackReceived is the event published to Kafka for every feedback submission, including positive votes.
+// The contact details of the user are not included.
+type FeedbackReceived struct {
+	ID                string `avro:"id" json:"id"`
+	ReceivedAt        string `avro:"received_at" json:"received_at"`
+	IsPageUseful      bool   `avro:"is_page_useful" json:"is_page_useful"`
+	IsGeneralFeedback bool   `avro:"is_general_feedback" json:"is_general_feedback"`
+	OnsURL            string `avro:"ons_url" json:"ons_url"`
+	Feedback          string `avro:"feedback" json:"feedback"`
+}
+
+// NewFeedbackReceived returns the feedback-received event for the provided feedback
+func NewFeedbackReceived(f *Feedback) *FeedbackReceived {
+	e := &FeedbackReceived{
+		ID:       f.ID,
+		OnsURL:   f.OnsURL,
+		Feedback: f.Feedback,
+	}
+	if f.ReceivedAt != nil {
+		e.ReceivedAt = f.ReceivedAt.UTC().Format(time.RFC3339)
+	}
+	if f.IsPageUseful != nil {
+		e.IsPageUseful = *f.IsPageUseful
+	}
+	if f.IsGeneralFeedback != nil {
+		e.IsGeneralFeedback = *f.IsGeneralFeedback
+	}
+	return e
+}
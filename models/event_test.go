@@ -0,0 +1,38 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-feedback-api/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNewFeedbackReceived(t *testing.T) {
+	Convey("The feedback-received event contains the feedback without the contact details of the user", t, func() {
+		isPageUseful, isGeneralFeedback := false, true
+		receivedAt := time.Date(2024, 3, 15, 11, 30, 0, 0, time.FixedZone("BST", 3600))
+		e := models.NewFeedbackReceived(&models.Feedback{
+			ID:                "feedback-1",
+			ReceivedAt:        &receivedAt,
+			IsPageUseful:      &isPageUseful,
+			IsGeneralFeedback: &isGeneralFeedback,
+			OnsURL:            "https://localhost/subpath",
+			Feedback:          "broken link",
+			Name:              "Mr Reporter",
+			EmailAddress:      "feedback@reporter.com",
+		})
+		So(e, ShouldResemble, &models.FeedbackReceived{
+			ID:                "feedback-1",
+			ReceivedAt:        "2024-03-15T10:30:00Z",
+			IsPageUseful:      false,
+			IsGeneralFeedback: true,
+			OnsURL:            "https://localhost/subpath",
+			Feedback:          "broken link",
+		})
+	})
+
+	Convey("Missing values are left empty in the feedback-received event", t, func() {
+		So(models.NewFeedbackReceived(&models.Feedback{ID: "feedback-1"}), ShouldResemble, &models.FeedbackReceived{ID: "feedback-1"})
+	})
+}
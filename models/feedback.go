@@ -1,43 +1,133 @@
 package models
 
 import (
-	"context"
+	"errors"
 	"fmt"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/ONSdigital/dp-feedback-api/config"
-	"github.com/ONSdigital/log.go/v2/log"
 	"github.com/go-playground/validator/v10"
 )
 
+// WholeSite is the ons_url value provided when the feedback is about the whole website rather than a page
+const WholeSite = "The whole website"
+
+// Feedback represents a feedback submission. ID and ReceivedAt are assigned by the API when the submission is received.
+// Honeypot and FormRenderedAt are provided by the feedback form to detect the submissions sent by bots.
+// Occurrences is the number of times the feedback was submitted, counting its near-duplicates. It is only set once a near-duplicate is received.
 type Feedback struct {
-	IsPageUseful      *bool  `json:"is_page_useful"         validate:"required"`
-	IsGeneralFeedback *bool  `json:"is_general_feedback"    validate:"required"`
-	OnsURL            string `json:"ons_url,omitempty"      validate:"omitempty,ons_url"`
-	Feedback          string `json:"feedback,omitempty"`
-	Name              string `json:"name,omitempty"`
-	EmailAddress      string `json:"email_address,omitempty" validate:"omitempty,email"`
+	ID                string      `json:"id,omitempty"`
+	ReceivedAt        *time.Time  `json:"received_at,omitempty"`
+	IsPageUseful      *bool       `json:"is_page_useful"         validate:"required"`
+	IsGeneralFeedback *bool       `json:"is_general_feedback"    validate:"required"`
+	OnsURL            string      `json:"ons_url,omitempty"      validate:"omitempty,ons_url"`
+	Feedback          string      `json:"feedback,omitempty"`
+	Name              string      `json:"name,omitempty"`
+	EmailAddress      string      `json:"email_address,omitempty" validate:"omitempty,email"`
+	Honeypot          string      `json:"honeypot,omitempty"`
+	FormRenderedAt    *time.Time  `json:"form_rendered_at,omitempty"`
+	Spam              *Spam       `json:"spam,omitempty"`
+	Redactions        []Redaction `json:"redactions,omitempty"`
+	Occurrences       int         `json:"occurrences,omitempty"`
+}
+
+// Spam is the result of the spam checks of a feedback submission, assigned by the API when the submission is received
+type Spam struct {
+	Score   int      `json:"score"`
+	Reasons []string `json:"reasons,omitempty"`
+	Action  string   `json:"action,omitempty"`
+}
+
+// Actions taken with feedback according to its spam score
+const (
+	// SpamActionTag notifies of the feedback as possible spam
+	SpamActionTag = "tag"
+	// SpamActionQuarantine stores the feedback without notifying of it
+	SpamActionQuarantine = "quarantine"
+	// SpamActionReject rejects the feedback without storing it
+	SpamActionReject = "reject"
+)
+
+// IsQuarantined returns true if the feedback was quarantined as spam, so it must not be notified
+func (f *Feedback) IsQuarantined() bool {
+	return f.Spam != nil && f.Spam.Action == SpamActionQuarantine
 }
 
-var cfg *config.Config
+// IsPossibleSpam returns true if the feedback was tagged as possible spam
+func (f *Feedback) IsPossibleSpam() bool {
+	return f.Spam != nil && f.Spam.Action == SpamActionTag
+}
+
+// ClearAssigned clears the fields that are assigned by the API, so that they cannot be provided by the submitter
+func (f *Feedback) ClearAssigned() {
+	f.ID = ""
+	f.ReceivedAt = nil
+	f.Spam = nil
+	f.Redactions = nil
+	f.Occurrences = 0
+}
 
-// getURLDomainValidator returns a validator func that checks that a field contains
-// a valid URL with a hostname that ends with the provided domain
-func getURLDomainValidator(onsDomain string) validator.Func {
-	return func(fl validator.FieldLevel) bool {
-		urlField := fl.Field().String()
-		return IsSiteDomainURL(urlField, onsDomain)
+// AddOccurrence counts a near-duplicate of the feedback as one more occurrence of it
+func (f *Feedback) AddOccurrence() {
+	if f.Occurrences == 0 {
+		f.Occurrences = 1
 	}
+	f.Occurrences++
 }
 
-// Validate checks that the Feedback struct complies with the validation tags
+// ValidationError is returned when the Feedback model fails validation, with the reasons why some of its fields
+// did not satisfy their rules, by struct field, e.g. the allowed domain that rejected its ons_url
+type ValidationError struct {
+	validator.ValidationErrors
+	Reasons map[string]string
+}
+
+// Error returns the validation errors, each followed by its reason, if any
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.ValidationErrors))
+	for _, fe := range e.ValidationErrors {
+		msg := fe.Error()
+		if reason := e.Reasons[fe.StructField()]; reason != "" {
+			msg += ": " + reason
+		}
+		msgs = append(msgs, msg)
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the validation errors
+func (e *ValidationError) Unwrap() error {
+	return e.ValidationErrors
+}
+
+// Validate checks that the Feedback struct complies with the validation tags, and that its ons_url is allowed by the domain rules of the provided config,
+// unless it is WholeSite, which is not a URL
 func (f *Feedback) Validate(cfg *config.Config) error {
+	rules, err := cfg.DomainRules()
+	if err != nil {
+		return fmt.Errorf("failed to get allowed domains: %w", err)
+	}
+
+	var urlErr error
 	validate := validator.New()
-	if err := validate.RegisterValidation("ons_url", getURLDomainValidator(cfg.OnsDomain)); err != nil {
+	if err := validate.RegisterValidation("ons_url", func(fl validator.FieldLevel) bool {
+		if fl.Field().String() == WholeSite {
+			return true
+		}
+		urlErr = CheckSiteURL(fl.Field().String(), rules)
+		return urlErr == nil
+	}); err != nil {
 		return fmt.Errorf("failed to register ons_url validator: %w", err)
 	}
-	return validate.Struct(f)
+
+	err = validate.Struct(f)
+	var validationErrs validator.ValidationErrors
+	if urlErr != nil && errors.As(err, &validationErrs) {
+		return &ValidationError{ValidationErrors: validationErrs, Reasons: map[string]string{"OnsURL": urlErr.Error()}}
+	}
+	return err
 }
 
 // Sanitize mutates all the strings in Feedback to prevent HTML, SQL and NoSQL injections,
@@ -49,32 +139,53 @@ func (f *Feedback) Sanitize(cfg *config.Sanitize) {
 	f.EmailAddress = Sanitize(cfg, f.EmailAddress)
 }
 
-// IsSiteDomainURL is true when urlString is a URL and its host ends with `.`+siteDomain (when siteDomain is blank, or uses config.SiteDomain)
-func IsSiteDomainURL(urlString, siteDomain string) bool {
-	ctx := context.Background()
+// CheckSiteURL returns nil if the provided string is a URL allowed by any of the provided domain rules,
+// or an error describing why it is not allowed: it is not a URL, its host does not match any rule,
+// or its path is not allowed by the first rule that matches its host
+func CheckSiteURL(urlString string, rules []*config.DomainRule) error {
 	if urlString == "" {
-		return false
+		return errors.New("it is empty")
 	}
-	urlString = NormaliseURL(urlString)
-	urlObject, err := url.ParseRequestURI(urlString)
-	if err != nil {
-		log.Error(ctx, "error parsing URL", err)
-		return false
+	urlObject, err := url.ParseRequestURI(NormaliseURL(urlString))
+	if err != nil || urlObject.Hostname() == "" {
+		return errors.New("it is not a valid URL")
 	}
+
+	host, path := urlObject.Hostname(), urlObject.Path
+	var rejectedBy *config.DomainRule
+	for _, rule := range rules {
+		if !rule.MatchesHost(host) {
+			continue
+		}
+		if rule.AllowsPath(path) {
+			return nil
+		}
+		if rejectedBy == nil {
+			rejectedBy = rule
+		}
+	}
+	if rejectedBy != nil {
+		return fmt.Errorf("path '%s' is not allowed for domain '%s'", path, rejectedBy)
+	}
+	return fmt.Errorf("host '%s' does not match any allowed domain", host)
+}
+
+// IsSiteDomainURL is true when urlString is a URL and its host is siteDomain or ends with `.`+siteDomain (when siteDomain is blank, uses config.OnsDomain)
+//
+// Deprecated: use CheckSiteURL with the domain rules of the config, which also supports several domains and path allow-lists.
+func IsSiteDomainURL(urlString, siteDomain string) bool {
 	if siteDomain == "" {
-		if cfg == nil {
-			if cfg, err = config.Get(); err != nil {
-				log.Error(ctx, "error getting config", err)
-				return false
-			}
+		cfg, err := config.Get()
+		if err != nil {
+			return false
 		}
 		siteDomain = cfg.OnsDomain
 	}
-	hostName := urlObject.Hostname()
-	if hostName != siteDomain && !strings.HasSuffix(hostName, "."+siteDomain) {
+	rules, err := config.ParseDomainRules([]string{siteDomain, "*." + siteDomain})
+	if err != nil {
 		return false
 	}
-	return true
+	return CheckSiteURL(urlString, rules) == nil
 }
 
 // NormaliseURL when a string is a URL without a scheme (e.g. `host.name/path`), add it (`https://`)
@@ -63,7 +63,7 @@ func TestValidate(t *testing.T) {
 		Convey("Then the validation fails with the expected error", func() {
 			err := f.Validate(cfg)
 			So(err, ShouldNotBeNil)
-			So(err.Error(), ShouldEqual, "Key: 'Feedback.OnsURL' Error:Field validation for 'OnsURL' failed on the 'ons_url' tag")
+			So(err.Error(), ShouldEqual, "Key: 'Feedback.OnsURL' Error:Field validation for 'OnsURL' failed on the 'ons_url' tag: host 'somedomain' does not match any allowed domain")
 		})
 	})
 
@@ -74,7 +74,7 @@ func TestValidate(t *testing.T) {
 		Convey("Then the validation fails with the expected error", func() {
 			err := f.Validate(cfg)
 			So(err, ShouldNotBeNil)
-			So(err.Error(), ShouldEqual, "Key: 'Feedback.OnsURL' Error:Field validation for 'OnsURL' failed on the 'ons_url' tag")
+			So(err.Error(), ShouldEqual, "Key: 'Feedback.OnsURL' Error:Field validation for 'OnsURL' failed on the 'ons_url' tag: host 'www.somedomain' does not match any allowed domain")
 		})
 	})
 
@@ -118,7 +118,23 @@ func TestValidate(t *testing.T) {
 		Convey("Then the validation fails with the expected error", func() {
 			err := f.Validate(cfg)
 			So(err, ShouldNotBeNil)
-			So(err.Error(), ShouldEqual, "Key: 'Feedback.OnsURL' Error:Field validation for 'OnsURL' failed on the 'ons_url' tag")
+			So(err.Error(), ShouldEqual, "Key: 'Feedback.OnsURL' Error:Field validation for 'OnsURL' failed on the 'ons_url' tag: it is not a valid URL")
+		})
+	})
+
+	Convey("Given a Feedback model about the whole website", t, func() {
+		f := validFeedbackModel()
+		f.OnsURL = models.WholeSite
+
+		Convey("Then validation is successful, as its 'ons_url' is not checked against the allowed domains", func() {
+			So(f.Validate(cfg), ShouldBeNil)
+		})
+
+		Convey("Then the validation fails if 'is_page_useful' is not provided", func() {
+			f.IsPageUseful = nil
+			err := f.Validate(cfg)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "Key: 'Feedback.IsPageUseful' Error:Field validation for 'IsPageUseful' failed on the 'required' tag")
 		})
 	})
 
@@ -129,11 +145,63 @@ func TestValidate(t *testing.T) {
 		Convey("Then the validation fails with the expected error", func() {
 			err := f.Validate(cfg)
 			So(err, ShouldNotBeNil)
-			So(err.Error(), ShouldEqual, "Key: 'Feedback.OnsURL' Error:Field validation for 'OnsURL' failed on the 'ons_url' tag")
+			So(err.Error(), ShouldEqual, "Key: 'Feedback.OnsURL' Error:Field validation for 'OnsURL' failed on the 'ons_url' tag: host 'attackerHost' does not match any allowed domain")
+		})
+	})
+}
+
+func TestValidateAllowedDomains(t *testing.T) {
+	Convey("Given a config with several allowed domains, some of them with a path allow-list", t, func() {
+		cfg := &config.Config{
+			OnsDomain:      "ons.gov.uk",
+			AllowedDomains: []string{"www.ons.gov.uk", "*.beta.ons.gov.uk", "census.gov.uk/2021", "census.gov.uk/help", "cy.ons.gov.uk"},
+		}
+		f := validFeedbackModel()
+
+		Convey("Then feedback about the pages of any of the allowed domains is valid", func() {
+			for _, u := range []string{
+				"https://www.ons.gov.uk/economy",
+				"https://dev.beta.ons.gov.uk/economy",
+				"census.gov.uk/2021/results",
+				"https://census.gov.uk/help",
+				"https://CY.ons.gov.uk/economi",
+			} {
+				f.OnsURL = u
+				So(f.Validate(cfg), ShouldBeNil)
+			}
+		})
+
+		Convey("Then feedback about a host that does not match any allowed domain is invalid, even if it is ONS_DOMAIN", func() {
+			f.OnsURL = "https://ons.gov.uk/economy"
+			err := f.Validate(cfg)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "Key: 'Feedback.OnsURL' Error:Field validation for 'OnsURL' failed on the 'ons_url' tag: "+
+				"host 'ons.gov.uk' does not match any allowed domain")
+		})
+
+		Convey("Then feedback about a path that is not allowed for its domain is invalid", func() {
+			f.OnsURL = "https://census.gov.uk/2011"
+			err := f.Validate(cfg)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldEqual, "Key: 'Feedback.OnsURL' Error:Field validation for 'OnsURL' failed on the 'ons_url' tag: "+
+				"path '/2011' is not allowed for domain 'census.gov.uk'")
 		})
 	})
 }
 
+func TestIsSiteDomainURL(t *testing.T) {
+	Convey("URLs of the provided domain and its subdomains are site domain URLs", t, func() {
+		So(models.IsSiteDomainURL("https://ons.gov.uk/economy", "ons.gov.uk"), ShouldBeTrue)
+		So(models.IsSiteDomainURL("www.ons.gov.uk/economy", "ons.gov.uk"), ShouldBeTrue)
+	})
+
+	Convey("URLs of other domains, lookalike domains and invalid URLs are not site domain URLs", t, func() {
+		So(models.IsSiteDomainURL("https://notons.gov.uk/economy", "ons.gov.uk"), ShouldBeFalse)
+		So(models.IsSiteDomainURL("https://ons.gov.uk.example.com", "ons.gov.uk"), ShouldBeFalse)
+		So(models.IsSiteDomainURL("", "ons.gov.uk"), ShouldBeFalse)
+	})
+}
+
 func TestFeedbackSanitize(t *testing.T) {
 	Convey("Given a Feedback model where all strings are unsafe", t, func() {
 		f := validFeedbackModel()
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// FeedbackFilter represents the criteria used to filter stored feedback. Nil or empty values are not filtered on.
+type FeedbackFilter struct {
+	IsPageUseful      *bool
+	IsGeneralFeedback *bool
+	OnsURLPrefix      string
+	ReceivedAfter     *time.Time // inclusive
+	ReceivedBefore    *time.Time // exclusive
+}
+
+// FeedbackList represents a paginated list of feedback
+type FeedbackList struct {
+	Items      []*Feedback `json:"items"`
+	Count      int         `json:"count"`
+	Offset     int         `json:"offset"`
+	Limit      int         `json:"limit"`
+	TotalCount int         `json:"total_count"`
+}
+
+// Matches returns true if the provided feedback satisfies all the criteria of the filter
+func (ff *FeedbackFilter) Matches(f *Feedback) bool {
+	if ff == nil {
+		return true
+	}
+	if ff.IsPageUseful != nil && (f.IsPageUseful == nil || *f.IsPageUseful != *ff.IsPageUseful) {
+		return false
+	}
+	if ff.IsGeneralFeedback != nil && (f.IsGeneralFeedback == nil || *f.IsGeneralFeedback != *ff.IsGeneralFeedback) {
+		return false
+	}
+	if ff.OnsURLPrefix != "" && (f.OnsURL == "" || !strings.HasPrefix(NormaliseURL(f.OnsURL), NormaliseURL(ff.OnsURLPrefix))) {
+		return false
+	}
+	if ff.ReceivedAfter != nil && (f.ReceivedAt == nil || f.ReceivedAt.Before(*ff.ReceivedAfter)) {
+		return false
+	}
+	if ff.ReceivedBefore != nil && (f.ReceivedAt == nil || !f.ReceivedAt.Before(*ff.ReceivedBefore)) {
+		return false
+	}
+	return true
+}
+
+// NewFeedbackList returns the page of the provided items starting at offset and containing up to limit items
+func NewFeedbackList(items []*Feedback, offset, limit int) *FeedbackList {
+	total := len(items)
+	start := min(offset, total)
+	end := min(start+limit, total)
+
+	return &FeedbackList{
+		Items:      items[start:end],
+		Count:      end - start,
+		Offset:     offset,
+		Limit:      limit,
+		TotalCount: total,
+	}
+}
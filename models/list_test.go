@@ -0,0 +1,86 @@
+package models_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-feedback-api/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFeedbackFilterMatches(t *testing.T) {
+	notUseful := false
+	received := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+	before := received.Add(-time.Hour)
+	after := received.Add(time.Hour)
+
+	Convey("Given a stored feedback model", t, func() {
+		f := validFeedbackModel()
+		f.ReceivedAt = &received
+
+		Convey("Then a nil or empty filter matches it", func() {
+			var nilFilter *models.FeedbackFilter
+			So(nilFilter.Matches(f), ShouldBeTrue)
+			So((&models.FeedbackFilter{}).Matches(f), ShouldBeTrue)
+		})
+
+		Convey("Then a filter with the same values matches it", func() {
+			ff := &models.FeedbackFilter{
+				IsPageUseful:      &pageIsUseful,
+				IsGeneralFeedback: &isGeneralFeedback,
+				OnsURLPrefix:      fmt.Sprintf("%s:1234/sub", onsHost),
+				ReceivedAfter:     &received,
+				ReceivedBefore:    &after,
+			}
+			So(ff.Matches(f), ShouldBeTrue)
+		})
+
+		Convey("Then a filter on a different is_page_useful value does not match it", func() {
+			So((&models.FeedbackFilter{IsPageUseful: &notUseful}).Matches(f), ShouldBeFalse)
+		})
+
+		Convey("Then a filter on a different is_general_feedback value does not match it", func() {
+			So((&models.FeedbackFilter{IsGeneralFeedback: &notUseful}).Matches(f), ShouldBeFalse)
+		})
+
+		Convey("Then a filter on a different ons_url prefix does not match it", func() {
+			So((&models.FeedbackFilter{OnsURLPrefix: "https://otherhost/sub"}).Matches(f), ShouldBeFalse)
+		})
+
+		Convey("Then a filter on a date range that does not include it does not match it", func() {
+			So((&models.FeedbackFilter{ReceivedAfter: &after}).Matches(f), ShouldBeFalse)
+			So((&models.FeedbackFilter{ReceivedBefore: &before}).Matches(f), ShouldBeFalse)
+			So((&models.FeedbackFilter{ReceivedBefore: &received}).Matches(f), ShouldBeFalse)
+		})
+	})
+}
+
+func TestNewFeedbackList(t *testing.T) {
+	Convey("Given a list of 3 feedback items", t, func() {
+		items := []*models.Feedback{{ID: "1"}, {ID: "2"}, {ID: "3"}}
+
+		Convey("Then a page within range contains the expected items", func() {
+			l := models.NewFeedbackList(items, 1, 1)
+			So(l.Items, ShouldResemble, []*models.Feedback{{ID: "2"}})
+			So(l.Count, ShouldEqual, 1)
+			So(l.Offset, ShouldEqual, 1)
+			So(l.Limit, ShouldEqual, 1)
+			So(l.TotalCount, ShouldEqual, 3)
+		})
+
+		Convey("Then a page that exceeds the items contains the remaining items", func() {
+			l := models.NewFeedbackList(items, 2, 10)
+			So(l.Items, ShouldResemble, []*models.Feedback{{ID: "3"}})
+			So(l.Count, ShouldEqual, 1)
+			So(l.TotalCount, ShouldEqual, 3)
+		})
+
+		Convey("Then a page with an offset beyond the items is empty", func() {
+			l := models.NewFeedbackList(items, 5, 10)
+			So(l.Items, ShouldBeEmpty)
+			So(l.Count, ShouldEqual, 0)
+			So(l.TotalCount, ShouldEqual, 3)
+		})
+	})
+}
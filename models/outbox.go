@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// OutboxMessage is an email waiting in the outbox to be delivered
+type OutboxMessage struct {
+	ID           string    `json:"id"`
+	From         string    `json:"from"`
+	To           []string  `json:"to"`
+	Msg          []byte    `json:"msg"`
+	CreatedAt    time.Time `json:"created_at"`
+	Attempts     int       `json:"attempts"`
+	NextAttempt  time.Time `json:"next_attempt"`
+	LastError    string    `json:"last_error,omitempty"`
+	DeadLettered bool      `json:"dead_lettered,omitempty"`
+}
+
+// IsPending returns true if the message has not been dead-lettered and is due to be delivered at the provided time
+func (m *OutboxMessage) IsPending(now time.Time) bool {
+	return !m.DeadLettered && !m.NextAttempt.After(now)
+}
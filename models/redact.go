@@ -0,0 +1,156 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/ONSdigital/dp-feedback-api/config"
+)
+
+// Types of personal information redacted from the feedback description
+const (
+	RedactionCardNumber  = "card_number"
+	RedactionNINO        = "nino"
+	RedactionPhoneNumber = "phone_number"
+	RedactionPostcode    = "postcode"
+)
+
+// Redaction records how many times a type of personal information was redacted from the feedback description
+type Redaction struct {
+	Type  string `json:"type"`
+	Count int    `json:"count"`
+}
+
+// redactor replaces the matches of its pattern that are valid with its placeholder
+type redactor struct {
+	kind        string
+	placeholder string
+	pattern     *regexp.Regexp
+	valid       func(match string) bool
+	enabled     func(cfg *config.Redact) bool
+}
+
+// redactors in the order they are applied. Card numbers go first, so that their digits are not taken for phone numbers.
+var redactors = []*redactor{
+	{
+		kind:        RedactionCardNumber,
+		placeholder: "[REDACTED CARD NUMBER]",
+		pattern:     regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`),
+		valid:       isCardNumber,
+		enabled:     func(cfg *config.Redact) bool { return cfg.CardNumber },
+	},
+	{
+		kind:        RedactionNINO,
+		placeholder: "[REDACTED NINO]",
+		pattern:     regexp.MustCompile(`(?i)\b[A-CEGHJ-PR-TW-Z][A-CEGHJ-NPR-TW-Z] ?\d{2} ?\d{2} ?\d{2} ?[A-D]\b`),
+		valid:       isNINO,
+		enabled:     func(cfg *config.Redact) bool { return cfg.NINO },
+	},
+	{
+		kind:        RedactionPhoneNumber,
+		placeholder: "[REDACTED PHONE NUMBER]",
+		pattern:     regexp.MustCompile(`(?:(?:\+|\b00)44 ?(?:\(0\) ?)?\d{2,4}|\(?\b0\d{2,4}\)?)[ -]?\d{3,4}[ -]?\d{3,4}\b`),
+		valid:       isPhoneNumber,
+		enabled:     func(cfg *config.Redact) bool { return cfg.PhoneNumber },
+	},
+	{
+		kind:        RedactionPostcode,
+		placeholder: "[REDACTED POSTCODE]",
+		pattern:     regexp.MustCompile(`(?i)\b(?:[A-PR-UWYZ][A-HK-Y]?\d[A-Z\d]? ?\d[ABD-HJLNP-UW-Z]{2}|GIR ?0AA)\b`),
+		valid:       func(string) bool { return true },
+		enabled:     func(cfg *config.Redact) bool { return cfg.Postcode },
+	},
+}
+
+// Redact replaces the personal information enabled in the provided config (National Insurance numbers, phone numbers,
+// postcodes and card numbers) found in the input string with typed placeholders, e.g. [REDACTED POSTCODE].
+// It returns the redacted string and the redactions applied, by type.
+func Redact(cfg *config.Redact, toRedact string) (string, []Redaction) {
+	s := toRedact
+	var redactions []Redaction
+	for _, r := range redactors {
+		if !r.enabled(cfg) {
+			continue
+		}
+		count := 0
+		s = r.pattern.ReplaceAllStringFunc(s, func(match string) string {
+			if !r.valid(match) {
+				return match
+			}
+			count++
+			return r.placeholder
+		})
+		if count > 0 {
+			redactions = append(redactions, Redaction{Type: r.kind, Count: count})
+		}
+	}
+	return s, redactions
+}
+
+// Redact mutates the feedback description to replace the personal information enabled in the provided config with placeholders,
+// and records the redactions applied. Nothing is redacted without a config.
+func (f *Feedback) Redact(cfg *config.Redact) {
+	f.Redactions = nil
+	if cfg == nil {
+		return
+	}
+	f.Feedback, f.Redactions = Redact(cfg, f.Feedback)
+}
+
+// digits returns the digits in the provided string
+func digits(s string) string {
+	var sb strings.Builder
+	for _, c := range s {
+		if c >= '0' && c <= '9' {
+			sb.WriteRune(c)
+		}
+	}
+	return sb.String()
+}
+
+// isCardNumber returns true if the provided string has between 13 and 19 digits that pass the Luhn check
+func isCardNumber(s string) bool {
+	d := digits(s)
+	if len(d) < 13 || len(d) > 19 {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(d) - 1; i >= 0; i-- {
+		n := int(d[i] - '0')
+		if double {
+			n *= 2
+			if n > 9 {
+				n -= 9
+			}
+		}
+		sum += n
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// invalidNINOPrefixes are the prefixes that are never allocated to National Insurance numbers
+var invalidNINOPrefixes = map[string]bool{"BG": true, "GB": true, "KN": true, "NK": true, "NT": true, "TN": true, "ZZ": true}
+
+// isNINO returns true if the provided string, which has the format of a National Insurance number, has a valid prefix
+func isNINO(s string) bool {
+	return !invalidNINOPrefixes[strings.ToUpper(s[:2])]
+}
+
+// isPhoneNumber returns true if the provided string, which has the format of a UK phone number, has a valid number of digits
+// after the trunk prefix or country code, starting with a digit used by UK phone numbers
+func isPhoneNumber(s string) bool {
+	d := digits(s)
+	switch {
+	case strings.HasPrefix(s, "+44"):
+		d = strings.TrimPrefix(d, "44")
+	case strings.HasPrefix(s, "0044"):
+		d = strings.TrimPrefix(d, "0044")
+	}
+	d = strings.TrimPrefix(d, "0")
+	if len(d) < 9 || len(d) > 10 {
+		return false
+	}
+	return strings.ContainsRune("1235789", rune(d[0]))
+}
@@ -0,0 +1,108 @@
+package models_test
+
+import (
+	"testing"
+
+	"github.com/ONSdigital/dp-feedback-api/config"
+	"github.com/ONSdigital/dp-feedback-api/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var redactAll = &config.Redact{NINO: true, PhoneNumber: true, Postcode: true, CardNumber: true}
+
+func TestRedact(t *testing.T) {
+	Convey("Given a fully enabled redact config", t, func() {
+		Convey("Then card numbers that pass the Luhn check are redacted", func() {
+			s, redactions := models.Redact(redactAll, "my card 4111 1111 1111 1111 and 5500-0000-0000-0004 were charged twice")
+			So(s, ShouldEqual, "my card [REDACTED CARD NUMBER] and [REDACTED CARD NUMBER] were charged twice")
+			So(redactions, ShouldResemble, []models.Redaction{{Type: models.RedactionCardNumber, Count: 2}})
+		})
+
+		Convey("Then long numbers that fail the Luhn check are not redacted", func() {
+			s, redactions := models.Redact(redactAll, "dataset 4111 1111 1111 1112 has errors")
+			So(s, ShouldEqual, "dataset 4111 1111 1111 1112 has errors")
+			So(redactions, ShouldBeEmpty)
+		})
+
+		Convey("Then National Insurance numbers with a valid prefix are redacted", func() {
+			s, redactions := models.Redact(redactAll, "my NI number is JG 10 37 59 A or ab123456d")
+			So(s, ShouldEqual, "my NI number is [REDACTED NINO] or [REDACTED NINO]")
+			So(redactions, ShouldResemble, []models.Redaction{{Type: models.RedactionNINO, Count: 2}})
+
+			s, redactions = models.Redact(redactAll, "reference GB123456A")
+			So(s, ShouldEqual, "reference GB123456A")
+			So(redactions, ShouldBeEmpty)
+		})
+
+		Convey("Then UK phone numbers are redacted", func() {
+			for _, phone := range []string{"020 7946 0958", "(020) 7946 0958", "07700 900123", "01632960983", "+44 20 7946 0958", "+44 (0) 7700 900123", "0044 113 496 0999"} {
+				s, redactions := models.Redact(redactAll, "call me on "+phone+" please")
+				So(s, ShouldEqual, "call me on [REDACTED PHONE NUMBER] please")
+				So(redactions, ShouldResemble, []models.Redaction{{Type: models.RedactionPhoneNumber, Count: 1}})
+			}
+		})
+
+		Convey("Then numbers that are not UK phone numbers are not redacted", func() {
+			for _, text := range []string{"inflation was 0.5 percent", "the figure 0400 123 456 is wrong", "between 2020 and 2021", "table 0123 4567"} {
+				s, redactions := models.Redact(redactAll, text)
+				So(s, ShouldEqual, text)
+				So(redactions, ShouldBeEmpty)
+			}
+		})
+
+		Convey("Then UK postcodes are redacted", func() {
+			s, redactions := models.Redact(redactAll, "I live at SW1A 1AA, near m1 1ae and EC1A1BB")
+			So(s, ShouldEqual, "I live at [REDACTED POSTCODE], near [REDACTED POSTCODE] and [REDACTED POSTCODE]")
+			So(redactions, ShouldResemble, []models.Redaction{{Type: models.RedactionPostcode, Count: 3}})
+		})
+
+		Convey("Then all the redactions applied are recorded by type", func() {
+			s, redactions := models.Redact(redactAll, "JG103759A, 07700 900123, SW1A 1AA, 4111111111111111")
+			So(s, ShouldEqual, "[REDACTED NINO], [REDACTED PHONE NUMBER], [REDACTED POSTCODE], [REDACTED CARD NUMBER]")
+			So(redactions, ShouldResemble, []models.Redaction{
+				{Type: models.RedactionCardNumber, Count: 1},
+				{Type: models.RedactionNINO, Count: 1},
+				{Type: models.RedactionPhoneNumber, Count: 1},
+				{Type: models.RedactionPostcode, Count: 1},
+			})
+		})
+
+		Convey("Then text without personal information is not changed", func() {
+			s, redactions := models.Redact(redactAll, "The CPI figures for Q1 2024 are missing")
+			So(s, ShouldEqual, "The CPI figures for Q1 2024 are missing")
+			So(redactions, ShouldBeNil)
+		})
+	})
+
+	Convey("Given a redact config that only enables postcodes", t, func() {
+		cfg := &config.Redact{Postcode: true}
+
+		Convey("Then only postcodes are redacted", func() {
+			s, redactions := models.Redact(cfg, "JG103759A, 07700 900123, SW1A 1AA")
+			So(s, ShouldEqual, "JG103759A, 07700 900123, [REDACTED POSTCODE]")
+			So(redactions, ShouldResemble, []models.Redaction{{Type: models.RedactionPostcode, Count: 1}})
+		})
+	})
+}
+
+func TestFeedbackRedact(t *testing.T) {
+	Convey("Given feedback with personal information in its description", t, func() {
+		f := validFeedbackModel()
+		f.Feedback = "please call 07700 900123"
+		f.Redactions = []models.Redaction{{Type: "provided", Count: 1}}
+
+		Convey("When it is redacted, then the description is redacted and the redactions applied are recorded", func() {
+			f.Redact(redactAll)
+			So(f.Feedback, ShouldEqual, "please call [REDACTED PHONE NUMBER]")
+			So(f.Redactions, ShouldResemble, []models.Redaction{{Type: models.RedactionPhoneNumber, Count: 1}})
+			So(f.Name, ShouldEqual, "Mr Feedback reporter")
+			So(f.EmailAddress, ShouldEqual, "feedback@reporter.com")
+		})
+
+		Convey("When it is redacted without a config, then nothing is redacted", func() {
+			f.Redact(nil)
+			So(f.Feedback, ShouldEqual, "please call 07700 900123")
+			So(f.Redactions, ShouldBeNil)
+		})
+	})
+}
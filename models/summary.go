@@ -0,0 +1,149 @@
+package models
+
+import (
+	"math"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Intervals that page usefulness can be summarised by
+const (
+	IntervalDay  = "day"
+	IntervalWeek = "week"
+)
+
+// UsefulnessSummary represents the aggregated answers to "Is this page useful?" for a page, optionally within a period
+type UsefulnessSummary struct {
+	OnsURL              string  `json:"ons_url"`
+	PeriodStart         string  `json:"period_start,omitempty"`
+	Useful              int     `json:"useful"`
+	NotUseful           int     `json:"not_useful"`
+	Total               int     `json:"total"`
+	UsefulPercentage    float64 `json:"useful_percentage"`
+	NotUsefulPercentage float64 `json:"not_useful_percentage"`
+}
+
+// UsefulnessSummaryList represents a paginated list of page usefulness summaries
+type UsefulnessSummaryList struct {
+	Items      []*UsefulnessSummary `json:"items"`
+	Count      int                  `json:"count"`
+	Offset     int                  `json:"offset"`
+	Limit      int                  `json:"limit"`
+	TotalCount int                  `json:"total_count"`
+}
+
+// IsValidInterval returns true if the provided interval can be used to summarise page usefulness.
+// An empty interval means that the summaries are not split by period.
+func IsValidInterval(interval string) bool {
+	return interval == "" || interval == IntervalDay || interval == IntervalWeek
+}
+
+// Summarise aggregates the page usefulness answers of the provided feedback, grouped by normalised ons_url
+// and by the start of the provided interval. Feedback without an ons_url is not included.
+// The summaries are sorted by number of "not useful" answers, so that the pages with most dissatisfaction come first.
+func Summarise(items []*Feedback, interval string) []*UsefulnessSummary {
+	type key struct{ url, period string }
+	groups := map[key]*UsefulnessSummary{}
+
+	for _, f := range items {
+		pageURL := CanonicalPageURL(f.OnsURL)
+		if pageURL == "" || f.IsPageUseful == nil {
+			continue
+		}
+
+		k := key{url: pageURL, period: periodStart(f.ReceivedAt, interval)}
+		s, ok := groups[k]
+		if !ok {
+			s = &UsefulnessSummary{OnsURL: k.url, PeriodStart: k.period}
+			groups[k] = s
+		}
+
+		if *f.IsPageUseful {
+			s.Useful++
+		} else {
+			s.NotUseful++
+		}
+	}
+
+	summaries := make([]*UsefulnessSummary, 0, len(groups))
+	for _, s := range groups {
+		s.Total = s.Useful + s.NotUseful
+		s.UsefulPercentage = percentage(s.Useful, s.Total)
+		s.NotUsefulPercentage = percentage(s.NotUseful, s.Total)
+		summaries = append(summaries, s)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		a, b := summaries[i], summaries[j]
+		if a.NotUseful != b.NotUseful {
+			return a.NotUseful > b.NotUseful
+		}
+		if a.NotUsefulPercentage != b.NotUsefulPercentage {
+			return a.NotUsefulPercentage > b.NotUsefulPercentage
+		}
+		if a.OnsURL != b.OnsURL {
+			return a.OnsURL < b.OnsURL
+		}
+		return a.PeriodStart < b.PeriodStart
+	})
+
+	return summaries
+}
+
+// NewUsefulnessSummaryList returns the page of the provided summaries starting at offset and containing up to limit items
+func NewUsefulnessSummaryList(items []*UsefulnessSummary, offset, limit int) *UsefulnessSummaryList {
+	total := len(items)
+	start := min(offset, total)
+	end := min(start+limit, total)
+
+	return &UsefulnessSummaryList{
+		Items:      items[start:end],
+		Count:      end - start,
+		Offset:     offset,
+		Limit:      limit,
+		TotalCount: total,
+	}
+}
+
+// CanonicalPageURL normalises a page URL so that different forms of the same page are grouped together:
+// the scheme, port, query and fragment are removed, the host and path are lower-cased and trailing slashes are trimmed.
+// An empty string is returned if the value is not a URL.
+func CanonicalPageURL(urlString string) string {
+	if urlString == "" {
+		return ""
+	}
+	u, err := url.Parse(NormaliseURL(urlString))
+	if err != nil || u.Hostname() == "" {
+		return ""
+	}
+	return strings.ToLower(u.Hostname()) + strings.TrimRight(strings.ToLower(u.Path), "/")
+}
+
+// periodStart returns the date ('YYYY-MM-DD') of the start of the interval that contains t,
+// where weeks start on Monday. An empty string is returned if no interval is provided.
+func periodStart(t *time.Time, interval string) string {
+	if t == nil {
+		return ""
+	}
+	utc := t.UTC()
+	day := time.Date(utc.Year(), utc.Month(), utc.Day(), 0, 0, 0, 0, time.UTC)
+	switch interval {
+	case IntervalDay:
+		return day.Format(time.DateOnly)
+	case IntervalWeek:
+		daysSinceMonday := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -daysSinceMonday).Format(time.DateOnly)
+	default:
+		return ""
+	}
+}
+
+// percentage returns n as a percentage of total, rounded to 2 decimal places
+func percentage(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(n)*10000/float64(total)) / 100
+}
@@ -0,0 +1,89 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-feedback-api/models"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func usefulnessVote(onsURL string, useful bool, receivedAt time.Time) *models.Feedback {
+	return &models.Feedback{
+		ReceivedAt:   &receivedAt,
+		IsPageUseful: &useful,
+		OnsURL:       onsURL,
+	}
+}
+
+func TestCanonicalPageURL(t *testing.T) {
+	Convey("Different forms of the same page URL are normalised to the same value", t, func() {
+		So(models.CanonicalPageURL("https://www.ons.gov.uk/economy/"), ShouldEqual, "www.ons.gov.uk/economy")
+		So(models.CanonicalPageURL("http://WWW.ons.gov.uk:443/Economy?x=1#top"), ShouldEqual, "www.ons.gov.uk/economy")
+		So(models.CanonicalPageURL("www.ons.gov.uk/economy"), ShouldEqual, "www.ons.gov.uk/economy")
+	})
+
+	Convey("Values that are not URLs are normalised to an empty string", t, func() {
+		So(models.CanonicalPageURL(""), ShouldEqual, "")
+		So(models.CanonicalPageURL("The whole website"), ShouldEqual, "")
+	})
+}
+
+func TestSummarise(t *testing.T) {
+	monday := time.Date(2024, 3, 11, 9, 0, 0, 0, time.UTC)
+	wednesday := time.Date(2024, 3, 13, 9, 0, 0, 0, time.UTC)
+	nextMonday := time.Date(2024, 3, 18, 9, 0, 0, 0, time.UTC)
+
+	items := []*models.Feedback{
+		usefulnessVote("https://www.ons.gov.uk/economy", true, monday),
+		usefulnessVote("https://www.ons.gov.uk/economy/", false, wednesday),
+		usefulnessVote("www.ons.gov.uk/economy", true, nextMonday),
+		usefulnessVote("https://www.ons.gov.uk/census", false, monday),
+		usefulnessVote("https://www.ons.gov.uk/census", false, wednesday),
+		usefulnessVote("https://www.ons.gov.uk/census", true, wednesday),
+		usefulnessVote("", false, monday),
+	}
+
+	Convey("Given feedback for several pages, when it is summarised without interval", t, func() {
+		summaries := models.Summarise(items, "")
+
+		Convey("Then the votes are grouped by page and sorted by most 'not useful' votes", func() {
+			So(summaries, ShouldResemble, []*models.UsefulnessSummary{
+				{OnsURL: "www.ons.gov.uk/census", Useful: 1, NotUseful: 2, Total: 3, UsefulPercentage: 33.33, NotUsefulPercentage: 66.67},
+				{OnsURL: "www.ons.gov.uk/economy", Useful: 2, NotUseful: 1, Total: 3, UsefulPercentage: 66.67, NotUsefulPercentage: 33.33},
+			})
+		})
+	})
+
+	Convey("Given feedback for several pages, when it is summarised by week", t, func() {
+		summaries := models.Summarise(items, models.IntervalWeek)
+
+		Convey("Then the votes are grouped by page and week starting on Monday", func() {
+			So(summaries, ShouldResemble, []*models.UsefulnessSummary{
+				{OnsURL: "www.ons.gov.uk/census", PeriodStart: "2024-03-11", Useful: 1, NotUseful: 2, Total: 3, UsefulPercentage: 33.33, NotUsefulPercentage: 66.67},
+				{OnsURL: "www.ons.gov.uk/economy", PeriodStart: "2024-03-11", Useful: 1, NotUseful: 1, Total: 2, UsefulPercentage: 50, NotUsefulPercentage: 50},
+				{OnsURL: "www.ons.gov.uk/economy", PeriodStart: "2024-03-18", Useful: 1, NotUseful: 0, Total: 1, UsefulPercentage: 100, NotUsefulPercentage: 0},
+			})
+		})
+	})
+
+	Convey("Given feedback for several pages, when it is summarised by day", t, func() {
+		summaries := models.Summarise(items, models.IntervalDay)
+
+		Convey("Then the votes are grouped by page and day", func() {
+			So(summaries, ShouldHaveLength, 5)
+			So(summaries[0], ShouldResemble, &models.UsefulnessSummary{
+				OnsURL: "www.ons.gov.uk/census", PeriodStart: "2024-03-11", NotUseful: 1, Total: 1, NotUsefulPercentage: 100,
+			})
+		})
+	})
+}
+
+func TestIsValidInterval(t *testing.T) {
+	Convey("Only the supported intervals are valid", t, func() {
+		So(models.IsValidInterval(""), ShouldBeTrue)
+		So(models.IsValidInterval(models.IntervalDay), ShouldBeTrue)
+		So(models.IsValidInterval(models.IntervalWeek), ShouldBeTrue)
+		So(models.IsValidInterval("month"), ShouldBeFalse)
+	})
+}
@@ -0,0 +1,24 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"github.com/ONSdigital/dp-feedback-api/models"
+)
+
+//go:generate moq -out mock/store.go -pkg mock . Store
+//go:generate moq -out mock/sender.go -pkg mock . Sender
+
+// Store defines the required methods to persist the outbox
+type Store interface {
+	AddOutboxMessage(ctx context.Context, msg *models.OutboxMessage) error
+	UpdateOutboxMessage(ctx context.Context, msg *models.OutboxMessage) error
+	DeleteOutboxMessage(ctx context.Context, id string) error
+	GetPendingOutboxMessages(ctx context.Context, now time.Time, limit int) ([]*models.OutboxMessage, error)
+}
+
+// Sender defines the required methods to send emails
+type Sender interface {
+	Send(from string, to []string, msg []byte) error
+}
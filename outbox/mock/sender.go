@@ -4,30 +4,30 @@
 package mock
 
 import (
-	"github.com/ONSdigital/dp-feedback-api/api"
+	"github.com/ONSdigital/dp-feedback-api/outbox"
 	"sync"
 )
 
-// Ensure, that EmailSenderMock does implement api.EmailSender.
+// Ensure, that SenderMock does implement outbox.Sender.
 // If this is not the case, regenerate this file with moq.
-var _ api.EmailSender = &EmailSenderMock{}
+var _ outbox.Sender = &SenderMock{}
 
-// EmailSenderMock is a mock implementation of api.EmailSender.
+// SenderMock is a mock implementation of outbox.Sender.
 //
-//	func TestSomethingThatUsesEmailSender(t *testing.T) {
+//	func TestSomethingThatUsesSender(t *testing.T) {
 //
-//		// make and configure a mocked api.EmailSender
-//		mockedEmailSender := &EmailSenderMock{
+//		// make and configure a mocked outbox.Sender
+//		mockedSender := &SenderMock{
 //			SendFunc: func(from string, to []string, msg []byte) error {
 //				panic("mock out the Send method")
 //			},
 //		}
 //
-//		// use mockedEmailSender in code that requires api.EmailSender
+//		// use mockedSender in code that requires outbox.Sender
 //		// and then make assertions.
 //
 //	}
-type EmailSenderMock struct {
+type SenderMock struct {
 	// SendFunc mocks the Send method.
 	SendFunc func(from string, to []string, msg []byte) error
 
@@ -47,9 +47,9 @@ type EmailSenderMock struct {
 }
 
 // Send calls SendFunc.
-func (mock *EmailSenderMock) Send(from string, to []string, msg []byte) error {
+func (mock *SenderMock) Send(from string, to []string, msg []byte) error {
 	if mock.SendFunc == nil {
-		panic("EmailSenderMock.SendFunc: method is nil but EmailSender.Send was just called")
+		panic("SenderMock.SendFunc: method is nil but Sender.Send was just called")
 	}
 	callInfo := struct {
 		From string
@@ -69,8 +69,8 @@ func (mock *EmailSenderMock) Send(from string, to []string, msg []byte) error {
 // SendCalls gets all the calls that were made to Send.
 // Check the length with:
 //
-//	len(mockedEmailSender.SendCalls())
-func (mock *EmailSenderMock) SendCalls() []struct {
+//	len(mockedSender.SendCalls())
+func (mock *SenderMock) SendCalls() []struct {
 	From string
 	To   []string
 	Msg  []byte
@@ -0,0 +1,240 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"context"
+	"github.com/ONSdigital/dp-feedback-api/models"
+	"github.com/ONSdigital/dp-feedback-api/outbox"
+	"sync"
+	"time"
+)
+
+// Ensure, that StoreMock does implement outbox.Store.
+// If this is not the case, regenerate this file with moq.
+var _ outbox.Store = &StoreMock{}
+
+// StoreMock is a mock implementation of outbox.Store.
+//
+//	func TestSomethingThatUsesStore(t *testing.T) {
+//
+//		// make and configure a mocked outbox.Store
+//		mockedStore := &StoreMock{
+//			AddOutboxMessageFunc: func(ctx context.Context, msg *models.OutboxMessage) error {
+//				panic("mock out the AddOutboxMessage method")
+//			},
+//			DeleteOutboxMessageFunc: func(ctx context.Context, id string) error {
+//				panic("mock out the DeleteOutboxMessage method")
+//			},
+//			GetPendingOutboxMessagesFunc: func(ctx context.Context, now time.Time, limit int) ([]*models.OutboxMessage, error) {
+//				panic("mock out the GetPendingOutboxMessages method")
+//			},
+//			UpdateOutboxMessageFunc: func(ctx context.Context, msg *models.OutboxMessage) error {
+//				panic("mock out the UpdateOutboxMessage method")
+//			},
+//		}
+//
+//		// use mockedStore in code that requires outbox.Store
+//		// and then make assertions.
+//
+//	}
+type StoreMock struct {
+	// AddOutboxMessageFunc mocks the AddOutboxMessage method.
+	AddOutboxMessageFunc func(ctx context.Context, msg *models.OutboxMessage) error
+
+	// DeleteOutboxMessageFunc mocks the DeleteOutboxMessage method.
+	DeleteOutboxMessageFunc func(ctx context.Context, id string) error
+
+	// GetPendingOutboxMessagesFunc mocks the GetPendingOutboxMessages method.
+	GetPendingOutboxMessagesFunc func(ctx context.Context, now time.Time, limit int) ([]*models.OutboxMessage, error)
+
+	// UpdateOutboxMessageFunc mocks the UpdateOutboxMessage method.
+	UpdateOutboxMessageFunc func(ctx context.Context, msg *models.OutboxMessage) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// AddOutboxMessage holds details about calls to the AddOutboxMessage method.
+		AddOutboxMessage []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Msg is the msg argument value.
+			Msg *models.OutboxMessage
+		}
+		// DeleteOutboxMessage holds details about calls to the DeleteOutboxMessage method.
+		DeleteOutboxMessage []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+		}
+		// GetPendingOutboxMessages holds details about calls to the GetPendingOutboxMessages method.
+		GetPendingOutboxMessages []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Now is the now argument value.
+			Now time.Time
+			// Limit is the limit argument value.
+			Limit int
+		}
+		// UpdateOutboxMessage holds details about calls to the UpdateOutboxMessage method.
+		UpdateOutboxMessage []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Msg is the msg argument value.
+			Msg *models.OutboxMessage
+		}
+	}
+	lockAddOutboxMessage         sync.RWMutex
+	lockDeleteOutboxMessage      sync.RWMutex
+	lockGetPendingOutboxMessages sync.RWMutex
+	lockUpdateOutboxMessage      sync.RWMutex
+}
+
+// AddOutboxMessage calls AddOutboxMessageFunc.
+func (mock *StoreMock) AddOutboxMessage(ctx context.Context, msg *models.OutboxMessage) error {
+	if mock.AddOutboxMessageFunc == nil {
+		panic("StoreMock.AddOutboxMessageFunc: method is nil but Store.AddOutboxMessage was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Msg *models.OutboxMessage
+	}{
+		Ctx: ctx,
+		Msg: msg,
+	}
+	mock.lockAddOutboxMessage.Lock()
+	mock.calls.AddOutboxMessage = append(mock.calls.AddOutboxMessage, callInfo)
+	mock.lockAddOutboxMessage.Unlock()
+	return mock.AddOutboxMessageFunc(ctx, msg)
+}
+
+// AddOutboxMessageCalls gets all the calls that were made to AddOutboxMessage.
+// Check the length with:
+//
+//	len(mockedStore.AddOutboxMessageCalls())
+func (mock *StoreMock) AddOutboxMessageCalls() []struct {
+	Ctx context.Context
+	Msg *models.OutboxMessage
+} {
+	var calls []struct {
+		Ctx context.Context
+		Msg *models.OutboxMessage
+	}
+	mock.lockAddOutboxMessage.RLock()
+	calls = mock.calls.AddOutboxMessage
+	mock.lockAddOutboxMessage.RUnlock()
+	return calls
+}
+
+// DeleteOutboxMessage calls DeleteOutboxMessageFunc.
+func (mock *StoreMock) DeleteOutboxMessage(ctx context.Context, id string) error {
+	if mock.DeleteOutboxMessageFunc == nil {
+		panic("StoreMock.DeleteOutboxMessageFunc: method is nil but Store.DeleteOutboxMessage was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockDeleteOutboxMessage.Lock()
+	mock.calls.DeleteOutboxMessage = append(mock.calls.DeleteOutboxMessage, callInfo)
+	mock.lockDeleteOutboxMessage.Unlock()
+	return mock.DeleteOutboxMessageFunc(ctx, id)
+}
+
+// DeleteOutboxMessageCalls gets all the calls that were made to DeleteOutboxMessage.
+// Check the length with:
+//
+//	len(mockedStore.DeleteOutboxMessageCalls())
+func (mock *StoreMock) DeleteOutboxMessageCalls() []struct {
+	Ctx context.Context
+	ID  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+	}
+	mock.lockDeleteOutboxMessage.RLock()
+	calls = mock.calls.DeleteOutboxMessage
+	mock.lockDeleteOutboxMessage.RUnlock()
+	return calls
+}
+
+// GetPendingOutboxMessages calls GetPendingOutboxMessagesFunc.
+func (mock *StoreMock) GetPendingOutboxMessages(ctx context.Context, now time.Time, limit int) ([]*models.OutboxMessage, error) {
+	if mock.GetPendingOutboxMessagesFunc == nil {
+		panic("StoreMock.GetPendingOutboxMessagesFunc: method is nil but Store.GetPendingOutboxMessages was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Now   time.Time
+		Limit int
+	}{
+		Ctx:   ctx,
+		Now:   now,
+		Limit: limit,
+	}
+	mock.lockGetPendingOutboxMessages.Lock()
+	mock.calls.GetPendingOutboxMessages = append(mock.calls.GetPendingOutboxMessages, callInfo)
+	mock.lockGetPendingOutboxMessages.Unlock()
+	return mock.GetPendingOutboxMessagesFunc(ctx, now, limit)
+}
+
+// GetPendingOutboxMessagesCalls gets all the calls that were made to GetPendingOutboxMessages.
+// Check the length with:
+//
+//	len(mockedStore.GetPendingOutboxMessagesCalls())
+func (mock *StoreMock) GetPendingOutboxMessagesCalls() []struct {
+	Ctx   context.Context
+	Now   time.Time
+	Limit int
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Now   time.Time
+		Limit int
+	}
+	mock.lockGetPendingOutboxMessages.RLock()
+	calls = mock.calls.GetPendingOutboxMessages
+	mock.lockGetPendingOutboxMessages.RUnlock()
+	return calls
+}
+
+// UpdateOutboxMessage calls UpdateOutboxMessageFunc.
+func (mock *StoreMock) UpdateOutboxMessage(ctx context.Context, msg *models.OutboxMessage) error {
+	if mock.UpdateOutboxMessageFunc == nil {
+		panic("StoreMock.UpdateOutboxMessageFunc: method is nil but Store.UpdateOutboxMessage was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Msg *models.OutboxMessage
+	}{
+		Ctx: ctx,
+		Msg: msg,
+	}
+	mock.lockUpdateOutboxMessage.Lock()
+	mock.calls.UpdateOutboxMessage = append(mock.calls.UpdateOutboxMessage, callInfo)
+	mock.lockUpdateOutboxMessage.Unlock()
+	return mock.UpdateOutboxMessageFunc(ctx, msg)
+}
+
+// UpdateOutboxMessageCalls gets all the calls that were made to UpdateOutboxMessage.
+// Check the length with:
+//
+//	len(mockedStore.UpdateOutboxMessageCalls())
+func (mock *StoreMock) UpdateOutboxMessageCalls() []struct {
+	Ctx context.Context
+	Msg *models.OutboxMessage
+} {
+	var calls []struct {
+		Ctx context.Context
+		Msg *models.OutboxMessage
+	}
+	mock.lockUpdateOutboxMessage.RLock()
+	calls = mock.calls.UpdateOutboxMessage
+	mock.lockUpdateOutboxMessage.RUnlock()
+	return calls
+}
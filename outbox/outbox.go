@@ -0,0 +1,178 @@
+// Package outbox provides a durable queue of emails, which are persisted before being delivered in the background,
+// so that no feedback email is lost when the mail server is unavailable.
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ONSdigital/dp-feedback-api/config"
+	"github.com/ONSdigital/dp-feedback-api/models"
+	"github.com/ONSdigital/dp-feedback-api/worker"
+	"github.com/ONSdigital/log.go/v2/log"
+	"github.com/gofrs/uuid"
+)
+
+// NewID generates a new unique identifier for an outbox message
+var NewID = func() string {
+	return uuid.Must(uuid.NewV4()).String()
+}
+
+// Now returns the current time, used to schedule the delivery attempts
+var Now = func() time.Time {
+	return time.Now().UTC()
+}
+
+// Outbox persists emails in the store and delivers them with the sender from a background worker,
+// retrying failed deliveries with exponential backoff until they are dead-lettered after the configured attempts.
+type Outbox struct {
+	store  Store
+	sender Sender
+	cfg    *config.Outbox
+
+	deliverMu sync.Mutex
+	wake      chan struct{}
+	worker    *worker.Worker
+}
+
+// New returns a new Outbox that persists emails in the provided store and delivers them with the provided sender
+func New(store Store, sender Sender, cfg *config.Outbox) *Outbox {
+	return &Outbox{
+		store:  store,
+		sender: sender,
+		cfg:    cfg,
+		wake:   make(chan struct{}, 1),
+		worker: worker.New(),
+	}
+}
+
+// Enqueue persists an email to be delivered by the worker, which is woken up to deliver it straight away
+func (o *Outbox) Enqueue(ctx context.Context, from string, to []string, msg []byte) error {
+	now := Now()
+	m := &models.OutboxMessage{
+		ID:          NewID(),
+		From:        from,
+		To:          to,
+		Msg:         msg,
+		CreatedAt:   now,
+		NextAttempt: now,
+	}
+	if err := o.store.AddOutboxMessage(ctx, m); err != nil {
+		return fmt.Errorf("failed to add message to outbox: %w", err)
+	}
+
+	select {
+	case o.wake <- struct{}{}:
+	default:
+		// the worker has already been woken up
+	}
+	return nil
+}
+
+// Start runs the worker in a new go-routine. It delivers the pending emails every poll interval,
+// and whenever a new email is enqueued, until the outbox is closed.
+func (o *Outbox) Start(ctx context.Context) {
+	o.worker.Start(func(stop <-chan struct{}) {
+		o.run(ctx, stop)
+	})
+}
+
+func (o *Outbox) run(ctx context.Context, stop <-chan struct{}) {
+	ticker := time.NewTicker(o.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		case <-o.wake:
+		}
+
+		if err := o.DeliverPending(ctx); err != nil {
+			log.Error(ctx, "failed to deliver outbox messages", err)
+		}
+	}
+}
+
+// DeliverPending attempts to deliver all the emails that are due, in batches.
+// Emails that fail to be delivered are rescheduled, or dead-lettered once they reach the maximum number of attempts.
+func (o *Outbox) DeliverPending(ctx context.Context) error {
+	o.deliverMu.Lock()
+	defer o.deliverMu.Unlock()
+
+	// emails rescheduled during this call are not due until later, so they are not returned again
+	now := Now()
+	for {
+		msgs, err := o.store.GetPendingOutboxMessages(ctx, now, o.cfg.BatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to get pending outbox messages: %w", err)
+		}
+		if len(msgs) == 0 {
+			return nil
+		}
+
+		for _, msg := range msgs {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := o.deliver(ctx, msg); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// deliver sends a single email, and removes it from the outbox or reschedules it according to the result
+func (o *Outbox) deliver(ctx context.Context, msg *models.OutboxMessage) error {
+	logData := log.Data{"outbox_message_id": msg.ID, "attempt": msg.Attempts + 1}
+
+	sendErr := o.sender.Send(msg.From, msg.To, msg.Msg)
+	if sendErr == nil {
+		if err := o.store.DeleteOutboxMessage(ctx, msg.ID); err != nil {
+			return fmt.Errorf("failed to delete delivered outbox message: %w", err)
+		}
+		log.Info(ctx, "outbox message delivered", logData)
+		return nil
+	}
+
+	msg.Attempts++
+	msg.LastError = sendErr.Error()
+	if msg.Attempts >= o.cfg.MaxAttempts {
+		msg.DeadLettered = true
+		log.Error(ctx, "outbox message dead-lettered after reaching the maximum delivery attempts", sendErr, logData)
+	} else {
+		msg.NextAttempt = Now().Add(o.backoff(msg.Attempts))
+		logData["next_attempt"] = msg.NextAttempt
+		log.Warn(ctx, "failed to deliver outbox message, it will be retried", log.FormatErrors([]error{sendErr}), logData)
+	}
+
+	if err := o.store.UpdateOutboxMessage(ctx, msg); err != nil {
+		return fmt.Errorf("failed to update outbox message: %w", err)
+	}
+	return nil
+}
+
+// backoff returns the time to wait before the next delivery attempt, which doubles after every failed attempt
+func (o *Outbox) backoff(attempts int) time.Duration {
+	d := o.cfg.InitialBackoff
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= o.cfg.MaxBackoff {
+			return o.cfg.MaxBackoff
+		}
+	}
+	return min(d, o.cfg.MaxBackoff)
+}
+
+// Close stops the worker and then delivers any pending emails, until they are all delivered or the context is done.
+// Emails that are not delivered remain in the outbox, to be delivered when the service is started again.
+func (o *Outbox) Close(ctx context.Context) error {
+	// wait for the worker to finish any delivery in progress
+	if err := o.worker.Stop(ctx); err != nil {
+		return err
+	}
+	return o.DeliverPending(ctx)
+}
@@ -0,0 +1,219 @@
+package outbox_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-feedback-api/config"
+	"github.com/ONSdigital/dp-feedback-api/models"
+	"github.com/ONSdigital/dp-feedback-api/outbox"
+	"github.com/ONSdigital/dp-feedback-api/outbox/mock"
+	"github.com/ONSdigital/dp-feedback-api/store"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var (
+	ctx      = context.Background()
+	testTime = time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+	errSMTP  = errors.New("smtp error")
+)
+
+func testConfig() *config.Outbox {
+	return &config.Outbox{
+		PollInterval:   time.Hour,
+		BatchSize:      2,
+		MaxAttempts:    3,
+		InitialBackoff: time.Minute,
+		MaxBackoff:     90 * time.Second,
+	}
+}
+
+// setClock overrides the outbox clock and ids, returning a func to restore them and a func to move the clock forward
+func setClock() (restore func(), advance func(time.Duration)) {
+	now, newID := outbox.Now, outbox.NewID
+	current := testTime
+	count := 0
+	outbox.Now = func() time.Time { return current }
+	outbox.NewID = func() string {
+		count++
+		return fmt.Sprintf("msg-%d", count)
+	}
+	return func() { outbox.Now, outbox.NewID = now, newID },
+		func(d time.Duration) { current = current.Add(d) }
+}
+
+func pending(s *store.Memory, at time.Time) []*models.OutboxMessage {
+	msgs, err := s.GetPendingOutboxMessages(ctx, at, 0)
+	So(err, ShouldBeNil)
+	return msgs
+}
+
+func TestDeliverPending(t *testing.T) {
+	restore, advance := setClock()
+	defer restore()
+
+	Convey("Given an outbox with some queued emails", t, func() {
+		s := store.NewMemory()
+		sender := &mock.SenderMock{
+			SendFunc: func(from string, to []string, msg []byte) error { return nil },
+		}
+		o := outbox.New(s, sender, testConfig())
+		for i := 0; i < 3; i++ {
+			So(o.Enqueue(ctx, "sender@mail.com", []string{"receiver@mail.com"}, []byte("message")), ShouldBeNil)
+		}
+
+		Convey("Then the emails are persisted but not sent yet", func() {
+			So(pending(s, testTime), ShouldHaveLength, 3)
+			So(sender.SendCalls(), ShouldHaveLength, 0)
+		})
+
+		Convey("When the pending emails are delivered successfully", func() {
+			So(o.DeliverPending(ctx), ShouldBeNil)
+
+			Convey("Then all of them are sent, across batches, and removed from the outbox", func() {
+				So(sender.SendCalls(), ShouldHaveLength, 3)
+				So(sender.SendCalls()[0].From, ShouldEqual, "sender@mail.com")
+				So(sender.SendCalls()[0].To, ShouldResemble, []string{"receiver@mail.com"})
+				So(string(sender.SendCalls()[0].Msg), ShouldEqual, "message")
+				So(pending(s, testTime.Add(24*time.Hour)), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the mail server is unavailable", func() {
+			sender.SendFunc = func(from string, to []string, msg []byte) error { return errSMTP }
+			So(o.DeliverPending(ctx), ShouldBeNil)
+
+			Convey("Then every email is attempted once and rescheduled after the initial backoff", func() {
+				So(sender.SendCalls(), ShouldHaveLength, 3)
+				So(pending(s, testTime.Add(59*time.Second)), ShouldBeEmpty)
+
+				msgs := pending(s, testTime.Add(time.Minute))
+				So(msgs, ShouldHaveLength, 3)
+				So(msgs[0].Attempts, ShouldEqual, 1)
+				So(msgs[0].LastError, ShouldEqual, "smtp error")
+				So(msgs[0].NextAttempt, ShouldEqual, testTime.Add(time.Minute))
+			})
+
+			Convey("Then the backoff doubles after every attempt, up to the maximum backoff", func() {
+				advance(time.Minute)
+				So(o.DeliverPending(ctx), ShouldBeNil)
+				So(sender.SendCalls(), ShouldHaveLength, 6)
+
+				msgs := pending(s, testTime.Add(time.Minute+90*time.Second))
+				So(msgs, ShouldHaveLength, 3)
+				So(msgs[0].Attempts, ShouldEqual, 2)
+				So(msgs[0].NextAttempt, ShouldEqual, testTime.Add(time.Minute+90*time.Second))
+				advance(-time.Minute)
+			})
+
+			Convey("Then the emails are dead-lettered once they reach the maximum attempts", func() {
+				advance(time.Hour)
+				So(o.DeliverPending(ctx), ShouldBeNil)
+				advance(time.Hour)
+				So(o.DeliverPending(ctx), ShouldBeNil)
+				advance(-2 * time.Hour)
+
+				So(sender.SendCalls(), ShouldHaveLength, 9)
+				So(pending(s, testTime.Add(24*time.Hour)), ShouldBeEmpty)
+			})
+
+			Convey("Then the emails are delivered once the mail server is available again", func() {
+				sender.SendFunc = func(from string, to []string, msg []byte) error { return nil }
+				advance(time.Minute)
+				So(o.DeliverPending(ctx), ShouldBeNil)
+				advance(-time.Minute)
+
+				So(sender.SendCalls(), ShouldHaveLength, 6)
+				So(pending(s, testTime.Add(24*time.Hour)), ShouldBeEmpty)
+			})
+		})
+	})
+
+	Convey("Given an outbox with a store that fails", t, func() {
+		storeMock := &mock.StoreMock{
+			AddOutboxMessageFunc: func(ctx context.Context, msg *models.OutboxMessage) error { return errors.New("store error") },
+			GetPendingOutboxMessagesFunc: func(ctx context.Context, now time.Time, limit int) ([]*models.OutboxMessage, error) {
+				return nil, errors.New("store error")
+			},
+		}
+		o := outbox.New(storeMock, &mock.SenderMock{}, testConfig())
+
+		Convey("Then enqueueing an email fails", func() {
+			So(o.Enqueue(ctx, "sender@mail.com", []string{"receiver@mail.com"}, []byte("message")), ShouldNotBeNil)
+		})
+
+		Convey("Then delivering the pending emails fails", func() {
+			So(o.DeliverPending(ctx), ShouldNotBeNil)
+		})
+	})
+}
+
+func TestStartAndClose(t *testing.T) {
+	restore, _ := setClock()
+	defer restore()
+
+	Convey("Given a started outbox", t, func() {
+		s := store.NewMemory()
+		sent := make(chan []byte, 1)
+		sender := &mock.SenderMock{
+			SendFunc: func(from string, to []string, msg []byte) error {
+				sent <- msg
+				return nil
+			},
+		}
+		o := outbox.New(s, sender, testConfig())
+		o.Start(ctx)
+
+		Convey("When an email is enqueued", func() {
+			So(o.Enqueue(ctx, "sender@mail.com", []string{"receiver@mail.com"}, []byte("message")), ShouldBeNil)
+
+			Convey("Then the worker is woken up and delivers it without waiting for the poll interval", func() {
+				select {
+				case msg := <-sent:
+					So(string(msg), ShouldEqual, "message")
+				case <-time.After(5 * time.Second):
+					So("email not delivered", ShouldBeEmpty)
+				}
+				So(o.Close(ctx), ShouldBeNil)
+			})
+		})
+	})
+
+	Convey("Given an outbox that was not started, with a queued email", t, func() {
+		s := store.NewMemory()
+		sender := &mock.SenderMock{
+			SendFunc: func(from string, to []string, msg []byte) error { return nil },
+		}
+		o := outbox.New(s, sender, testConfig())
+		So(o.Enqueue(ctx, "sender@mail.com", []string{"receiver@mail.com"}, []byte("message")), ShouldBeNil)
+
+		Convey("When it is closed", func() {
+			So(o.Close(ctx), ShouldBeNil)
+
+			Convey("Then the queued email is delivered", func() {
+				So(sender.SendCalls(), ShouldHaveLength, 1)
+				So(pending(s, testTime), ShouldBeEmpty)
+			})
+		})
+	})
+
+	Convey("Given an outbox with a queued email that cannot be delivered", t, func() {
+		s := store.NewMemory()
+		sender := &mock.SenderMock{
+			SendFunc: func(from string, to []string, msg []byte) error { return errSMTP },
+		}
+		o := outbox.New(s, sender, testConfig())
+		So(o.Enqueue(ctx, "sender@mail.com", []string{"receiver@mail.com"}, []byte("message")), ShouldBeNil)
+
+		Convey("When it is closed", func() {
+			So(o.Close(ctx), ShouldBeNil)
+
+			Convey("Then the email remains in the outbox, to be delivered later", func() {
+				So(pending(s, testTime.Add(time.Minute)), ShouldHaveLength, 1)
+			})
+		})
+	})
+}
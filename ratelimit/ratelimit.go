@@ -0,0 +1,251 @@
+// Package ratelimit limits the rate of requests per authenticated caller and per client IP with token buckets
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ONSdigital/dp-feedback-api/config"
+)
+
+// Now returns the current time, used to refill the token buckets
+var Now = time.Now
+
+// Limiter is a set of token buckets, one per key. Every bucket holds up to burst tokens and is refilled at a constant rate,
+// and every request takes a token from the bucket of its key, or is rejected if the bucket is empty.
+// A nil Limiter allows every request.
+type Limiter struct {
+	rate  float64 // tokens per second
+	burst float64
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter returns a Limiter that allows perMinute requests per minute and key, with bursts of up to burst requests.
+// A burst lower than 1 is treated as 1. It returns nil, which allows every request, if perMinute is not positive.
+func NewLimiter(perMinute, burst int) *Limiter {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &Limiter{
+		rate:    float64(perMinute) / 60,
+		burst:   math.Max(float64(burst), 1),
+		buckets: map[string]*bucket{},
+	}
+}
+
+// Allow takes a token from the bucket of the provided key. If the bucket is empty, it returns false
+// and how long it will take for a token to become available.
+func (l *Limiter) Allow(key string) (allowed bool, retryAfter time.Duration) {
+	return allow(Now(), limit{l, key})
+}
+
+// limit is the bucket of a key in a Limiter
+type limit struct {
+	limiter *Limiter
+	key     string
+}
+
+// allow takes a token from every provided bucket, but only if none of them is empty, so that a request rejected by one limiter
+// does not use up the tokens of the others. Otherwise, it returns false and how long it will take for all of them to have a token.
+// The limiters are locked in the provided order, which must always be the same. Nil limiters allow every request.
+func allow(now time.Time, limits ...limit) (allowed bool, retryAfter time.Duration) {
+	buckets := make([]*bucket, 0, len(limits))
+	for _, lim := range limits {
+		l := lim.limiter
+		if l == nil {
+			continue
+		}
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		b := l.bucket(lim.key, now)
+		if b.tokens < 1 {
+			retryAfter = max(retryAfter, time.Duration((1-b.tokens)/l.rate*float64(time.Second)))
+		}
+		buckets = append(buckets, b)
+	}
+	if retryAfter > 0 {
+		return false, retryAfter
+	}
+
+	for _, b := range buckets {
+		b.tokens--
+	}
+	return true, 0
+}
+
+// bucket returns the bucket of the provided key, refilled up to the provided time. It must be called with the limiter locked.
+func (l *Limiter) bucket(key string, now time.Time) *bucket {
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = l.refill(b, now)
+	b.last = now
+	return b
+}
+
+// refill returns the tokens of the provided bucket at the provided time
+func (l *Limiter) refill(b *bucket, now time.Time) float64 {
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	return math.Min(l.burst, b.tokens+elapsed*l.rate)
+}
+
+// sweep removes the buckets that are full, as they are the same as new buckets, so that the buckets of the keys
+// that stopped sending requests do not accumulate. It runs at most once per the time it takes to fill an empty bucket.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep).Seconds() < l.burst/l.rate {
+		return
+	}
+	for key, b := range l.buckets {
+		if l.refill(b, now) >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
+
+// RateLimiter limits the rate of requests per authenticated caller and per client IP.
+// The client IP is taken from the X-Forwarded-For header of the requests received from trusted proxies.
+type RateLimiter struct {
+	callers        *Limiter
+	ips            *Limiter
+	trustedProxies []netip.Prefix
+}
+
+// New returns a RateLimiter with the provided configuration. It fails if a trusted proxy is not a valid IP or CIDR range.
+func New(cfg *config.RateLimit) (*RateLimiter, error) {
+	trustedProxies, err := ParsePrefixes(cfg.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	return &RateLimiter{
+		callers:        NewLimiter(cfg.CallerPerMinute, cfg.CallerBurst),
+		ips:            NewLimiter(cfg.IPPerMinute, cfg.IPBurst),
+		trustedProxies: trustedProxies,
+	}, nil
+}
+
+// Allow returns whether the provided request, made by the provided caller, is within the rate limits of both the caller
+// and the client IP. If it is not, it also returns how long the client should wait before retrying.
+// A token is only taken from the bucket of either if the request is within both limits.
+// Requests without a caller are only limited per client IP, and requests whose client IP is a trusted proxy,
+// e.g. a frontend that does not forward the address of its users, are only limited per caller,
+// so that the submissions of all its users do not share the same limit.
+func (rl *RateLimiter) Allow(caller string, r *http.Request) (allowed bool, retryAfter time.Duration) {
+	var limits []limit
+	if ip := ClientIP(r, rl.trustedProxies); !rl.isTrusted(ip) {
+		limits = append(limits, limit{rl.ips, ip})
+	}
+	if caller != "" {
+		limits = append(limits, limit{rl.callers, caller})
+	}
+	return allow(Now(), limits...)
+}
+
+// isTrusted returns true if the provided client IP belongs to any of the trusted proxies
+func (rl *RateLimiter) isTrusted(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	return err == nil && isTrusted(addr, rl.trustedProxies)
+}
+
+// ParsePrefixes parses the provided IP addresses and CIDR ranges, e.g. "10.0.0.1" or "10.0.0.0/8"
+func ParsePrefixes(values []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if strings.Contains(v, "/") {
+			p, err := netip.ParsePrefix(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", v, err)
+			}
+			prefixes = append(prefixes, p.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", v, err)
+		}
+		addr = addr.Unmap()
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// ClientIP returns the IP address of the client that made the provided request.
+// If the request was received from a trusted proxy, the X-Forwarded-For header is walked from right to left,
+// and the client is the first address that is not a trusted proxy, as the addresses on its left could be forged.
+// If every forwarded address is trusted, the client is the left-most one.
+func ClientIP(r *http.Request, trustedProxies []netip.Prefix) string {
+	remote, ok := parseAddr(r.RemoteAddr)
+	if !ok {
+		return r.RemoteAddr
+	}
+	if !isTrusted(remote, trustedProxies) {
+		return remote.String()
+	}
+
+	var forwarded []string
+	for _, h := range r.Header.Values("X-Forwarded-For") {
+		forwarded = append(forwarded, strings.Split(h, ",")...)
+	}
+
+	client := remote
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		addr, ok := parseAddr(strings.TrimSpace(forwarded[i]))
+		if !ok {
+			// the addresses from here on cannot be trusted, so the last trusted proxy is the client
+			break
+		}
+		client = addr
+		if !isTrusted(addr, trustedProxies) {
+			break
+		}
+	}
+	return client.String()
+}
+
+// parseAddr parses an IP address, with or without a port
+func parseAddr(s string) (netip.Addr, bool) {
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap().WithZone(""), true
+}
+
+// isTrusted returns true if the provided address belongs to any of the trusted proxies
+func isTrusted(addr netip.Addr, trustedProxies []netip.Prefix) bool {
+	for _, p := range trustedProxies {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,197 @@
+package ratelimit_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-feedback-api/config"
+	"github.com/ONSdigital/dp-feedback-api/ratelimit"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var testTime = time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+
+// setClock makes the rate limiters use a clock that starts at testTime, and returns a function to move it forward
+func setClock(t *testing.T) func(d time.Duration) {
+	now := ratelimit.Now
+	t.Cleanup(func() { ratelimit.Now = now })
+
+	current := testTime
+	ratelimit.Now = func() time.Time { return current }
+	return func(d time.Duration) { current = current.Add(d) }
+}
+
+func newRequest(remoteAddr string, forwardedFor ...string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/feedback", http.NoBody)
+	r.RemoteAddr = remoteAddr
+	for _, f := range forwardedFor {
+		r.Header.Add("X-Forwarded-For", f)
+	}
+	return r
+}
+
+func TestLimiter(t *testing.T) {
+	Convey("Given a limiter of 60 requests per minute with bursts of 2", t, func() {
+		advance := setClock(t)
+		l := ratelimit.NewLimiter(60, 2)
+
+		Convey("Then a burst of 2 requests is allowed, and the next one is rejected until a token is refilled", func() {
+			ok, _ := l.Allow("a")
+			So(ok, ShouldBeTrue)
+			ok, _ = l.Allow("a")
+			So(ok, ShouldBeTrue)
+			ok, retryAfter := l.Allow("a")
+			So(ok, ShouldBeFalse)
+			So(retryAfter, ShouldEqual, time.Second)
+
+			advance(500 * time.Millisecond)
+			ok, retryAfter = l.Allow("a")
+			So(ok, ShouldBeFalse)
+			So(retryAfter, ShouldEqual, 500*time.Millisecond)
+
+			advance(500 * time.Millisecond)
+			ok, _ = l.Allow("a")
+			So(ok, ShouldBeTrue)
+		})
+
+		Convey("Then every key has its own bucket", func() {
+			l.Allow("a")
+			l.Allow("a")
+			ok, _ := l.Allow("b")
+			So(ok, ShouldBeTrue)
+		})
+
+		Convey("Then the buckets are not refilled beyond the burst", func() {
+			advance(time.Hour)
+			l.Allow("a")
+			l.Allow("a")
+			ok, _ := l.Allow("a")
+			So(ok, ShouldBeFalse)
+		})
+	})
+
+	Convey("A limiter without a rate allows every request", t, func() {
+		l := ratelimit.NewLimiter(0, 10)
+		So(l, ShouldBeNil)
+		for i := 0; i < 100; i++ {
+			ok, _ := l.Allow("a")
+			So(ok, ShouldBeTrue)
+		}
+	})
+}
+
+func TestClientIP(t *testing.T) {
+	trusted, err := ratelimit.ParsePrefixes([]string{"10.0.0.0/8", "192.168.1.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Convey("The client IP is the remote address if it is not a trusted proxy, even if it forwards another address", t, func() {
+		So(ratelimit.ClientIP(newRequest("203.0.113.1:1234", "198.51.100.1"), trusted), ShouldEqual, "203.0.113.1")
+	})
+
+	Convey("The client IP is the forwarded address of a request received from a trusted proxy", t, func() {
+		So(ratelimit.ClientIP(newRequest("10.0.0.1:1234", "198.51.100.1"), trusted), ShouldEqual, "198.51.100.1")
+	})
+
+	Convey("The client IP is the right-most forwarded address that is not a trusted proxy, ignoring any forged addresses on its left", t, func() {
+		r := newRequest("10.0.0.1:1234", "1.2.3.4, 198.51.100.1", "192.168.1.1")
+		So(ratelimit.ClientIP(r, trusted), ShouldEqual, "198.51.100.1")
+	})
+
+	Convey("The client IP is the left-most forwarded address if all of them are trusted proxies", t, func() {
+		So(ratelimit.ClientIP(newRequest("10.0.0.1:1234", "10.0.0.3, 10.0.0.2"), trusted), ShouldEqual, "10.0.0.3")
+	})
+
+	Convey("The client IP is the last trusted proxy if the next forwarded address is not valid", t, func() {
+		So(ratelimit.ClientIP(newRequest("10.0.0.1:1234", "198.51.100.1, unknown, 10.0.0.2"), trusted), ShouldEqual, "10.0.0.2")
+	})
+
+	Convey("The client IP is the remote address of a trusted proxy that does not forward any address", t, func() {
+		So(ratelimit.ClientIP(newRequest("[::ffff:10.0.0.1]:1234"), trusted), ShouldEqual, "10.0.0.1")
+	})
+}
+
+func TestParsePrefixes(t *testing.T) {
+	Convey("IP addresses and CIDR ranges are parsed", t, func() {
+		prefixes, err := ratelimit.ParsePrefixes([]string{"10.1.2.3/8", " ::1 ", ""})
+		So(err, ShouldBeNil)
+		So(prefixes, ShouldHaveLength, 2)
+		So(prefixes[0].String(), ShouldEqual, "10.0.0.0/8")
+		So(prefixes[1].String(), ShouldEqual, "::1/128")
+	})
+
+	Convey("Invalid addresses are rejected", t, func() {
+		_, err := ratelimit.ParsePrefixes([]string{"10.0.0.0/33"})
+		So(err, ShouldNotBeNil)
+		_, err = ratelimit.ParsePrefixes([]string{"proxy.internal"})
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldContainSubstring, "invalid trusted proxy")
+	})
+}
+
+func TestRateLimiter(t *testing.T) {
+	Convey("Given a rate limiter with a lower limit per client IP than per caller", t, func() {
+		setClock(t)
+		rl, err := ratelimit.New(&config.RateLimit{
+			CallerPerMinute: 60,
+			CallerBurst:     3,
+			IPPerMinute:     60,
+			IPBurst:         2,
+			TrustedProxies:  []string{"10.0.0.0/8"},
+		})
+		So(err, ShouldBeNil)
+
+		Convey("Then the requests of a client IP are limited, even if the caller still has capacity", func() {
+			ok, _ := rl.Allow("frontend", newRequest("10.0.0.1:1234", "198.51.100.1"))
+			So(ok, ShouldBeTrue)
+			ok, _ = rl.Allow("frontend", newRequest("10.0.0.1:1234", "198.51.100.1"))
+			So(ok, ShouldBeTrue)
+			ok, retryAfter := rl.Allow("frontend", newRequest("10.0.0.1:1234", "198.51.100.1"))
+			So(ok, ShouldBeFalse)
+			So(retryAfter, ShouldEqual, time.Second)
+
+			Convey("And the requests of the caller from other client IPs are limited by the caller limit", func() {
+				ok, _ := rl.Allow("frontend", newRequest("10.0.0.1:1234", "198.51.100.2"))
+				So(ok, ShouldBeTrue)
+				ok, _ = rl.Allow("frontend", newRequest("10.0.0.1:1234", "198.51.100.3"))
+				So(ok, ShouldBeFalse)
+			})
+		})
+
+		Convey("Then a request rejected by the caller limit does not take a token from the bucket of its client IP", func() {
+			for _, ip := range []string{"198.51.100.1", "198.51.100.2", "198.51.100.3"} {
+				ok, _ := rl.Allow("frontend", newRequest("10.0.0.1:1234", ip))
+				So(ok, ShouldBeTrue)
+			}
+			ok, _ := rl.Allow("frontend", newRequest("10.0.0.1:1234", "198.51.100.4"))
+			So(ok, ShouldBeFalse)
+
+			for i := 0; i < 3; i++ {
+				ok, _ := rl.Allow("other", newRequest("10.0.0.1:1234", "198.51.100.4"))
+				So(ok, ShouldEqual, i < 2)
+			}
+		})
+
+		Convey("Then the requests of a trusted proxy that does not forward the client IP are only limited per caller", func() {
+			for i := 0; i < 4; i++ {
+				ok, _ := rl.Allow("frontend", newRequest("10.0.0.1:1234"))
+				So(ok, ShouldEqual, i < 3)
+			}
+		})
+
+		Convey("Then requests without a caller are only limited per client IP", func() {
+			for i := 0; i < 4; i++ {
+				ok, _ := rl.Allow("", newRequest("203.0.113.1:1234"))
+				So(ok, ShouldEqual, i < 2)
+			}
+		})
+	})
+
+	Convey("A rate limiter cannot be created with invalid trusted proxies", t, func() {
+		_, err := ratelimit.New(&config.RateLimit{TrustedProxies: []string{"not-an-ip"}})
+		So(err, ShouldNotBeNil)
+	})
+}
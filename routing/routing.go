@@ -0,0 +1,307 @@
+package routing
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ONSdigital/dp-feedback-api/models"
+)
+
+// Feedback types that a route can be restricted to
+const (
+	FeedbackTypeGeneral  = "general"
+	FeedbackTypeSpecific = "specific"
+)
+
+// Digest intervals that can be configured by name, in addition to any Go duration of at least MinDigestInterval
+const (
+	DigestHourly = "hourly"
+	DigestDaily  = "daily"
+)
+
+// MinDigestInterval is the shortest interval between the digest emails of a route
+const MinDigestInterval = time.Minute
+
+// Table is the routing table that decides which recipients feedback emails are sent to
+type Table struct {
+	// Default are the recipients of the feedback that does not match any route
+	Default []string `json:"default,omitempty"`
+	// Routes are all evaluated, and the feedback is sent to the recipients of all the routes it matches
+	Routes []*Route `json:"routes"`
+	// UrgentKeywords flag the feedback whose description contains any of them, as whole words ignoring case,
+	// which is sent straight away, even to the routes with a digest
+	UrgentKeywords []string `json:"urgent_keywords,omitempty"`
+
+	urgentKeywords []*regexp.Regexp
+}
+
+// Route sends the feedback that matches all of its conditions to its recipients.
+// A condition matches if any of its values match, and empty conditions match any feedback.
+type Route struct {
+	Name string `json:"name"`
+	// Hosts are matched against the host of the ons_url, ignoring case
+	Hosts []string `json:"hosts,omitempty"`
+	// PathPrefixes are matched against the whole path segments of the ons_url, ignoring case
+	PathPrefixes []string `json:"path_prefixes,omitempty"`
+	// FeedbackType is either "general" or "specific", or empty to match both
+	FeedbackType string `json:"feedback_type,omitempty"`
+	// Keywords are matched against the whole words of the feedback description, ignoring case
+	Keywords []string `json:"keywords,omitempty"`
+	// To are the recipients of the feedback that matches the route
+	To []string `json:"to"`
+	// Digest is either "hourly", "daily" or a Go duration, e.g. "30m", to send a single digest email per interval
+	// with all the feedback that matches the route, or empty to send an email per submission
+	Digest string `json:"digest,omitempty"`
+
+	keywords       []*regexp.Regexp
+	digestInterval time.Duration
+}
+
+// Load reads the routing table from the JSON file in path. If path is empty, all the feedback is sent to defaultTo,
+// which is also used when the file does not provide any default recipients.
+func Load(path, defaultTo string) (*Table, error) {
+	t := &Table{}
+	if path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read routes file: %w", err)
+		}
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(t); err != nil {
+			return nil, fmt.Errorf("failed to parse routes file: %w", err)
+		}
+	}
+	if len(t.Default) == 0 {
+		t.Default = []string{defaultTo}
+	}
+
+	if err := t.init(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// init validates the routing table, compiles the keywords and parses the digest intervals of its routes
+func (t *Table) init() error {
+	if err := validateRecipients(t.Default); err != nil {
+		return fmt.Errorf("invalid default recipients: %w", err)
+	}
+
+	var err error
+	if t.urgentKeywords, err = compileKeywords(t.UrgentKeywords); err != nil {
+		return fmt.Errorf("invalid urgent keywords: %w", err)
+	}
+
+	names := map[string]bool{}
+	for i, r := range t.Routes {
+		if r == nil {
+			return fmt.Errorf("invalid route %d: route is empty", i)
+		}
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("route %d", i)
+		}
+		if names[r.Name] {
+			return fmt.Errorf("invalid route '%s': duplicate name", r.Name)
+		}
+		names[r.Name] = true
+		if len(r.To) == 0 {
+			return fmt.Errorf("invalid route '%s': no recipients", r.Name)
+		}
+		if err := validateRecipients(r.To); err != nil {
+			return fmt.Errorf("invalid route '%s': %w", r.Name, err)
+		}
+		switch r.FeedbackType {
+		case "", FeedbackTypeGeneral, FeedbackTypeSpecific:
+		default:
+			return fmt.Errorf("invalid route '%s': unknown feedback type '%s'", r.Name, r.FeedbackType)
+		}
+		for _, prefix := range r.PathPrefixes {
+			if !strings.HasPrefix(prefix, "/") {
+				return fmt.Errorf("invalid route '%s': path prefix '%s' does not start with '/'", r.Name, prefix)
+			}
+		}
+
+		if r.keywords, err = compileKeywords(r.Keywords); err != nil {
+			return fmt.Errorf("invalid route '%s': %w", r.Name, err)
+		}
+		if r.digestInterval, err = parseDigestInterval(r.Digest); err != nil {
+			return fmt.Errorf("invalid route '%s': %w", r.Name, err)
+		}
+	}
+	return nil
+}
+
+// compileKeywords returns the regular expressions that match the keywords as whole words, ignoring case
+func compileKeywords(keywords []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(keywords))
+	for _, keyword := range keywords {
+		if strings.TrimSpace(keyword) == "" {
+			return nil, errors.New("empty keyword")
+		}
+		compiled = append(compiled, regexp.MustCompile(`(?i)\b`+regexp.QuoteMeta(strings.TrimSpace(keyword))+`\b`))
+	}
+	return compiled, nil
+}
+
+// parseDigestInterval returns the interval of a digest, or zero if there is no digest
+func parseDigestInterval(digest string) (time.Duration, error) {
+	switch digest {
+	case "":
+		return 0, nil
+	case DigestHourly:
+		return time.Hour, nil
+	case DigestDaily:
+		return 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(digest)
+	if err != nil {
+		return 0, fmt.Errorf("invalid digest '%s': must be '%s', '%s' or a duration", digest, DigestHourly, DigestDaily)
+	}
+	if d < MinDigestInterval {
+		return 0, fmt.Errorf("invalid digest '%s': must be at least %s", digest, MinDigestInterval)
+	}
+	return d, nil
+}
+
+// validateRecipients checks that all the recipients are valid email addresses
+func validateRecipients(to []string) error {
+	for _, addr := range to {
+		if _, err := mail.ParseAddress(addr); err != nil {
+			return fmt.Errorf("invalid recipient '%s': %w", addr, err)
+		}
+	}
+	return nil
+}
+
+// Match returns the routes that the feedback matches, in the order they are defined
+func (t *Table) Match(f *models.Feedback) []*Route {
+	host, path := splitURL(f.OnsURL)
+
+	var routes []*Route
+	for _, r := range t.Routes {
+		if r.matches(f, host, path) {
+			routes = append(routes, r)
+		}
+	}
+	return routes
+}
+
+// Recipients returns the recipients that the feedback is emailed to straight away, without duplicates: those of all the routes
+// that it matches without a digest, or with a digest if the feedback is urgent, or the default recipients if it does not match any route.
+// It returns no recipients if the feedback is only sent in digests.
+func (t *Table) Recipients(f *models.Feedback) []string {
+	routes := t.Match(f)
+	if len(routes) == 0 {
+		return t.Default
+	}
+
+	urgent := t.IsUrgent(f)
+	var to []string
+	seen := map[string]bool{}
+	for _, r := range routes {
+		if r.digestInterval > 0 && !urgent {
+			continue
+		}
+		for _, addr := range r.To {
+			if key := strings.ToLower(addr); !seen[key] {
+				seen[key] = true
+				to = append(to, addr)
+			}
+		}
+	}
+	return to
+}
+
+// Digests returns the routes with a digest that the feedback matches, unless it is urgent
+func (t *Table) Digests(f *models.Feedback) []*Route {
+	if t.IsUrgent(f) {
+		return nil
+	}
+
+	var routes []*Route
+	for _, r := range t.Match(f) {
+		if r.digestInterval > 0 {
+			routes = append(routes, r)
+		}
+	}
+	return routes
+}
+
+// DigestRoutes returns all the routes with a digest, in the order they are defined
+func (t *Table) DigestRoutes() []*Route {
+	var routes []*Route
+	for _, r := range t.Routes {
+		if r.digestInterval > 0 {
+			routes = append(routes, r)
+		}
+	}
+	return routes
+}
+
+// IsUrgent returns true if the feedback description contains any of the urgent keywords
+func (t *Table) IsUrgent(f *models.Feedback) bool {
+	return matchesAny(t.urgentKeywords, func(k *regexp.Regexp) bool { return k.MatchString(f.Feedback) })
+}
+
+// DigestInterval returns the interval between the digest emails of the route, or zero if it has no digest
+func (r *Route) DigestInterval() time.Duration {
+	return r.digestInterval
+}
+
+// matches returns true if the feedback, whose ons_url has the provided host and path, matches all the conditions of the route
+func (r *Route) matches(f *models.Feedback, host, path string) bool {
+	isGeneral := f.IsGeneralFeedback != nil && *f.IsGeneralFeedback
+	switch {
+	case r.FeedbackType == FeedbackTypeGeneral && !isGeneral:
+		return false
+	case r.FeedbackType == FeedbackTypeSpecific && isGeneral:
+		return false
+	case len(r.Hosts) > 0 && !matchesAny(r.Hosts, func(h string) bool { return strings.EqualFold(h, host) }):
+		return false
+	case len(r.PathPrefixes) > 0 && !matchesAny(r.PathPrefixes, func(p string) bool { return hasPathPrefix(path, p) }):
+		return false
+	case len(r.keywords) > 0 && !matchesAny(r.keywords, func(k *regexp.Regexp) bool { return k.MatchString(f.Feedback) }):
+		return false
+	}
+	return true
+}
+
+// matchesAny returns true if match is true for any of the values
+func matchesAny[T any](values []T, match func(T) bool) bool {
+	for _, v := range values {
+		if match(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPathPrefix returns true if path starts with the whole segments of prefix, ignoring case,
+// e.g. '/census' is a prefix of '/census' and '/census/data', but not of '/censuses'
+func hasPathPrefix(path, prefix string) bool {
+	path = strings.ToLower(path)
+	prefix = strings.ToLower(strings.TrimSuffix(prefix, "/"))
+	return prefix == "" || path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// splitURL returns the host and path of an ons_url, which may not have a scheme, or empty strings if it is not a URL
+func splitURL(onsURL string) (host, path string) {
+	if onsURL == "" {
+		return "", ""
+	}
+	u, err := url.Parse(models.NormaliseURL(onsURL))
+	if err != nil {
+		return "", ""
+	}
+	return u.Hostname(), u.Path
+}
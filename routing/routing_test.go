@@ -0,0 +1,179 @@
+package routing_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-feedback-api/models"
+	"github.com/ONSdigital/dp-feedback-api/routing"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const testRoutes = `{
+	"default": ["feedback@ons.gov.uk"],
+	"routes": [
+		{"name": "census", "path_prefixes": ["/census/"], "to": ["census@ons.gov.uk"]},
+		{"name": "economy", "hosts": ["www.ons.gov.uk"], "path_prefixes": ["/economy"], "to": ["economy@ons.gov.uk", "gdp@ons.gov.uk"]},
+		{"name": "releases", "feedback_type": "specific", "keywords": ["release calendar", "Published"], "to": ["releases@ons.gov.uk", "Census@ons.gov.uk"]},
+		{"name": "website", "feedback_type": "general", "to": ["web@ons.gov.uk"]}
+	]
+}`
+
+var (
+	general  = true
+	specific = false
+)
+
+// writeRoutes writes the routing table to a file in dir and returns its path
+func writeRoutes(dir, content string) string {
+	path := filepath.Join(dir, "routes.json")
+	So(os.WriteFile(path, []byte(content), 0o600), ShouldBeNil)
+	return path
+}
+
+func TestRecipients(t *testing.T) {
+	Convey("Given a routing table loaded from a file", t, func() {
+		table, err := routing.Load(writeRoutes(t.TempDir(), testRoutes), "fallback@ons.gov.uk")
+		So(err, ShouldBeNil)
+
+		Convey("Then feedback is routed by the whole path segments of its ons_url, with or without scheme", func() {
+			So(table.Recipients(&models.Feedback{OnsURL: "https://www.ons.gov.uk/census", IsGeneralFeedback: &specific}),
+				ShouldResemble, []string{"census@ons.gov.uk"})
+			So(table.Recipients(&models.Feedback{OnsURL: "localhost/Census/data", IsGeneralFeedback: &specific}),
+				ShouldResemble, []string{"census@ons.gov.uk"})
+			So(table.Recipients(&models.Feedback{OnsURL: "https://www.ons.gov.uk/censuses", IsGeneralFeedback: &specific}),
+				ShouldResemble, []string{"feedback@ons.gov.uk"})
+		})
+
+		Convey("Then feedback is only routed when it matches all the conditions of a route", func() {
+			So(table.Recipients(&models.Feedback{OnsURL: "https://WWW.ons.gov.uk/economy/gdp", IsGeneralFeedback: &specific}),
+				ShouldResemble, []string{"economy@ons.gov.uk", "gdp@ons.gov.uk"})
+			So(table.Recipients(&models.Feedback{OnsURL: "https://localhost/economy/gdp", IsGeneralFeedback: &specific}),
+				ShouldResemble, []string{"feedback@ons.gov.uk"})
+		})
+
+		Convey("Then feedback is routed by the whole words of its description, ignoring case", func() {
+			So(table.Recipients(&models.Feedback{Feedback: "the RELEASE calendar is wrong", IsGeneralFeedback: &specific}),
+				ShouldResemble, []string{"releases@ons.gov.uk", "Census@ons.gov.uk"})
+			So(table.Recipients(&models.Feedback{Feedback: "it was unpublished", IsGeneralFeedback: &specific}),
+				ShouldResemble, []string{"feedback@ons.gov.uk"})
+			So(table.Recipients(&models.Feedback{Feedback: "it was published", IsGeneralFeedback: &general}),
+				ShouldResemble, []string{"web@ons.gov.uk"})
+		})
+
+		Convey("Then feedback matching several routes is sent to the recipients of all of them, without duplicates", func() {
+			f := &models.Feedback{OnsURL: "https://localhost/census", Feedback: "not published", IsGeneralFeedback: &specific}
+			So(table.Recipients(f), ShouldResemble, []string{"census@ons.gov.uk", "releases@ons.gov.uk"})
+			So(table.Match(f), ShouldHaveLength, 2)
+		})
+
+		Convey("Then feedback that does not match any route is sent to the default recipients", func() {
+			f := &models.Feedback{OnsURL: "The whole website", IsGeneralFeedback: &specific}
+			So(table.Match(f), ShouldBeEmpty)
+			So(table.Recipients(f), ShouldResemble, []string{"feedback@ons.gov.uk"})
+		})
+	})
+
+	Convey("Given a routing table loaded without a file", t, func() {
+		table, err := routing.Load("", "fallback@ons.gov.uk")
+		So(err, ShouldBeNil)
+
+		Convey("Then all the feedback is sent to the fallback recipient", func() {
+			So(table.Recipients(&models.Feedback{OnsURL: "https://localhost/census", IsGeneralFeedback: &specific}),
+				ShouldResemble, []string{"fallback@ons.gov.uk"})
+		})
+	})
+
+	Convey("Given a routing table file without default recipients", t, func() {
+		table, err := routing.Load(writeRoutes(t.TempDir(), `{"routes": []}`), "fallback@ons.gov.uk")
+		So(err, ShouldBeNil)
+
+		Convey("Then the fallback recipient is used as default", func() {
+			So(table.Default, ShouldResemble, []string{"fallback@ons.gov.uk"})
+		})
+	})
+}
+
+func TestDigests(t *testing.T) {
+	Convey("Given a routing table with digest routes and urgent keywords", t, func() {
+		table, err := routing.Load(writeRoutes(t.TempDir(), `{
+			"urgent_keywords": ["data breach"],
+			"routes": [
+				{"name": "census", "path_prefixes": ["/census"], "digest": "daily", "to": ["census@ons.gov.uk"]},
+				{"name": "releases", "path_prefixes": ["/releases"], "digest": "30m", "to": ["releases@ons.gov.uk"]},
+				{"name": "all", "to": ["all@ons.gov.uk"]}
+			]
+		}`), "fallback@ons.gov.uk")
+		So(err, ShouldBeNil)
+
+		Convey("Then the digest intervals of the routes are parsed", func() {
+			So(table.DigestRoutes(), ShouldHaveLength, 2)
+			So(table.DigestRoutes()[0].DigestInterval(), ShouldEqual, 24*time.Hour)
+			So(table.DigestRoutes()[1].DigestInterval(), ShouldEqual, 30*time.Minute)
+			So(table.Routes[2].DigestInterval(), ShouldEqual, 0)
+		})
+
+		Convey("Then feedback matching a digest route is added to its digest instead of being emailed to its recipients", func() {
+			f := &models.Feedback{OnsURL: "https://localhost/census", Feedback: "wrong figures", IsGeneralFeedback: &specific}
+			So(table.Recipients(f), ShouldResemble, []string{"all@ons.gov.uk"})
+			So(table.Digests(f), ShouldHaveLength, 1)
+			So(table.Digests(f)[0].Name, ShouldEqual, "census")
+			So(table.IsUrgent(f), ShouldBeFalse)
+		})
+
+		Convey("Then urgent feedback is emailed straight away to the recipients of all the routes it matches", func() {
+			f := &models.Feedback{OnsURL: "https://localhost/census", Feedback: "Possible DATA BREACH on this page", IsGeneralFeedback: &specific}
+			So(table.IsUrgent(f), ShouldBeTrue)
+			So(table.Recipients(f), ShouldResemble, []string{"census@ons.gov.uk", "all@ons.gov.uk"})
+			So(table.Digests(f), ShouldBeEmpty)
+		})
+	})
+
+	Convey("Given a routing table where all the routes have a digest", t, func() {
+		table, err := routing.Load(writeRoutes(t.TempDir(), `{"routes": [{"name": "census", "digest": "hourly", "to": ["census@ons.gov.uk"]}]}`), "fallback@ons.gov.uk")
+		So(err, ShouldBeNil)
+
+		Convey("Then no email is sent straight away", func() {
+			f := &models.Feedback{Feedback: "wrong figures", IsGeneralFeedback: &general}
+			So(table.Recipients(f), ShouldBeEmpty)
+			So(table.Digests(f), ShouldHaveLength, 1)
+			So(table.Digests(f)[0].DigestInterval(), ShouldEqual, time.Hour)
+		})
+	})
+}
+
+func TestLoad(t *testing.T) {
+	Convey("Given invalid routing table files", t, func() {
+		dir := t.TempDir()
+
+		Convey("Then loading them fails with the reason", func() {
+			for content, reason := range map[string]string{
+				`{"routes": [`:                                                                               "failed to parse routes file",
+				`{"routes": [], "unknown": true}`:                                                            "unknown field",
+				`{"default": ["not an email"], "routes": []}`:                                                "invalid default recipients",
+				`{"routes": [{"name": "census", "path_prefixes": ["/census"]}]}`:                             "invalid route 'census': no recipients",
+				`{"routes": [{"name": "census", "to": ["census"]}]}`:                                         "invalid route 'census': invalid recipient 'census'",
+				`{"routes": [{"name": "census", "feedback_type": "all", "to": ["census@ons.gov.uk"]}]}`:      "unknown feedback type 'all'",
+				`{"routes": [{"name": "census", "path_prefixes": ["census"], "to": ["census@ons.gov.uk"]}]}`: "path prefix 'census' does not start with '/'",
+				`{"routes": [{"keywords": [" "], "to": ["census@ons.gov.uk"]}]}`:                             "invalid route 'route 0': empty keyword",
+				`{"routes": [{"name": "a", "to": ["a@ons.gov.uk"]}, {"name": "a", "to": ["b@ons.gov.uk"]}]}`: "invalid route 'a': duplicate name",
+				`{"routes": [{"name": "a", "digest": "weekly", "to": ["a@ons.gov.uk"]}]}`:                    "invalid digest 'weekly'",
+				`{"routes": [{"name": "a", "digest": "30s", "to": ["a@ons.gov.uk"]}]}`:                       "must be at least 1m0s",
+				`{"urgent_keywords": [""], "routes": []}`:                                                    "invalid urgent keywords: empty keyword",
+				`{"routes": [null]}`: "invalid route 0: route is empty",
+			} {
+				_, err := routing.Load(writeRoutes(dir, content), "fallback@ons.gov.uk")
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldContainSubstring, reason)
+			}
+		})
+
+		Convey("Then loading a file that does not exist fails", func() {
+			_, err := routing.Load(filepath.Join(dir, "missing.json"), "fallback@ons.gov.uk")
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "failed to read routes file")
+		})
+	})
+}
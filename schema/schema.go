@@ -0,0 +1,24 @@
+// Package schema contains the Avro schemas of the Kafka messages produced by the service
+package schema
+
+import (
+	"github.com/ONSdigital/dp-kafka/v4/avro"
+)
+
+var feedbackReceivedEvent = `{
+  "type": "record",
+  "name": "feedback-received",
+  "fields": [
+    {"name": "id", "type": "string", "default": ""},
+    {"name": "received_at", "type": "string", "default": ""},
+    {"name": "is_page_useful", "type": "boolean", "default": false},
+    {"name": "is_general_feedback", "type": "boolean", "default": false},
+    {"name": "ons_url", "type": "string", "default": ""},
+    {"name": "feedback", "type": "string", "default": ""}
+  ]
+}`
+
+// FeedbackReceivedEvent is the Avro schema for feedback-received messages
+var FeedbackReceivedEvent = &avro.Schema{
+	Definition: feedbackReceivedEvent,
+}
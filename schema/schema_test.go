@@ -0,0 +1,34 @@
+package schema_test
+
+import (
+	"testing"
+
+	"github.com/ONSdigital/dp-feedback-api/models"
+	"github.com/ONSdigital/dp-feedback-api/schema"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFeedbackReceivedEvent(t *testing.T) {
+	Convey("Given a feedback-received event", t, func() {
+		event := &models.FeedbackReceived{
+			ID:                "feedback-1",
+			ReceivedAt:        "2024-03-15T10:30:00Z",
+			IsPageUseful:      false,
+			IsGeneralFeedback: true,
+			OnsURL:            "https://localhost/subpath",
+			Feedback:          "broken link",
+		}
+
+		Convey("When it is marshalled with the schema", func() {
+			b, err := schema.FeedbackReceivedEvent.Marshal(event)
+			So(err, ShouldBeNil)
+			So(b, ShouldNotBeEmpty)
+
+			Convey("Then it can be unmarshalled back to the same event", func() {
+				unmarshalled := &models.FeedbackReceived{}
+				So(schema.FeedbackReceivedEvent.Unmarshal(b, unmarshalled), ShouldBeNil)
+				So(unmarshalled, ShouldResemble, event)
+			})
+		})
+	})
+}
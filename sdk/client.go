@@ -5,20 +5,27 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 
 	healthcheck "github.com/ONSdigital/dp-api-clients-go/v2/health"
 	"github.com/ONSdigital/dp-feedback-api/models"
 	sdkError "github.com/ONSdigital/dp-feedback-api/sdk/errors"
 	health "github.com/ONSdigital/dp-healthcheck/healthcheck"
+	"github.com/gofrs/uuid"
 )
 
 // package level constants
 const (
-	Service          = "dp-feedback-api"
-	FeedbackEndpoint = "%s/feedback"
-	Authorization    = "Authorization"
-	BearerPrefix     = "Bearer "
+	Service              = "dp-feedback-api"
+	FeedbackEndpoint     = "%s/feedback"
+	FeedbackItemEndpoint = "%s/feedback/%s"
+	SummaryEndpoint      = "%s/feedback/summary"
+	Authorization        = "Authorization"
+	BearerPrefix         = "Bearer "
+	IdempotencyKey       = "Idempotency-Key"
+	ForwardedFor         = "X-Forwarded-For"
 )
 
 // HTTPClient is the interface that defines a client for making HTTP requests
@@ -34,6 +41,16 @@ type Client struct {
 // Options is a struct containing for customised options for the API client
 type Options struct {
 	AuthToken string
+	Query     url.Values
+	// IdempotencyKey is sent with PostFeedback, so that its retries return the original response instead of submitting the feedback again
+	IdempotencyKey string
+	// GenerateIdempotencyKey sends a new random idempotency key with every PostFeedback call if IdempotencyKey is empty,
+	// so that the retries of the request by the HTTP client do not submit the feedback twice
+	GenerateIdempotencyKey bool
+	// ClientIP is the IP address of the user that submitted the feedback, e.g. to the frontend that calls PostFeedback.
+	// It is sent in the X-Forwarded-For header, so that submissions are rate limited by user
+	// rather than only by caller, as long as the caller is one of the proxies trusted by the API.
+	ClientIP string
 }
 
 func (o *Options) SetAuth(req *http.Request) {
@@ -42,6 +59,31 @@ func (o *Options) SetAuth(req *http.Request) {
 	}
 }
 
+// SetQuery adds the query parameters provided in the options to the request URL, if any
+func (o *Options) SetQuery(req *http.Request) {
+	if len(o.Query) > 0 {
+		req.URL.RawQuery = o.Query.Encode()
+	}
+}
+
+// SetIdempotencyKey adds the idempotency key provided in the options to the request, or a new random one if GenerateIdempotencyKey is set
+func (o *Options) SetIdempotencyKey(req *http.Request) {
+	key := o.IdempotencyKey
+	if key == "" && o.GenerateIdempotencyKey {
+		key = uuid.Must(uuid.NewV4()).String()
+	}
+	if key != "" {
+		req.Header.Set(IdempotencyKey, key)
+	}
+}
+
+// SetClientIP adds the client IP provided in the options to the request as the X-Forwarded-For header, if any
+func (o *Options) SetClientIP(req *http.Request) {
+	if o.ClientIP != "" {
+		req.Header.Set(ForwardedFor, o.ClientIP)
+	}
+}
+
 // New constructs a new Client instance with a given feedback api url
 func New(feedbackAPIURL string) *Client {
 	return &Client{
@@ -72,14 +114,16 @@ func (cli *Client) Checker(ctx context.Context, check *health.CheckState) error
 	return cli.hcCli.Checker(ctx, check)
 }
 
-// PostFeedback sends the provided feedback model to the feedback API via a post call
-func (cli *Client) PostFeedback(ctx context.Context, feedback *models.Feedback, options Options) *sdkError.StatusError {
+// PostFeedback sends the provided feedback model to the feedback API via a post call, and returns the created feedback.
+// An idempotency key can be provided, or generated, in the options so that retries do not submit the feedback twice,
+// as well as the IP address of the user that submitted the feedback.
+func (cli *Client) PostFeedback(ctx context.Context, feedback *models.Feedback, options Options) (*models.Feedback, *sdkError.StatusError) {
 	uri := fmt.Sprintf(FeedbackEndpoint, cli.hcCli.URL)
 
 	buf := &bytes.Buffer{}
 	err := json.NewEncoder(buf).Encode(feedback)
 	if err != nil {
-		return &sdkError.StatusError{
+		return nil, &sdkError.StatusError{
 			Err:  fmt.Errorf("failed to encode feedback: %w", err),
 			Code: http.StatusInternalServerError,
 		}
@@ -87,7 +131,30 @@ func (cli *Client) PostFeedback(ctx context.Context, feedback *models.Feedback,
 
 	req, err := http.NewRequest(http.MethodPost, uri, buf)
 	if err != nil {
-		return &sdkError.StatusError{
+		return nil, &sdkError.StatusError{
+			Err:  fmt.Errorf("error creating request: %w", err),
+			Code: http.StatusInternalServerError,
+		}
+	}
+
+	options.SetAuth(req)
+	options.SetIdempotencyKey(req)
+	options.SetClientIP(req)
+
+	created := &models.Feedback{}
+	if errStatus := cli.callFeedbackAPI(ctx, req, http.StatusCreated, created); errStatus != nil {
+		return nil, errStatus
+	}
+	return created, nil
+}
+
+// GetFeedback returns the stored feedback with the provided id
+func (cli *Client) GetFeedback(ctx context.Context, id string, options Options) (*models.Feedback, *sdkError.StatusError) {
+	uri := fmt.Sprintf(FeedbackItemEndpoint, cli.hcCli.URL, url.PathEscape(id))
+
+	req, err := http.NewRequest(http.MethodGet, uri, http.NoBody)
+	if err != nil {
+		return nil, &sdkError.StatusError{
 			Err:  fmt.Errorf("error creating request: %w", err),
 			Code: http.StatusInternalServerError,
 		}
@@ -95,6 +162,62 @@ func (cli *Client) PostFeedback(ctx context.Context, feedback *models.Feedback,
 
 	options.SetAuth(req)
 
+	feedback := &models.Feedback{}
+	if errStatus := cli.callFeedbackAPI(ctx, req, http.StatusOK, feedback); errStatus != nil {
+		return nil, errStatus
+	}
+	return feedback, nil
+}
+
+// GetFeedbackList returns a page of the stored feedback. Filters and pagination can be provided as query parameters in the options,
+// e.g. 'is_page_useful', 'is_general_feedback', 'ons_url', 'start_date', 'end_date', 'offset' and 'limit'.
+func (cli *Client) GetFeedbackList(ctx context.Context, options Options) (*models.FeedbackList, *sdkError.StatusError) {
+	uri := fmt.Sprintf(FeedbackEndpoint, cli.hcCli.URL)
+
+	req, err := http.NewRequest(http.MethodGet, uri, http.NoBody)
+	if err != nil {
+		return nil, &sdkError.StatusError{
+			Err:  fmt.Errorf("error creating request: %w", err),
+			Code: http.StatusInternalServerError,
+		}
+	}
+
+	options.SetAuth(req)
+	options.SetQuery(req)
+
+	list := &models.FeedbackList{}
+	if errStatus := cli.callFeedbackAPI(ctx, req, http.StatusOK, list); errStatus != nil {
+		return nil, errStatus
+	}
+	return list, nil
+}
+
+// GetFeedbackSummary returns a page of the "Is this page useful?" answers aggregated by page, with the pages with most
+// "not useful" answers first. The 'interval' query parameter ('day' or 'week') can be provided in the options to split
+// the summaries by period, as well as the same filters and pagination accepted by GetFeedbackList.
+func (cli *Client) GetFeedbackSummary(ctx context.Context, options Options) (*models.UsefulnessSummaryList, *sdkError.StatusError) {
+	uri := fmt.Sprintf(SummaryEndpoint, cli.hcCli.URL)
+
+	req, err := http.NewRequest(http.MethodGet, uri, http.NoBody)
+	if err != nil {
+		return nil, &sdkError.StatusError{
+			Err:  fmt.Errorf("error creating request: %w", err),
+			Code: http.StatusInternalServerError,
+		}
+	}
+
+	options.SetAuth(req)
+	options.SetQuery(req)
+
+	list := &models.UsefulnessSummaryList{}
+	if errStatus := cli.callFeedbackAPI(ctx, req, http.StatusOK, list); errStatus != nil {
+		return nil, errStatus
+	}
+	return list, nil
+}
+
+// callFeedbackAPI sends the provided request, checks that the expected status is returned and unmarshals the response body into v
+func (cli *Client) callFeedbackAPI(ctx context.Context, req *http.Request, expectedStatus int, v interface{}) *sdkError.StatusError {
 	resp, err := cli.hcCli.Client.Do(ctx, req)
 	if err != nil {
 		return &sdkError.StatusError{
@@ -109,12 +232,27 @@ func (cli *Client) PostFeedback(ctx context.Context, feedback *models.Feedback,
 		}
 	}()
 
-	if resp.StatusCode != http.StatusCreated {
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
 		return &sdkError.StatusError{
-			Err:  fmt.Errorf("unexpected status returned from the feedback api post feedback endpoint: %d", resp.StatusCode),
+			Err:  fmt.Errorf("failed to read response body: %w", err),
 			Code: resp.StatusCode,
 		}
 	}
 
+	if resp.StatusCode != expectedStatus {
+		return sdkError.NewStatusError(
+			resp.StatusCode,
+			b,
+			fmt.Errorf("unexpected status returned from the feedback api %s %s endpoint: %d", req.Method, req.URL.Path, resp.StatusCode),
+		)
+	}
+
+	if err := json.Unmarshal(b, v); err != nil {
+		return &sdkError.StatusError{
+			Err:  fmt.Errorf("failed to unmarshal response body: %w", err),
+			Code: resp.StatusCode,
+		}
+	}
 	return nil
 }
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"net/url"
 	"testing"
 	"time"
 
@@ -135,17 +136,23 @@ func TestHealthChecker(t *testing.T) {
 
 func TestPostFeedback(t *testing.T) {
 	Convey("Given a mock http client that returns 201 created", t, func() {
-		hcCli, httpClientMock := getMockClient(testHost, http.StatusCreated, "", nil)
+		createdBody := `{"id":"123","received_at":"2024-03-15T10:30:00Z","is_page_useful":true,"is_general_feedback":true}`
+		hcCli, httpClientMock := getMockClient(testHost, http.StatusCreated, createdBody, nil)
 		apiClient := sdk.NewWithHealthClient(hcCli)
 
 		Convey("When PostFeedback is called with a valid feedback body", func() {
 			ctx := context.Background()
 			f := getExampleFeedback()
 			opts := sdk.Options{AuthToken: testAuthToken}
-			err := apiClient.PostFeedback(ctx, f, opts)
+			created, err := apiClient.PostFeedback(ctx, f, opts)
 
-			Convey("Then no error is returned", func() {
+			Convey("Then no error is returned and the created feedback is returned", func() {
 				So(err, ShouldBeNil)
+				expected := getExampleFeedback()
+				receivedAt := time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+				expected.ID = "123"
+				expected.ReceivedAt = &receivedAt
+				So(created, ShouldResemble, expected)
 			})
 
 			Convey("Then the expected request is sent with the expected path, method and auth header", func() {
@@ -154,6 +161,47 @@ func TestPostFeedback(t *testing.T) {
 				So(httpClientMock.DoCalls()[0].Req.Method, ShouldEqual, http.MethodPost)
 				So(httpClientMock.DoCalls()[0].Req.Header.Get(sdk.Authorization), ShouldEqual, "Bearer serviceToken")
 			})
+
+			Convey("Then no idempotency key or client IP is sent", func() {
+				So(httpClientMock.DoCalls()[0].Req.Header.Get(sdk.IdempotencyKey), ShouldBeEmpty)
+				So(httpClientMock.DoCalls()[0].Req.Header.Get(sdk.ForwardedFor), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When PostFeedback is called with the IP address of the user that submitted the feedback", func() {
+			opts := sdk.Options{AuthToken: testAuthToken, ClientIP: "198.51.100.1"}
+			_, err := apiClient.PostFeedback(context.Background(), getExampleFeedback(), opts)
+
+			Convey("Then the request is sent with the client IP in the X-Forwarded-For header", func() {
+				So(err, ShouldBeNil)
+				So(httpClientMock.DoCalls()[0].Req.Header.Get(sdk.ForwardedFor), ShouldEqual, "198.51.100.1")
+			})
+		})
+
+		Convey("When PostFeedback is called with an idempotency key", func() {
+			opts := sdk.Options{AuthToken: testAuthToken, IdempotencyKey: "key-1", GenerateIdempotencyKey: true}
+			_, err := apiClient.PostFeedback(context.Background(), getExampleFeedback(), opts)
+
+			Convey("Then the request is sent with the provided idempotency key", func() {
+				So(err, ShouldBeNil)
+				So(httpClientMock.DoCalls()[0].Req.Header.Get(sdk.IdempotencyKey), ShouldEqual, "key-1")
+			})
+		})
+
+		Convey("When PostFeedback is called twice with the option to generate idempotency keys", func() {
+			opts := sdk.Options{AuthToken: testAuthToken, GenerateIdempotencyKey: true}
+			_, err1 := apiClient.PostFeedback(context.Background(), getExampleFeedback(), opts)
+			_, err2 := apiClient.PostFeedback(context.Background(), getExampleFeedback(), opts)
+
+			Convey("Then every request is sent with a new idempotency key", func() {
+				So(err1, ShouldBeNil)
+				So(err2, ShouldBeNil)
+				first := httpClientMock.DoCalls()[0].Req.Header.Get(sdk.IdempotencyKey)
+				second := httpClientMock.DoCalls()[1].Req.Header.Get(sdk.IdempotencyKey)
+				So(first, ShouldNotBeEmpty)
+				So(second, ShouldNotBeEmpty)
+				So(first, ShouldNotEqual, second)
+			})
 		})
 	})
 
@@ -165,16 +213,36 @@ func TestPostFeedback(t *testing.T) {
 			ctx := context.Background()
 			f := getExampleFeedback()
 			opts := sdk.Options{AuthToken: "wrong"}
-			err := apiClient.PostFeedback(ctx, f, opts)
+			created, err := apiClient.PostFeedback(ctx, f, opts)
 
 			Convey("Then the expected error and status code is returned", func() {
+				So(created, ShouldBeNil)
 				So(err, ShouldNotBeNil)
-				So(err.Error(), ShouldEqual, "unexpected status returned from the feedback api post feedback endpoint: 401")
+				So(err.Error(), ShouldEqual, "unexpected status returned from the feedback api POST /feedback endpoint: 401")
 				So(err.Status(), ShouldEqual, http.StatusUnauthorized)
 			})
 		})
 	})
 
+	Convey("Given a mock http client that returns 400 Bad Request with field errors", t, func() {
+		errBody := `{"code":"validation_failed","message":"request body failed validation",` +
+			`"errors":[{"field":"EmailAddress","json_name":"email_address","rule":"email"}]}`
+		hcCli, _ := getMockClient(testHost, http.StatusBadRequest, errBody, nil)
+		apiClient := sdk.NewWithHealthClient(hcCli)
+
+		Convey("When PostFeedback is called", func() {
+			_, err := apiClient.PostFeedback(context.Background(), getExampleFeedback(), sdk.Options{AuthToken: testAuthToken})
+
+			Convey("Then the error response is decoded into the returned error", func() {
+				So(err, ShouldNotBeNil)
+				So(err.Status(), ShouldEqual, http.StatusBadRequest)
+				So(err.Error(), ShouldEqual, "request body failed validation")
+				So(err.ErrorCode, ShouldEqual, models.ErrCodeValidationFailed)
+				So(err.Fields, ShouldResemble, []models.FieldError{{Field: "EmailAddress", JSONName: "email_address", Rule: "email"}})
+			})
+		})
+	})
+
 	Convey("Given a mock http client that fails with an unexpected error", t, func() {
 		doErr := errors.New("unexpected error")
 		hcCli, _ := getMockClient(testHost, -1, "", doErr)
@@ -184,7 +252,7 @@ func TestPostFeedback(t *testing.T) {
 			ctx := context.Background()
 			f := getExampleFeedback()
 			opts := sdk.Options{AuthToken: testAuthToken}
-			err := apiClient.PostFeedback(ctx, f, opts)
+			_, err := apiClient.PostFeedback(ctx, f, opts)
 
 			Convey("Then the expected error is returned", func() {
 				So(err, ShouldNotBeNil)
@@ -194,6 +262,140 @@ func TestPostFeedback(t *testing.T) {
 	})
 }
 
+func TestGetFeedback(t *testing.T) {
+	Convey("Given a mock http client that returns 200 OK with feedback", t, func() {
+		hcCli, httpClientMock := getMockClient(testHost, http.StatusOK, `{"id":"123","is_page_useful":true,"is_general_feedback":true}`, nil)
+		apiClient := sdk.NewWithHealthClient(hcCli)
+
+		Convey("When GetFeedback is called", func() {
+			f, err := apiClient.GetFeedback(context.Background(), "123", sdk.Options{AuthToken: testAuthToken})
+
+			Convey("Then the expected feedback is returned", func() {
+				So(err, ShouldBeNil)
+				expected := getExampleFeedback()
+				expected.ID = "123"
+				So(f, ShouldResemble, expected)
+			})
+
+			Convey("Then the expected request is sent with the expected path, method and auth header", func() {
+				So(httpClientMock.DoCalls(), ShouldHaveLength, 1)
+				So(httpClientMock.DoCalls()[0].Req.URL.String(), ShouldEqual, "http://localhost:1234/feedback/123")
+				So(httpClientMock.DoCalls()[0].Req.Method, ShouldEqual, http.MethodGet)
+				So(httpClientMock.DoCalls()[0].Req.Header.Get(sdk.Authorization), ShouldEqual, "Bearer serviceToken")
+			})
+		})
+	})
+
+	Convey("Given a mock http client that returns 404 Not Found", t, func() {
+		hcCli, _ := getMockClient(testHost, http.StatusNotFound, "feedback not found", nil)
+		apiClient := sdk.NewWithHealthClient(hcCli)
+
+		Convey("When GetFeedback is called", func() {
+			f, err := apiClient.GetFeedback(context.Background(), "unknown", sdk.Options{AuthToken: testAuthToken})
+
+			Convey("Then the expected error and status code is returned", func() {
+				So(f, ShouldBeNil)
+				So(err, ShouldNotBeNil)
+				So(err.Status(), ShouldEqual, http.StatusNotFound)
+			})
+		})
+	})
+}
+
+func TestGetFeedbackList(t *testing.T) {
+	listBody := `{"items":[{"id":"123","is_page_useful":true,"is_general_feedback":true}],"count":1,"offset":0,"limit":1,"total_count":5}`
+
+	Convey("Given a mock http client that returns 200 OK with a feedback list", t, func() {
+		hcCli, httpClientMock := getMockClient(testHost, http.StatusOK, listBody, nil)
+		apiClient := sdk.NewWithHealthClient(hcCli)
+
+		Convey("When GetFeedbackList is called with query parameters", func() {
+			ctx := context.Background()
+			opts := sdk.Options{
+				AuthToken: testAuthToken,
+				Query:     url.Values{"is_page_useful": []string{"true"}, "limit": []string{"1"}},
+			}
+			list, err := apiClient.GetFeedbackList(ctx, opts)
+
+			Convey("Then the expected feedback list is returned", func() {
+				So(err, ShouldBeNil)
+				So(list.Count, ShouldEqual, 1)
+				So(list.Limit, ShouldEqual, 1)
+				So(list.TotalCount, ShouldEqual, 5)
+				So(list.Items, ShouldHaveLength, 1)
+				So(list.Items[0].ID, ShouldEqual, "123")
+			})
+
+			Convey("Then the expected request is sent with the expected path, query, method and auth header", func() {
+				So(httpClientMock.DoCalls(), ShouldHaveLength, 1)
+				So(httpClientMock.DoCalls()[0].Req.URL.String(), ShouldEqual, "http://localhost:1234/feedback?is_page_useful=true&limit=1")
+				So(httpClientMock.DoCalls()[0].Req.Method, ShouldEqual, http.MethodGet)
+				So(httpClientMock.DoCalls()[0].Req.Header.Get(sdk.Authorization), ShouldEqual, "Bearer serviceToken")
+			})
+		})
+	})
+
+	Convey("Given a mock http client that returns 400 Bad Request", t, func() {
+		hcCli, _ := getMockClient(testHost, http.StatusBadRequest, "invalid query parameter", nil)
+		apiClient := sdk.NewWithHealthClient(hcCli)
+
+		Convey("When GetFeedbackList is called", func() {
+			list, err := apiClient.GetFeedbackList(context.Background(), sdk.Options{AuthToken: testAuthToken})
+
+			Convey("Then the expected error and status code is returned", func() {
+				So(list, ShouldBeNil)
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldEqual, "unexpected status returned from the feedback api GET /feedback endpoint: 400")
+				So(err.Status(), ShouldEqual, http.StatusBadRequest)
+			})
+		})
+	})
+
+	Convey("Given a mock http client that returns 200 OK with an invalid body", t, func() {
+		hcCli, _ := getMockClient(testHost, http.StatusOK, "not json", nil)
+		apiClient := sdk.NewWithHealthClient(hcCli)
+
+		Convey("When GetFeedbackList is called", func() {
+			list, err := apiClient.GetFeedbackList(context.Background(), sdk.Options{AuthToken: testAuthToken})
+
+			Convey("Then an unmarshal error is returned", func() {
+				So(list, ShouldBeNil)
+				So(err, ShouldNotBeNil)
+				So(err.Status(), ShouldEqual, http.StatusOK)
+			})
+		})
+	})
+}
+
+func TestGetFeedbackSummary(t *testing.T) {
+	summaryBody := `{"items":[{"ons_url":"www.ons.gov.uk/census","period_start":"2024-03-11","useful":1,"not_useful":3,"total":4,` +
+		`"useful_percentage":25,"not_useful_percentage":75}],"count":1,"offset":0,"limit":20,"total_count":1}`
+
+	Convey("Given a mock http client that returns 200 OK with a summary list", t, func() {
+		hcCli, httpClientMock := getMockClient(testHost, http.StatusOK, summaryBody, nil)
+		apiClient := sdk.NewWithHealthClient(hcCli)
+
+		Convey("When GetFeedbackSummary is called with an interval", func() {
+			opts := sdk.Options{AuthToken: testAuthToken, Query: url.Values{"interval": []string{"week"}}}
+			list, err := apiClient.GetFeedbackSummary(context.Background(), opts)
+
+			Convey("Then the expected summary list is returned", func() {
+				So(err, ShouldBeNil)
+				So(list.Items, ShouldResemble, []*models.UsefulnessSummary{{
+					OnsURL: "www.ons.gov.uk/census", PeriodStart: "2024-03-11", Useful: 1, NotUseful: 3, Total: 4,
+					UsefulPercentage: 25, NotUsefulPercentage: 75,
+				}})
+			})
+
+			Convey("Then the expected request is sent", func() {
+				So(httpClientMock.DoCalls(), ShouldHaveLength, 1)
+				So(httpClientMock.DoCalls()[0].Req.URL.String(), ShouldEqual, "http://localhost:1234/feedback/summary?interval=week")
+				So(httpClientMock.DoCalls()[0].Req.Method, ShouldEqual, http.MethodGet)
+			})
+		})
+	})
+}
+
 func getMockClient(host string, statusCode int, bodyStr string, doErr error) (*healthcheck.Client, *dphttp.ClienterMock) {
 	c := &dphttp.ClienterMock{
 		DoFunc: func(ctx context.Context, req *http.Request) (*http.Response, error) {
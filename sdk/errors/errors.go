@@ -1,6 +1,11 @@
 package errors
 
-import "errors"
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/ONSdigital/dp-feedback-api/models"
+)
 
 // Error represents a handler error. It provides methods for a HTTP status
 // code and embeds the built-in error interface.
@@ -10,9 +15,32 @@ type Error interface {
 }
 
 // StatusError represents an error with an associated HTTP status code.
+// If the error was returned by the feedback API, ErrorCode and Fields contain the decoded error response.
 type StatusError struct {
-	Code int
-	Err  error
+	Code      int
+	Err       error
+	ErrorCode string
+	Fields    []models.FieldError
+}
+
+// NewStatusError creates a StatusError for an unexpected response from the feedback API with the provided status code.
+// If the response body is a JSON error response, its message, error code and field errors are decoded into the StatusError,
+// otherwise the fallback error is used.
+func NewStatusError(code int, body []byte, fallback error) *StatusError {
+	sErr := &StatusError{
+		Code: code,
+		Err:  fallback,
+	}
+
+	errResp := &models.ErrorResponse{}
+	if err := json.Unmarshal(body, errResp); err != nil || errResp.Message == "" {
+		return sErr
+	}
+
+	sErr.Err = errors.New(errResp.Message)
+	sErr.ErrorCode = errResp.Code
+	sErr.Fields = errResp.Errors
+	return sErr
 }
 
 // Allows StatusError to satisfy the error interface.
@@ -48,3 +76,17 @@ func ErrorMessage(err error) string {
 
 	return err.Error()
 }
+
+// ErrorFields returns the list of fields that failed validation, if the provided error is a StatusError
+func ErrorFields(err error) []models.FieldError {
+	var sErr StatusError
+	if errors.As(err, &sErr) {
+		return sErr.Fields
+	}
+	var sErrPtr *StatusError
+	if errors.As(err, &sErrPtr) {
+		return sErrPtr.Fields
+	}
+
+	return nil
+}
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/ONSdigital/dp-feedback-api/models"
+
 	. "github.com/smartystreets/goconvey/convey"
 )
 
@@ -49,3 +51,51 @@ func Test(t *testing.T) {
 		})
 	})
 }
+
+func TestNewStatusError(t *testing.T) {
+	t.Parallel()
+
+	fallback := errors.New("unexpected status")
+
+	Convey("given a JSON error response body with field errors", t, func() {
+		body := []byte(`{"code":"validation_failed","message":"request body failed validation",` +
+			`"errors":[{"field":"OnsURL","json_name":"ons_url","rule":"ons_url"}]}`)
+
+		Convey("when a status error is created from it", func() {
+			sErr := NewStatusError(400, body, fallback)
+
+			Convey("then the error response is decoded into the status error", func() {
+				So(sErr.Status(), ShouldEqual, 400)
+				So(sErr.Error(), ShouldEqual, "request body failed validation")
+				So(sErr.ErrorCode, ShouldEqual, "validation_failed")
+				So(sErr.Fields, ShouldResemble, []models.FieldError{{Field: "OnsURL", JSONName: "ons_url", Rule: "ons_url"}})
+			})
+
+			Convey("then the field errors can be obtained with ErrorFields", func() {
+				So(ErrorFields(sErr), ShouldResemble, sErr.Fields)
+				So(ErrorFields(*sErr), ShouldResemble, sErr.Fields)
+			})
+		})
+	})
+
+	Convey("given a response body that is not a JSON error response", t, func() {
+		body := []byte("404 page not found")
+
+		Convey("when a status error is created from it", func() {
+			sErr := NewStatusError(404, body, fallback)
+
+			Convey("then the fallback error is used", func() {
+				So(sErr.Status(), ShouldEqual, 404)
+				So(sErr.Err, ShouldEqual, fallback)
+				So(sErr.Fields, ShouldBeNil)
+				So(ErrorFields(sErr), ShouldBeNil)
+			})
+		})
+	})
+
+	Convey("given an error that is not a status error", t, func() {
+		Convey("then ErrorFields returns nil", func() {
+			So(ErrorFields(errors.New("test error")), ShouldBeNil)
+		})
+	})
+}
@@ -1,13 +1,21 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"time"
 
+	"github.com/ONSdigital/dp-api-clients-go/v2/identity"
 	"github.com/ONSdigital/dp-feedback-api/config"
+	"github.com/ONSdigital/dp-feedback-api/duplicate"
 	"github.com/ONSdigital/dp-feedback-api/email"
+	"github.com/ONSdigital/dp-feedback-api/idempotency"
+	"github.com/ONSdigital/dp-feedback-api/ratelimit"
+	"github.com/ONSdigital/dp-feedback-api/store"
 
 	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+	kafka "github.com/ONSdigital/dp-kafka/v4"
 	dphttp "github.com/ONSdigital/dp-net/v3/http"
 )
 
@@ -33,7 +41,68 @@ var GetHealthCheck = func(cfg *config.Config, buildTime, gitCommit, version stri
 	return &hc, nil
 }
 
-// GetEmailSender creates an email sender using the email package
-var GetEmailSender = func(cfg *config.Mail) EmailSender {
-	return email.NewSMTPSender(cfg)
+// GetEmailSender creates an email sender using the email package, which reuses a pool of connections to the mail server
+var GetEmailSender = func(cfg *config.Mail) (EmailSender, error) {
+	sender, err := email.NewSMTPSender(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return email.NewPooledSender(sender, cfg.PoolSize, cfg.PoolIdleTimeout), nil
+}
+
+// IdentityCheckerName is the name of the zebedee check reported by the healthcheck, which the identity client authenticates callers against
+const IdentityCheckerName = "Zebedee"
+
+// GetIdentityClient creates an identity client to authenticate callers against zebedee
+var GetIdentityClient = func(cfg *config.Config) IdentityClient {
+	return identity.New(cfg.ZebedeeURL)
+}
+
+// GetFeedbackStore opens the embedded on-disk feedback store
+var GetFeedbackStore = func(ctx context.Context, cfg *config.Store) (FeedbackStore, error) {
+	return store.NewBolt(ctx, cfg)
+}
+
+// GetKafkaProducer creates a Kafka producer for the feedback-received topic, which logs the messages that fail to be published.
+// The producer is still returned if the brokers cannot be reached, and is initialised by the healthcheck once they can.
+// It must not be sent messages until then, as sending blocks while it is not initialised.
+var GetKafkaProducer = func(ctx context.Context, cfg *config.Kafka) (KafkaProducer, error) {
+	pConfig := &kafka.ProducerConfig{
+		BrokerAddrs:       cfg.Addr,
+		Topic:             cfg.FeedbackReceivedTopic,
+		KafkaVersion:      &cfg.Version,
+		MinBrokersHealthy: &cfg.MinBrokersHealthy,
+	}
+	if cfg.SecProtocol == config.KafkaTLSProtocol {
+		pConfig.SecurityConfig = kafka.GetSecurityConfig(cfg.SecCACerts, "", "", cfg.SecSkipVerify)
+	}
+	producer, err := kafka.NewProducer(ctx, pConfig)
+	if err != nil {
+		return nil, err
+	}
+	producer.LogErrors(ctx)
+	return producer, nil
+}
+
+// GetRateLimiter creates an in-memory rate limiter for the submission of feedback
+var GetRateLimiter = func(cfg *config.RateLimit) (RateLimiter, error) {
+	return ratelimit.New(cfg)
+}
+
+// GetIdempotencyCache creates an in-memory cache of the responses to the submissions of feedback with an idempotency key,
+// which keeps them for the provided window. It returns nil, which disables idempotency keys, if the window is not positive.
+var GetIdempotencyCache = func(window time.Duration) IdempotencyCache {
+	if window <= 0 {
+		return nil
+	}
+	return idempotency.NewCache(window)
+}
+
+// GetDuplicateDetector creates an in-memory detector of near-duplicate feedback submissions, which identifies the submitters
+// by their email address. It returns nil, which disables the detection, if the window is not positive.
+var GetDuplicateDetector = func(cfg *config.Duplicate) DuplicateDetector {
+	if cfg.Window <= 0 {
+		return nil
+	}
+	return duplicate.New(cfg)
 }
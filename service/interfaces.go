@@ -3,13 +3,23 @@ package service
 import (
 	"context"
 	"net/http"
+	"time"
 
+	"github.com/ONSdigital/dp-api-clients-go/v2/identity"
+	"github.com/ONSdigital/dp-feedback-api/idempotency"
+	"github.com/ONSdigital/dp-feedback-api/models"
 	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+	"github.com/ONSdigital/dp-kafka/v4/avro"
 )
 
 //go:generate moq -out mock/server.go -pkg mock . HTTPServer
 //go:generate moq -out mock/healthCheck.go -pkg mock . HealthChecker
 //go:generate moq -out mock/email.go -pkg mock . EmailSender
+//go:generate moq -out mock/identity.go -pkg mock . IdentityClient
+//go:generate moq -out mock/store.go -pkg mock . FeedbackStore
+//go:generate moq -out mock/ratelimiter.go -pkg mock . RateLimiter
+//go:generate moq -out mock/idempotency.go -pkg mock . IdempotencyCache
+//go:generate moq -out mock/duplicates.go -pkg mock . DuplicateDetector
 
 // HTTPServer defines the required methods from the HTTP server
 type HTTPServer interface {
@@ -25,7 +35,58 @@ type HealthChecker interface {
 	AddCheck(name string, checker healthcheck.Checker) (err error)
 }
 
-// EmailSender defines the required methods to send emails
+// EmailSender defines the required methods to send emails, check the health of the mail server and close its connections
 type EmailSender interface {
 	Send(from string, to []string, msg []byte) error
+	Checker(ctx context.Context, state *healthcheck.CheckState) error
+	Close(ctx context.Context) error
+}
+
+// IdentityClient defines the required methods to identify the caller of a request from its auth token
+type IdentityClient interface {
+	CheckRequest(req *http.Request, florenceToken, serviceAuthToken string) (context.Context, int, identity.AuthFailure, error)
+	Checker(ctx context.Context, state *healthcheck.CheckState) error
+}
+
+// FeedbackStore defines the required methods to persist feedback submissions, the outbox of emails and the digest entries
+type FeedbackStore interface {
+	AddFeedback(ctx context.Context, f *models.Feedback) error
+	GetFeedback(ctx context.Context, id string) (*models.Feedback, error)
+	AddOccurrence(ctx context.Context, id string) (*models.Feedback, error)
+	GetFeedbackList(ctx context.Context, filter *models.FeedbackFilter) ([]*models.Feedback, error)
+	AddOutboxMessage(ctx context.Context, msg *models.OutboxMessage) error
+	UpdateOutboxMessage(ctx context.Context, msg *models.OutboxMessage) error
+	DeleteOutboxMessage(ctx context.Context, id string) error
+	GetPendingOutboxMessages(ctx context.Context, now time.Time, limit int) ([]*models.OutboxMessage, error)
+	AddDigestEntry(ctx context.Context, e *models.DigestEntry) error
+	GetDigestEntries(ctx context.Context, route string) ([]*models.DigestEntry, error)
+	DeleteDigestEntries(ctx context.Context, ids []string) error
+	Close(ctx context.Context) error
+}
+
+// KafkaProducer defines the required methods to publish messages to a Kafka topic, check the health of the brokers and close the producer
+type KafkaProducer interface {
+	IsInitialised() bool
+	Send(ctx context.Context, schema *avro.Schema, event interface{}) error
+	Checker(ctx context.Context, state *healthcheck.CheckState) error
+	Close(ctx context.Context) error
+}
+
+// RateLimiter defines the required methods to limit the rate of requests per caller and per client IP
+type RateLimiter interface {
+	Allow(caller string, r *http.Request) (allowed bool, retryAfter time.Duration)
+}
+
+// IdempotencyCache defines the required methods to cache the responses to the requests with an idempotency key,
+// so that their retries get the original response instead of being processed again
+type IdempotencyCache interface {
+	Begin(key, fingerprint string) (*idempotency.Response, error)
+	Complete(key string, resp *idempotency.Response)
+	Cancel(key string)
+}
+
+// DuplicateDetector defines the required methods to detect the near-duplicates of recent feedback submissions
+type DuplicateDetector interface {
+	Check(f *models.Feedback) (originalID string)
+	Forget(id string)
 }
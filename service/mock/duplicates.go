@@ -0,0 +1,120 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"github.com/ONSdigital/dp-feedback-api/models"
+	"github.com/ONSdigital/dp-feedback-api/service"
+	"sync"
+)
+
+// Ensure, that DuplicateDetectorMock does implement service.DuplicateDetector.
+// If this is not the case, regenerate this file with moq.
+var _ service.DuplicateDetector = &DuplicateDetectorMock{}
+
+// DuplicateDetectorMock is a mock implementation of service.DuplicateDetector.
+//
+//	func TestSomethingThatUsesDuplicateDetector(t *testing.T) {
+//
+//		// make and configure a mocked service.DuplicateDetector
+//		mockedDuplicateDetector := &DuplicateDetectorMock{
+//			CheckFunc: func(f *models.Feedback) string {
+//				panic("mock out the Check method")
+//			},
+//			ForgetFunc: func(id string)  {
+//				panic("mock out the Forget method")
+//			},
+//		}
+//
+//		// use mockedDuplicateDetector in code that requires service.DuplicateDetector
+//		// and then make assertions.
+//
+//	}
+type DuplicateDetectorMock struct {
+	// CheckFunc mocks the Check method.
+	CheckFunc func(f *models.Feedback) string
+
+	// ForgetFunc mocks the Forget method.
+	ForgetFunc func(id string)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Check holds details about calls to the Check method.
+		Check []struct {
+			// F is the f argument value.
+			F *models.Feedback
+		}
+		// Forget holds details about calls to the Forget method.
+		Forget []struct {
+			// ID is the id argument value.
+			ID string
+		}
+	}
+	lockCheck  sync.RWMutex
+	lockForget sync.RWMutex
+}
+
+// Check calls CheckFunc.
+func (mock *DuplicateDetectorMock) Check(f *models.Feedback) string {
+	if mock.CheckFunc == nil {
+		panic("DuplicateDetectorMock.CheckFunc: method is nil but DuplicateDetector.Check was just called")
+	}
+	callInfo := struct {
+		F *models.Feedback
+	}{
+		F: f,
+	}
+	mock.lockCheck.Lock()
+	mock.calls.Check = append(mock.calls.Check, callInfo)
+	mock.lockCheck.Unlock()
+	return mock.CheckFunc(f)
+}
+
+// CheckCalls gets all the calls that were made to Check.
+// Check the length with:
+//
+//	len(mockedDuplicateDetector.CheckCalls())
+func (mock *DuplicateDetectorMock) CheckCalls() []struct {
+	F *models.Feedback
+} {
+	var calls []struct {
+		F *models.Feedback
+	}
+	mock.lockCheck.RLock()
+	calls = mock.calls.Check
+	mock.lockCheck.RUnlock()
+	return calls
+}
+
+// Forget calls ForgetFunc.
+func (mock *DuplicateDetectorMock) Forget(id string) {
+	if mock.ForgetFunc == nil {
+		panic("DuplicateDetectorMock.ForgetFunc: method is nil but DuplicateDetector.Forget was just called")
+	}
+	callInfo := struct {
+		ID string
+	}{
+		ID: id,
+	}
+	mock.lockForget.Lock()
+	mock.calls.Forget = append(mock.calls.Forget, callInfo)
+	mock.lockForget.Unlock()
+	mock.ForgetFunc(id)
+}
+
+// ForgetCalls gets all the calls that were made to Forget.
+// Check the length with:
+//
+//	len(mockedDuplicateDetector.ForgetCalls())
+func (mock *DuplicateDetectorMock) ForgetCalls() []struct {
+	ID string
+} {
+	var calls []struct {
+		ID string
+	}
+	mock.lockForget.RLock()
+	calls = mock.calls.Forget
+	mock.lockForget.RUnlock()
+	return calls
+}
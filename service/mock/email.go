@@ -4,7 +4,9 @@
 package mock
 
 import (
+	"context"
 	"github.com/ONSdigital/dp-feedback-api/service"
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
 	"sync"
 )
 
@@ -18,6 +20,12 @@ var _ service.EmailSender = &EmailSenderMock{}
 //
 //		// make and configure a mocked service.EmailSender
 //		mockedEmailSender := &EmailSenderMock{
+//			CheckerFunc: func(ctx context.Context, state *healthcheck.CheckState) error {
+//				panic("mock out the Checker method")
+//			},
+//			CloseFunc: func(ctx context.Context) error {
+//				panic("mock out the Close method")
+//			},
 //			SendFunc: func(from string, to []string, msg []byte) error {
 //				panic("mock out the Send method")
 //			},
@@ -28,11 +36,29 @@ var _ service.EmailSender = &EmailSenderMock{}
 //
 //	}
 type EmailSenderMock struct {
+	// CheckerFunc mocks the Checker method.
+	CheckerFunc func(ctx context.Context, state *healthcheck.CheckState) error
+
+	// CloseFunc mocks the Close method.
+	CloseFunc func(ctx context.Context) error
+
 	// SendFunc mocks the Send method.
 	SendFunc func(from string, to []string, msg []byte) error
 
 	// calls tracks calls to the methods.
 	calls struct {
+		// Checker holds details about calls to the Checker method.
+		Checker []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// State is the state argument value.
+			State *healthcheck.CheckState
+		}
+		// Close holds details about calls to the Close method.
+		Close []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
 		// Send holds details about calls to the Send method.
 		Send []struct {
 			// From is the from argument value.
@@ -43,7 +69,77 @@ type EmailSenderMock struct {
 			Msg []byte
 		}
 	}
-	lockSend sync.RWMutex
+	lockChecker sync.RWMutex
+	lockClose   sync.RWMutex
+	lockSend    sync.RWMutex
+}
+
+// Checker calls CheckerFunc.
+func (mock *EmailSenderMock) Checker(ctx context.Context, state *healthcheck.CheckState) error {
+	if mock.CheckerFunc == nil {
+		panic("EmailSenderMock.CheckerFunc: method is nil but EmailSender.Checker was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		State *healthcheck.CheckState
+	}{
+		Ctx:   ctx,
+		State: state,
+	}
+	mock.lockChecker.Lock()
+	mock.calls.Checker = append(mock.calls.Checker, callInfo)
+	mock.lockChecker.Unlock()
+	return mock.CheckerFunc(ctx, state)
+}
+
+// CheckerCalls gets all the calls that were made to Checker.
+// Check the length with:
+//
+//	len(mockedEmailSender.CheckerCalls())
+func (mock *EmailSenderMock) CheckerCalls() []struct {
+	Ctx   context.Context
+	State *healthcheck.CheckState
+} {
+	var calls []struct {
+		Ctx   context.Context
+		State *healthcheck.CheckState
+	}
+	mock.lockChecker.RLock()
+	calls = mock.calls.Checker
+	mock.lockChecker.RUnlock()
+	return calls
+}
+
+// Close calls CloseFunc.
+func (mock *EmailSenderMock) Close(ctx context.Context) error {
+	if mock.CloseFunc == nil {
+		panic("EmailSenderMock.CloseFunc: method is nil but EmailSender.Close was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockClose.Lock()
+	mock.calls.Close = append(mock.calls.Close, callInfo)
+	mock.lockClose.Unlock()
+	return mock.CloseFunc(ctx)
+}
+
+// CloseCalls gets all the calls that were made to Close.
+// Check the length with:
+//
+//	len(mockedEmailSender.CloseCalls())
+func (mock *EmailSenderMock) CloseCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockClose.RLock()
+	calls = mock.calls.Close
+	mock.lockClose.RUnlock()
+	return calls
 }
 
 // Send calls SendFunc.
@@ -0,0 +1,176 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"github.com/ONSdigital/dp-feedback-api/idempotency"
+	"github.com/ONSdigital/dp-feedback-api/service"
+	"sync"
+)
+
+// Ensure, that IdempotencyCacheMock does implement service.IdempotencyCache.
+// If this is not the case, regenerate this file with moq.
+var _ service.IdempotencyCache = &IdempotencyCacheMock{}
+
+// IdempotencyCacheMock is a mock implementation of service.IdempotencyCache.
+//
+//	func TestSomethingThatUsesIdempotencyCache(t *testing.T) {
+//
+//		// make and configure a mocked service.IdempotencyCache
+//		mockedIdempotencyCache := &IdempotencyCacheMock{
+//			BeginFunc: func(key string, fingerprint string) (*idempotency.Response, error) {
+//				panic("mock out the Begin method")
+//			},
+//			CancelFunc: func(key string)  {
+//				panic("mock out the Cancel method")
+//			},
+//			CompleteFunc: func(key string, resp *idempotency.Response)  {
+//				panic("mock out the Complete method")
+//			},
+//		}
+//
+//		// use mockedIdempotencyCache in code that requires service.IdempotencyCache
+//		// and then make assertions.
+//
+//	}
+type IdempotencyCacheMock struct {
+	// BeginFunc mocks the Begin method.
+	BeginFunc func(key string, fingerprint string) (*idempotency.Response, error)
+
+	// CancelFunc mocks the Cancel method.
+	CancelFunc func(key string)
+
+	// CompleteFunc mocks the Complete method.
+	CompleteFunc func(key string, resp *idempotency.Response)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// Begin holds details about calls to the Begin method.
+		Begin []struct {
+			// Key is the key argument value.
+			Key string
+			// Fingerprint is the fingerprint argument value.
+			Fingerprint string
+		}
+		// Cancel holds details about calls to the Cancel method.
+		Cancel []struct {
+			// Key is the key argument value.
+			Key string
+		}
+		// Complete holds details about calls to the Complete method.
+		Complete []struct {
+			// Key is the key argument value.
+			Key string
+			// Resp is the resp argument value.
+			Resp *idempotency.Response
+		}
+	}
+	lockBegin    sync.RWMutex
+	lockCancel   sync.RWMutex
+	lockComplete sync.RWMutex
+}
+
+// Begin calls BeginFunc.
+func (mock *IdempotencyCacheMock) Begin(key string, fingerprint string) (*idempotency.Response, error) {
+	if mock.BeginFunc == nil {
+		panic("IdempotencyCacheMock.BeginFunc: method is nil but IdempotencyCache.Begin was just called")
+	}
+	callInfo := struct {
+		Key         string
+		Fingerprint string
+	}{
+		Key:         key,
+		Fingerprint: fingerprint,
+	}
+	mock.lockBegin.Lock()
+	mock.calls.Begin = append(mock.calls.Begin, callInfo)
+	mock.lockBegin.Unlock()
+	return mock.BeginFunc(key, fingerprint)
+}
+
+// BeginCalls gets all the calls that were made to Begin.
+// Check the length with:
+//
+//	len(mockedIdempotencyCache.BeginCalls())
+func (mock *IdempotencyCacheMock) BeginCalls() []struct {
+	Key         string
+	Fingerprint string
+} {
+	var calls []struct {
+		Key         string
+		Fingerprint string
+	}
+	mock.lockBegin.RLock()
+	calls = mock.calls.Begin
+	mock.lockBegin.RUnlock()
+	return calls
+}
+
+// Cancel calls CancelFunc.
+func (mock *IdempotencyCacheMock) Cancel(key string) {
+	if mock.CancelFunc == nil {
+		panic("IdempotencyCacheMock.CancelFunc: method is nil but IdempotencyCache.Cancel was just called")
+	}
+	callInfo := struct {
+		Key string
+	}{
+		Key: key,
+	}
+	mock.lockCancel.Lock()
+	mock.calls.Cancel = append(mock.calls.Cancel, callInfo)
+	mock.lockCancel.Unlock()
+	mock.CancelFunc(key)
+}
+
+// CancelCalls gets all the calls that were made to Cancel.
+// Check the length with:
+//
+//	len(mockedIdempotencyCache.CancelCalls())
+func (mock *IdempotencyCacheMock) CancelCalls() []struct {
+	Key string
+} {
+	var calls []struct {
+		Key string
+	}
+	mock.lockCancel.RLock()
+	calls = mock.calls.Cancel
+	mock.lockCancel.RUnlock()
+	return calls
+}
+
+// Complete calls CompleteFunc.
+func (mock *IdempotencyCacheMock) Complete(key string, resp *idempotency.Response) {
+	if mock.CompleteFunc == nil {
+		panic("IdempotencyCacheMock.CompleteFunc: method is nil but IdempotencyCache.Complete was just called")
+	}
+	callInfo := struct {
+		Key  string
+		Resp *idempotency.Response
+	}{
+		Key:  key,
+		Resp: resp,
+	}
+	mock.lockComplete.Lock()
+	mock.calls.Complete = append(mock.calls.Complete, callInfo)
+	mock.lockComplete.Unlock()
+	mock.CompleteFunc(key, resp)
+}
+
+// CompleteCalls gets all the calls that were made to Complete.
+// Check the length with:
+//
+//	len(mockedIdempotencyCache.CompleteCalls())
+func (mock *IdempotencyCacheMock) CompleteCalls() []struct {
+	Key  string
+	Resp *idempotency.Response
+} {
+	var calls []struct {
+		Key  string
+		Resp *idempotency.Response
+	}
+	mock.lockComplete.RLock()
+	calls = mock.calls.Complete
+	mock.lockComplete.RUnlock()
+	return calls
+}
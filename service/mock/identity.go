@@ -0,0 +1,141 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"context"
+	"github.com/ONSdigital/dp-api-clients-go/v2/identity"
+	"github.com/ONSdigital/dp-feedback-api/service"
+	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+	"net/http"
+	"sync"
+)
+
+// Ensure, that IdentityClientMock does implement service.IdentityClient.
+// If this is not the case, regenerate this file with moq.
+var _ service.IdentityClient = &IdentityClientMock{}
+
+// IdentityClientMock is a mock implementation of service.IdentityClient.
+//
+//	func TestSomethingThatUsesIdentityClient(t *testing.T) {
+//
+//		// make and configure a mocked service.IdentityClient
+//		mockedIdentityClient := &IdentityClientMock{
+//			CheckRequestFunc: func(req *http.Request, florenceToken string, serviceAuthToken string) (context.Context, int, identity.AuthFailure, error) {
+//				panic("mock out the CheckRequest method")
+//			},
+//			CheckerFunc: func(ctx context.Context, state *healthcheck.CheckState) error {
+//				panic("mock out the Checker method")
+//			},
+//		}
+//
+//		// use mockedIdentityClient in code that requires service.IdentityClient
+//		// and then make assertions.
+//
+//	}
+type IdentityClientMock struct {
+	// CheckRequestFunc mocks the CheckRequest method.
+	CheckRequestFunc func(req *http.Request, florenceToken string, serviceAuthToken string) (context.Context, int, identity.AuthFailure, error)
+
+	// CheckerFunc mocks the Checker method.
+	CheckerFunc func(ctx context.Context, state *healthcheck.CheckState) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// CheckRequest holds details about calls to the CheckRequest method.
+		CheckRequest []struct {
+			// Req is the req argument value.
+			Req *http.Request
+			// FlorenceToken is the florenceToken argument value.
+			FlorenceToken string
+			// ServiceAuthToken is the serviceAuthToken argument value.
+			ServiceAuthToken string
+		}
+		// Checker holds details about calls to the Checker method.
+		Checker []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// State is the state argument value.
+			State *healthcheck.CheckState
+		}
+	}
+	lockCheckRequest sync.RWMutex
+	lockChecker      sync.RWMutex
+}
+
+// CheckRequest calls CheckRequestFunc.
+func (mock *IdentityClientMock) CheckRequest(req *http.Request, florenceToken string, serviceAuthToken string) (context.Context, int, identity.AuthFailure, error) {
+	if mock.CheckRequestFunc == nil {
+		panic("IdentityClientMock.CheckRequestFunc: method is nil but IdentityClient.CheckRequest was just called")
+	}
+	callInfo := struct {
+		Req              *http.Request
+		FlorenceToken    string
+		ServiceAuthToken string
+	}{
+		Req:              req,
+		FlorenceToken:    florenceToken,
+		ServiceAuthToken: serviceAuthToken,
+	}
+	mock.lockCheckRequest.Lock()
+	mock.calls.CheckRequest = append(mock.calls.CheckRequest, callInfo)
+	mock.lockCheckRequest.Unlock()
+	return mock.CheckRequestFunc(req, florenceToken, serviceAuthToken)
+}
+
+// CheckRequestCalls gets all the calls that were made to CheckRequest.
+// Check the length with:
+//
+//	len(mockedIdentityClient.CheckRequestCalls())
+func (mock *IdentityClientMock) CheckRequestCalls() []struct {
+	Req              *http.Request
+	FlorenceToken    string
+	ServiceAuthToken string
+} {
+	var calls []struct {
+		Req              *http.Request
+		FlorenceToken    string
+		ServiceAuthToken string
+	}
+	mock.lockCheckRequest.RLock()
+	calls = mock.calls.CheckRequest
+	mock.lockCheckRequest.RUnlock()
+	return calls
+}
+
+// Checker calls CheckerFunc.
+func (mock *IdentityClientMock) Checker(ctx context.Context, state *healthcheck.CheckState) error {
+	if mock.CheckerFunc == nil {
+		panic("IdentityClientMock.CheckerFunc: method is nil but IdentityClient.Checker was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		State *healthcheck.CheckState
+	}{
+		Ctx:   ctx,
+		State: state,
+	}
+	mock.lockChecker.Lock()
+	mock.calls.Checker = append(mock.calls.Checker, callInfo)
+	mock.lockChecker.Unlock()
+	return mock.CheckerFunc(ctx, state)
+}
+
+// CheckerCalls gets all the calls that were made to Checker.
+// Check the length with:
+//
+//	len(mockedIdentityClient.CheckerCalls())
+func (mock *IdentityClientMock) CheckerCalls() []struct {
+	Ctx   context.Context
+	State *healthcheck.CheckState
+} {
+	var calls []struct {
+		Ctx   context.Context
+		State *healthcheck.CheckState
+	}
+	mock.lockChecker.RLock()
+	calls = mock.calls.Checker
+	mock.lockChecker.RUnlock()
+	return calls
+}
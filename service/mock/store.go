@@ -0,0 +1,634 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"context"
+	"github.com/ONSdigital/dp-feedback-api/models"
+	"github.com/ONSdigital/dp-feedback-api/service"
+	"sync"
+	"time"
+)
+
+// Ensure, that FeedbackStoreMock does implement service.FeedbackStore.
+// If this is not the case, regenerate this file with moq.
+var _ service.FeedbackStore = &FeedbackStoreMock{}
+
+// FeedbackStoreMock is a mock implementation of service.FeedbackStore.
+//
+//	func TestSomethingThatUsesFeedbackStore(t *testing.T) {
+//
+//		// make and configure a mocked service.FeedbackStore
+//		mockedFeedbackStore := &FeedbackStoreMock{
+//			AddDigestEntryFunc: func(ctx context.Context, e *models.DigestEntry) error {
+//				panic("mock out the AddDigestEntry method")
+//			},
+//			AddFeedbackFunc: func(ctx context.Context, f *models.Feedback) error {
+//				panic("mock out the AddFeedback method")
+//			},
+//			AddOccurrenceFunc: func(ctx context.Context, id string) (*models.Feedback, error) {
+//				panic("mock out the AddOccurrence method")
+//			},
+//			AddOutboxMessageFunc: func(ctx context.Context, msg *models.OutboxMessage) error {
+//				panic("mock out the AddOutboxMessage method")
+//			},
+//			CloseFunc: func(ctx context.Context) error {
+//				panic("mock out the Close method")
+//			},
+//			DeleteDigestEntriesFunc: func(ctx context.Context, ids []string) error {
+//				panic("mock out the DeleteDigestEntries method")
+//			},
+//			DeleteOutboxMessageFunc: func(ctx context.Context, id string) error {
+//				panic("mock out the DeleteOutboxMessage method")
+//			},
+//			GetDigestEntriesFunc: func(ctx context.Context, route string) ([]*models.DigestEntry, error) {
+//				panic("mock out the GetDigestEntries method")
+//			},
+//			GetFeedbackFunc: func(ctx context.Context, id string) (*models.Feedback, error) {
+//				panic("mock out the GetFeedback method")
+//			},
+//			GetFeedbackListFunc: func(ctx context.Context, filter *models.FeedbackFilter) ([]*models.Feedback, error) {
+//				panic("mock out the GetFeedbackList method")
+//			},
+//			GetPendingOutboxMessagesFunc: func(ctx context.Context, now time.Time, limit int) ([]*models.OutboxMessage, error) {
+//				panic("mock out the GetPendingOutboxMessages method")
+//			},
+//			UpdateOutboxMessageFunc: func(ctx context.Context, msg *models.OutboxMessage) error {
+//				panic("mock out the UpdateOutboxMessage method")
+//			},
+//		}
+//
+//		// use mockedFeedbackStore in code that requires service.FeedbackStore
+//		// and then make assertions.
+//
+//	}
+type FeedbackStoreMock struct {
+	// AddDigestEntryFunc mocks the AddDigestEntry method.
+	AddDigestEntryFunc func(ctx context.Context, e *models.DigestEntry) error
+
+	// AddFeedbackFunc mocks the AddFeedback method.
+	AddFeedbackFunc func(ctx context.Context, f *models.Feedback) error
+
+	// AddOccurrenceFunc mocks the AddOccurrence method.
+	AddOccurrenceFunc func(ctx context.Context, id string) (*models.Feedback, error)
+
+	// AddOutboxMessageFunc mocks the AddOutboxMessage method.
+	AddOutboxMessageFunc func(ctx context.Context, msg *models.OutboxMessage) error
+
+	// CloseFunc mocks the Close method.
+	CloseFunc func(ctx context.Context) error
+
+	// DeleteDigestEntriesFunc mocks the DeleteDigestEntries method.
+	DeleteDigestEntriesFunc func(ctx context.Context, ids []string) error
+
+	// DeleteOutboxMessageFunc mocks the DeleteOutboxMessage method.
+	DeleteOutboxMessageFunc func(ctx context.Context, id string) error
+
+	// GetDigestEntriesFunc mocks the GetDigestEntries method.
+	GetDigestEntriesFunc func(ctx context.Context, route string) ([]*models.DigestEntry, error)
+
+	// GetFeedbackFunc mocks the GetFeedback method.
+	GetFeedbackFunc func(ctx context.Context, id string) (*models.Feedback, error)
+
+	// GetFeedbackListFunc mocks the GetFeedbackList method.
+	GetFeedbackListFunc func(ctx context.Context, filter *models.FeedbackFilter) ([]*models.Feedback, error)
+
+	// GetPendingOutboxMessagesFunc mocks the GetPendingOutboxMessages method.
+	GetPendingOutboxMessagesFunc func(ctx context.Context, now time.Time, limit int) ([]*models.OutboxMessage, error)
+
+	// UpdateOutboxMessageFunc mocks the UpdateOutboxMessage method.
+	UpdateOutboxMessageFunc func(ctx context.Context, msg *models.OutboxMessage) error
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// AddDigestEntry holds details about calls to the AddDigestEntry method.
+		AddDigestEntry []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// E is the e argument value.
+			E *models.DigestEntry
+		}
+		// AddFeedback holds details about calls to the AddFeedback method.
+		AddFeedback []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// F is the f argument value.
+			F *models.Feedback
+		}
+		// AddOccurrence holds details about calls to the AddOccurrence method.
+		AddOccurrence []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+		}
+		// AddOutboxMessage holds details about calls to the AddOutboxMessage method.
+		AddOutboxMessage []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Msg is the msg argument value.
+			Msg *models.OutboxMessage
+		}
+		// Close holds details about calls to the Close method.
+		Close []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+		// DeleteDigestEntries holds details about calls to the DeleteDigestEntries method.
+		DeleteDigestEntries []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Ids is the ids argument value.
+			Ids []string
+		}
+		// DeleteOutboxMessage holds details about calls to the DeleteOutboxMessage method.
+		DeleteOutboxMessage []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+		}
+		// GetDigestEntries holds details about calls to the GetDigestEntries method.
+		GetDigestEntries []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Route is the route argument value.
+			Route string
+		}
+		// GetFeedback holds details about calls to the GetFeedback method.
+		GetFeedback []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// ID is the id argument value.
+			ID string
+		}
+		// GetFeedbackList holds details about calls to the GetFeedbackList method.
+		GetFeedbackList []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Filter is the filter argument value.
+			Filter *models.FeedbackFilter
+		}
+		// GetPendingOutboxMessages holds details about calls to the GetPendingOutboxMessages method.
+		GetPendingOutboxMessages []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Now is the now argument value.
+			Now time.Time
+			// Limit is the limit argument value.
+			Limit int
+		}
+		// UpdateOutboxMessage holds details about calls to the UpdateOutboxMessage method.
+		UpdateOutboxMessage []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Msg is the msg argument value.
+			Msg *models.OutboxMessage
+		}
+	}
+	lockAddDigestEntry           sync.RWMutex
+	lockAddFeedback              sync.RWMutex
+	lockAddOccurrence            sync.RWMutex
+	lockAddOutboxMessage         sync.RWMutex
+	lockClose                    sync.RWMutex
+	lockDeleteDigestEntries      sync.RWMutex
+	lockDeleteOutboxMessage      sync.RWMutex
+	lockGetDigestEntries         sync.RWMutex
+	lockGetFeedback              sync.RWMutex
+	lockGetFeedbackList          sync.RWMutex
+	lockGetPendingOutboxMessages sync.RWMutex
+	lockUpdateOutboxMessage      sync.RWMutex
+}
+
+// AddDigestEntry calls AddDigestEntryFunc.
+func (mock *FeedbackStoreMock) AddDigestEntry(ctx context.Context, e *models.DigestEntry) error {
+	if mock.AddDigestEntryFunc == nil {
+		panic("FeedbackStoreMock.AddDigestEntryFunc: method is nil but FeedbackStore.AddDigestEntry was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		E   *models.DigestEntry
+	}{
+		Ctx: ctx,
+		E:   e,
+	}
+	mock.lockAddDigestEntry.Lock()
+	mock.calls.AddDigestEntry = append(mock.calls.AddDigestEntry, callInfo)
+	mock.lockAddDigestEntry.Unlock()
+	return mock.AddDigestEntryFunc(ctx, e)
+}
+
+// AddDigestEntryCalls gets all the calls that were made to AddDigestEntry.
+// Check the length with:
+//
+//	len(mockedFeedbackStore.AddDigestEntryCalls())
+func (mock *FeedbackStoreMock) AddDigestEntryCalls() []struct {
+	Ctx context.Context
+	E   *models.DigestEntry
+} {
+	var calls []struct {
+		Ctx context.Context
+		E   *models.DigestEntry
+	}
+	mock.lockAddDigestEntry.RLock()
+	calls = mock.calls.AddDigestEntry
+	mock.lockAddDigestEntry.RUnlock()
+	return calls
+}
+
+// AddFeedback calls AddFeedbackFunc.
+func (mock *FeedbackStoreMock) AddFeedback(ctx context.Context, f *models.Feedback) error {
+	if mock.AddFeedbackFunc == nil {
+		panic("FeedbackStoreMock.AddFeedbackFunc: method is nil but FeedbackStore.AddFeedback was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		F   *models.Feedback
+	}{
+		Ctx: ctx,
+		F:   f,
+	}
+	mock.lockAddFeedback.Lock()
+	mock.calls.AddFeedback = append(mock.calls.AddFeedback, callInfo)
+	mock.lockAddFeedback.Unlock()
+	return mock.AddFeedbackFunc(ctx, f)
+}
+
+// AddFeedbackCalls gets all the calls that were made to AddFeedback.
+// Check the length with:
+//
+//	len(mockedFeedbackStore.AddFeedbackCalls())
+func (mock *FeedbackStoreMock) AddFeedbackCalls() []struct {
+	Ctx context.Context
+	F   *models.Feedback
+} {
+	var calls []struct {
+		Ctx context.Context
+		F   *models.Feedback
+	}
+	mock.lockAddFeedback.RLock()
+	calls = mock.calls.AddFeedback
+	mock.lockAddFeedback.RUnlock()
+	return calls
+}
+
+// AddOccurrence calls AddOccurrenceFunc.
+func (mock *FeedbackStoreMock) AddOccurrence(ctx context.Context, id string) (*models.Feedback, error) {
+	if mock.AddOccurrenceFunc == nil {
+		panic("FeedbackStoreMock.AddOccurrenceFunc: method is nil but FeedbackStore.AddOccurrence was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockAddOccurrence.Lock()
+	mock.calls.AddOccurrence = append(mock.calls.AddOccurrence, callInfo)
+	mock.lockAddOccurrence.Unlock()
+	return mock.AddOccurrenceFunc(ctx, id)
+}
+
+// AddOccurrenceCalls gets all the calls that were made to AddOccurrence.
+// Check the length with:
+//
+//	len(mockedFeedbackStore.AddOccurrenceCalls())
+func (mock *FeedbackStoreMock) AddOccurrenceCalls() []struct {
+	Ctx context.Context
+	ID  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+	}
+	mock.lockAddOccurrence.RLock()
+	calls = mock.calls.AddOccurrence
+	mock.lockAddOccurrence.RUnlock()
+	return calls
+}
+
+// AddOutboxMessage calls AddOutboxMessageFunc.
+func (mock *FeedbackStoreMock) AddOutboxMessage(ctx context.Context, msg *models.OutboxMessage) error {
+	if mock.AddOutboxMessageFunc == nil {
+		panic("FeedbackStoreMock.AddOutboxMessageFunc: method is nil but FeedbackStore.AddOutboxMessage was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Msg *models.OutboxMessage
+	}{
+		Ctx: ctx,
+		Msg: msg,
+	}
+	mock.lockAddOutboxMessage.Lock()
+	mock.calls.AddOutboxMessage = append(mock.calls.AddOutboxMessage, callInfo)
+	mock.lockAddOutboxMessage.Unlock()
+	return mock.AddOutboxMessageFunc(ctx, msg)
+}
+
+// AddOutboxMessageCalls gets all the calls that were made to AddOutboxMessage.
+// Check the length with:
+//
+//	len(mockedFeedbackStore.AddOutboxMessageCalls())
+func (mock *FeedbackStoreMock) AddOutboxMessageCalls() []struct {
+	Ctx context.Context
+	Msg *models.OutboxMessage
+} {
+	var calls []struct {
+		Ctx context.Context
+		Msg *models.OutboxMessage
+	}
+	mock.lockAddOutboxMessage.RLock()
+	calls = mock.calls.AddOutboxMessage
+	mock.lockAddOutboxMessage.RUnlock()
+	return calls
+}
+
+// Close calls CloseFunc.
+func (mock *FeedbackStoreMock) Close(ctx context.Context) error {
+	if mock.CloseFunc == nil {
+		panic("FeedbackStoreMock.CloseFunc: method is nil but FeedbackStore.Close was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockClose.Lock()
+	mock.calls.Close = append(mock.calls.Close, callInfo)
+	mock.lockClose.Unlock()
+	return mock.CloseFunc(ctx)
+}
+
+// CloseCalls gets all the calls that were made to Close.
+// Check the length with:
+//
+//	len(mockedFeedbackStore.CloseCalls())
+func (mock *FeedbackStoreMock) CloseCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockClose.RLock()
+	calls = mock.calls.Close
+	mock.lockClose.RUnlock()
+	return calls
+}
+
+// DeleteDigestEntries calls DeleteDigestEntriesFunc.
+func (mock *FeedbackStoreMock) DeleteDigestEntries(ctx context.Context, ids []string) error {
+	if mock.DeleteDigestEntriesFunc == nil {
+		panic("FeedbackStoreMock.DeleteDigestEntriesFunc: method is nil but FeedbackStore.DeleteDigestEntries was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Ids []string
+	}{
+		Ctx: ctx,
+		Ids: ids,
+	}
+	mock.lockDeleteDigestEntries.Lock()
+	mock.calls.DeleteDigestEntries = append(mock.calls.DeleteDigestEntries, callInfo)
+	mock.lockDeleteDigestEntries.Unlock()
+	return mock.DeleteDigestEntriesFunc(ctx, ids)
+}
+
+// DeleteDigestEntriesCalls gets all the calls that were made to DeleteDigestEntries.
+// Check the length with:
+//
+//	len(mockedFeedbackStore.DeleteDigestEntriesCalls())
+func (mock *FeedbackStoreMock) DeleteDigestEntriesCalls() []struct {
+	Ctx context.Context
+	Ids []string
+} {
+	var calls []struct {
+		Ctx context.Context
+		Ids []string
+	}
+	mock.lockDeleteDigestEntries.RLock()
+	calls = mock.calls.DeleteDigestEntries
+	mock.lockDeleteDigestEntries.RUnlock()
+	return calls
+}
+
+// DeleteOutboxMessage calls DeleteOutboxMessageFunc.
+func (mock *FeedbackStoreMock) DeleteOutboxMessage(ctx context.Context, id string) error {
+	if mock.DeleteOutboxMessageFunc == nil {
+		panic("FeedbackStoreMock.DeleteOutboxMessageFunc: method is nil but FeedbackStore.DeleteOutboxMessage was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockDeleteOutboxMessage.Lock()
+	mock.calls.DeleteOutboxMessage = append(mock.calls.DeleteOutboxMessage, callInfo)
+	mock.lockDeleteOutboxMessage.Unlock()
+	return mock.DeleteOutboxMessageFunc(ctx, id)
+}
+
+// DeleteOutboxMessageCalls gets all the calls that were made to DeleteOutboxMessage.
+// Check the length with:
+//
+//	len(mockedFeedbackStore.DeleteOutboxMessageCalls())
+func (mock *FeedbackStoreMock) DeleteOutboxMessageCalls() []struct {
+	Ctx context.Context
+	ID  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+	}
+	mock.lockDeleteOutboxMessage.RLock()
+	calls = mock.calls.DeleteOutboxMessage
+	mock.lockDeleteOutboxMessage.RUnlock()
+	return calls
+}
+
+// GetDigestEntries calls GetDigestEntriesFunc.
+func (mock *FeedbackStoreMock) GetDigestEntries(ctx context.Context, route string) ([]*models.DigestEntry, error) {
+	if mock.GetDigestEntriesFunc == nil {
+		panic("FeedbackStoreMock.GetDigestEntriesFunc: method is nil but FeedbackStore.GetDigestEntries was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Route string
+	}{
+		Ctx:   ctx,
+		Route: route,
+	}
+	mock.lockGetDigestEntries.Lock()
+	mock.calls.GetDigestEntries = append(mock.calls.GetDigestEntries, callInfo)
+	mock.lockGetDigestEntries.Unlock()
+	return mock.GetDigestEntriesFunc(ctx, route)
+}
+
+// GetDigestEntriesCalls gets all the calls that were made to GetDigestEntries.
+// Check the length with:
+//
+//	len(mockedFeedbackStore.GetDigestEntriesCalls())
+func (mock *FeedbackStoreMock) GetDigestEntriesCalls() []struct {
+	Ctx   context.Context
+	Route string
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Route string
+	}
+	mock.lockGetDigestEntries.RLock()
+	calls = mock.calls.GetDigestEntries
+	mock.lockGetDigestEntries.RUnlock()
+	return calls
+}
+
+// GetFeedback calls GetFeedbackFunc.
+func (mock *FeedbackStoreMock) GetFeedback(ctx context.Context, id string) (*models.Feedback, error) {
+	if mock.GetFeedbackFunc == nil {
+		panic("FeedbackStoreMock.GetFeedbackFunc: method is nil but FeedbackStore.GetFeedback was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		ID  string
+	}{
+		Ctx: ctx,
+		ID:  id,
+	}
+	mock.lockGetFeedback.Lock()
+	mock.calls.GetFeedback = append(mock.calls.GetFeedback, callInfo)
+	mock.lockGetFeedback.Unlock()
+	return mock.GetFeedbackFunc(ctx, id)
+}
+
+// GetFeedbackCalls gets all the calls that were made to GetFeedback.
+// Check the length with:
+//
+//	len(mockedFeedbackStore.GetFeedbackCalls())
+func (mock *FeedbackStoreMock) GetFeedbackCalls() []struct {
+	Ctx context.Context
+	ID  string
+} {
+	var calls []struct {
+		Ctx context.Context
+		ID  string
+	}
+	mock.lockGetFeedback.RLock()
+	calls = mock.calls.GetFeedback
+	mock.lockGetFeedback.RUnlock()
+	return calls
+}
+
+// GetFeedbackList calls GetFeedbackListFunc.
+func (mock *FeedbackStoreMock) GetFeedbackList(ctx context.Context, filter *models.FeedbackFilter) ([]*models.Feedback, error) {
+	if mock.GetFeedbackListFunc == nil {
+		panic("FeedbackStoreMock.GetFeedbackListFunc: method is nil but FeedbackStore.GetFeedbackList was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Filter *models.FeedbackFilter
+	}{
+		Ctx:    ctx,
+		Filter: filter,
+	}
+	mock.lockGetFeedbackList.Lock()
+	mock.calls.GetFeedbackList = append(mock.calls.GetFeedbackList, callInfo)
+	mock.lockGetFeedbackList.Unlock()
+	return mock.GetFeedbackListFunc(ctx, filter)
+}
+
+// GetFeedbackListCalls gets all the calls that were made to GetFeedbackList.
+// Check the length with:
+//
+//	len(mockedFeedbackStore.GetFeedbackListCalls())
+func (mock *FeedbackStoreMock) GetFeedbackListCalls() []struct {
+	Ctx    context.Context
+	Filter *models.FeedbackFilter
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Filter *models.FeedbackFilter
+	}
+	mock.lockGetFeedbackList.RLock()
+	calls = mock.calls.GetFeedbackList
+	mock.lockGetFeedbackList.RUnlock()
+	return calls
+}
+
+// GetPendingOutboxMessages calls GetPendingOutboxMessagesFunc.
+func (mock *FeedbackStoreMock) GetPendingOutboxMessages(ctx context.Context, now time.Time, limit int) ([]*models.OutboxMessage, error) {
+	if mock.GetPendingOutboxMessagesFunc == nil {
+		panic("FeedbackStoreMock.GetPendingOutboxMessagesFunc: method is nil but FeedbackStore.GetPendingOutboxMessages was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Now   time.Time
+		Limit int
+	}{
+		Ctx:   ctx,
+		Now:   now,
+		Limit: limit,
+	}
+	mock.lockGetPendingOutboxMessages.Lock()
+	mock.calls.GetPendingOutboxMessages = append(mock.calls.GetPendingOutboxMessages, callInfo)
+	mock.lockGetPendingOutboxMessages.Unlock()
+	return mock.GetPendingOutboxMessagesFunc(ctx, now, limit)
+}
+
+// GetPendingOutboxMessagesCalls gets all the calls that were made to GetPendingOutboxMessages.
+// Check the length with:
+//
+//	len(mockedFeedbackStore.GetPendingOutboxMessagesCalls())
+func (mock *FeedbackStoreMock) GetPendingOutboxMessagesCalls() []struct {
+	Ctx   context.Context
+	Now   time.Time
+	Limit int
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Now   time.Time
+		Limit int
+	}
+	mock.lockGetPendingOutboxMessages.RLock()
+	calls = mock.calls.GetPendingOutboxMessages
+	mock.lockGetPendingOutboxMessages.RUnlock()
+	return calls
+}
+
+// UpdateOutboxMessage calls UpdateOutboxMessageFunc.
+func (mock *FeedbackStoreMock) UpdateOutboxMessage(ctx context.Context, msg *models.OutboxMessage) error {
+	if mock.UpdateOutboxMessageFunc == nil {
+		panic("FeedbackStoreMock.UpdateOutboxMessageFunc: method is nil but FeedbackStore.UpdateOutboxMessage was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+		Msg *models.OutboxMessage
+	}{
+		Ctx: ctx,
+		Msg: msg,
+	}
+	mock.lockUpdateOutboxMessage.Lock()
+	mock.calls.UpdateOutboxMessage = append(mock.calls.UpdateOutboxMessage, callInfo)
+	mock.lockUpdateOutboxMessage.Unlock()
+	return mock.UpdateOutboxMessageFunc(ctx, msg)
+}
+
+// UpdateOutboxMessageCalls gets all the calls that were made to UpdateOutboxMessage.
+// Check the length with:
+//
+//	len(mockedFeedbackStore.UpdateOutboxMessageCalls())
+func (mock *FeedbackStoreMock) UpdateOutboxMessageCalls() []struct {
+	Ctx context.Context
+	Msg *models.OutboxMessage
+} {
+	var calls []struct {
+		Ctx context.Context
+		Msg *models.OutboxMessage
+	}
+	mock.lockUpdateOutboxMessage.RLock()
+	calls = mock.calls.UpdateOutboxMessage
+	mock.lockUpdateOutboxMessage.RUnlock()
+	return calls
+}
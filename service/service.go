@@ -7,6 +7,12 @@ import (
 
 	"github.com/ONSdigital/dp-feedback-api/api"
 	"github.com/ONSdigital/dp-feedback-api/config"
+	"github.com/ONSdigital/dp-feedback-api/digest"
+	"github.com/ONSdigital/dp-feedback-api/email"
+	"github.com/ONSdigital/dp-feedback-api/event"
+	"github.com/ONSdigital/dp-feedback-api/outbox"
+	"github.com/ONSdigital/dp-feedback-api/routing"
+	"github.com/ONSdigital/dp-feedback-api/webhook"
 	"github.com/ONSdigital/log.go/v2/log"
 	"github.com/go-chi/chi/v5"
 	"github.com/pkg/errors"
@@ -14,11 +20,18 @@ import (
 
 // Service contains all the configs, server and clients to run the API
 type Service struct {
-	Config      *config.Config
-	Server      HTTPServer
-	API         *api.API
-	EmailSender EmailSender
-	HealthCheck HealthChecker
+	Config         *config.Config
+	Server         HTTPServer
+	API            *api.API
+	EmailSender    EmailSender
+	Outbox         *outbox.Outbox
+	Digests        *digest.Scheduler
+	Webhook        *webhook.Notifier
+	KafkaProducer  KafkaProducer
+	Events         *event.Publisher
+	IdentityClient IdentityClient
+	FeedbackStore  FeedbackStore
+	HealthCheck    HealthChecker
 }
 
 func New() *Service {
@@ -35,8 +48,80 @@ func (svc *Service) Init(ctx context.Context, cfg *config.Config, buildTime, git
 	}
 	svc.Config = cfg
 
+	var notifiers []api.Notifier
+
 	// Get Email Sender
-	svc.EmailSender = GetEmailSender(cfg.Mail)
+	if cfg.HasNotifier(config.NotifierEmail) {
+		if svc.EmailSender, err = GetEmailSender(cfg.Mail); err != nil {
+			return fmt.Errorf("could not instantiate email sender: %w", err)
+		}
+	}
+
+	// Get Identity Client
+	svc.IdentityClient = GetIdentityClient(cfg)
+
+	// Get Feedback Store
+	if svc.FeedbackStore, err = GetFeedbackStore(ctx, cfg.Store); err != nil {
+		return fmt.Errorf("could not instantiate feedback store: %w", err)
+	}
+
+	if cfg.HasNotifier(config.NotifierEmail) {
+		// Create the outbox, which delivers the emails queued by the API in the background
+		svc.Outbox = outbox.New(svc.FeedbackStore, svc.EmailSender, cfg.Outbox)
+
+		// Load Email Templates
+		emailTemplates, err := email.LoadTemplates(cfg.EmailTemplates, cfg.Sanitize)
+		if err != nil {
+			return fmt.Errorf("could not load email templates: %w", err)
+		}
+
+		// Load Feedback Routes
+		routes, err := routing.Load(cfg.FeedbackRoutesFile, cfg.FeedbackTo)
+		if err != nil {
+			return fmt.Errorf("could not load feedback routes: %w", err)
+		}
+
+		// Create the digest scheduler, which queues the digest emails of the routes configured with one in the outbox
+		svc.Digests = digest.New(svc.FeedbackStore, svc.Outbox, routes, emailTemplates, cfg.FeedbackFrom, cfg.Digest)
+
+		notifiers = append(notifiers, &email.Notifier{
+			Templates: emailTemplates,
+			Routes:    routes,
+			Queue:     svc.Outbox,
+			Digests:   svc.Digests,
+			From:      cfg.FeedbackFrom,
+		})
+	}
+
+	// Create the webhook notifier, which posts the feedback to the webhook in the background
+	if cfg.HasNotifier(config.NotifierWebhook) {
+		if svc.Webhook, err = webhook.New(cfg.Webhook); err != nil {
+			return fmt.Errorf("could not instantiate webhook notifier: %w", err)
+		}
+		notifiers = append(notifiers, svc.Webhook)
+	}
+
+	// Get Kafka Producer, which publishes the feedback-received events queued by the API in the background
+	var events api.EventPublisher
+	if cfg.Kafka.Enabled {
+		if svc.KafkaProducer, err = GetKafkaProducer(ctx, cfg.Kafka); err != nil {
+			return fmt.Errorf("could not instantiate kafka producer: %w", err)
+		}
+		svc.Events = event.NewPublisher(svc.KafkaProducer, cfg.Kafka.QueueSize)
+		events = svc.Events
+	}
+
+	// Get Rate Limiter, which limits the submission of feedback per caller and per client IP
+	rateLimiter, err := GetRateLimiter(cfg.RateLimit)
+	if err != nil {
+		return fmt.Errorf("could not instantiate rate limiter: %w", err)
+	}
+
+	// Get Idempotency Cache, which returns the original response to the retries of the submission of feedback
+	idempotencyCache := GetIdempotencyCache(cfg.IdempotencyWindow)
+
+	// Get Duplicate Detector, which counts the near-duplicates of recent feedback as occurrences of the original
+	duplicates := GetDuplicateDetector(cfg.Duplicate)
 
 	// Get HealthCheck
 	if svc.HealthCheck, err = GetHealthCheck(cfg, buildTime, gitCommit, version); err != nil {
@@ -52,7 +137,7 @@ func (svc *Service) Init(ctx context.Context, cfg *config.Config, buildTime, git
 	svc.Server = GetHTTPServer(cfg.BindAddr, r)
 
 	// Create API
-	svc.API = api.Setup(ctx, cfg, r, svc.EmailSender)
+	svc.API = api.Setup(ctx, cfg, r, notifiers, events, rateLimiter, idempotencyCache, duplicates, svc.IdentityClient, svc.FeedbackStore)
 	return nil
 }
 
@@ -62,6 +147,18 @@ func (svc *Service) Start(ctx context.Context, svcErrors chan error) {
 
 	svc.HealthCheck.Start(ctx)
 
+	// Start delivering the queued emails and webhook requests, publishing the queued events, and sending the digests when they are due
+	if svc.Outbox != nil {
+		svc.Outbox.Start(ctx)
+		svc.Digests.Start(ctx)
+	}
+	if svc.Webhook != nil {
+		svc.Webhook.Start(ctx)
+	}
+	if svc.Events != nil {
+		svc.Events.Start(ctx)
+	}
+
 	// Run the http server in a new go-routine
 	go func() {
 		if err := svc.Server.ListenAndServe(); err != nil {
@@ -99,7 +196,75 @@ func (svc *Service) Close(ctx context.Context) error {
 			log.Info(ctx, "successfully stopped http server")
 		}
 
-		// TODO: Close other dependencies, in the expected order
+		// post any queued feedback to the webhook once no more feedback can be received
+		if svc.Webhook != nil {
+			log.Info(ctx, "closing webhook notifier...")
+			if err := svc.Webhook.Close(ctx); err != nil {
+				log.Error(ctx, "failed to close webhook notifier", err)
+				hasShutdownError = true
+			}
+			log.Info(ctx, "successfully closed webhook notifier")
+		}
+
+		// send any queued events to the kafka producer once no more feedback can be received
+		if svc.Events != nil {
+			log.Info(ctx, "closing event publisher...")
+			if err := svc.Events.Close(ctx); err != nil {
+				log.Error(ctx, "failed to close event publisher", err)
+				hasShutdownError = true
+			}
+			log.Info(ctx, "successfully closed event publisher")
+		}
+
+		// publish any buffered events and close the connections to the kafka brokers once the queued events have been sent
+		if svc.KafkaProducer != nil {
+			log.Info(ctx, "closing kafka producer...")
+			if err := svc.KafkaProducer.Close(ctx); err != nil {
+				log.Error(ctx, "failed to close kafka producer", err)
+				hasShutdownError = true
+			}
+			log.Info(ctx, "successfully closed kafka producer")
+		}
+
+		// stop sending digests once no more feedback can be added to them, before the outbox is closed
+		if svc.Digests != nil {
+			log.Info(ctx, "closing digest scheduler...")
+			if err := svc.Digests.Close(ctx); err != nil {
+				log.Error(ctx, "failed to close digest scheduler", err)
+				hasShutdownError = true
+			}
+			log.Info(ctx, "successfully closed digest scheduler")
+		}
+
+		// deliver any queued emails and stop the outbox worker once no more emails can be queued
+		if svc.Outbox != nil {
+			log.Info(ctx, "closing outbox...")
+			if err := svc.Outbox.Close(ctx); err != nil {
+				log.Error(ctx, "failed to close outbox", err)
+				hasShutdownError = true
+			}
+			log.Info(ctx, "successfully closed outbox")
+		}
+
+		// close the connections to the mail server once the outbox has delivered the queued emails
+		if svc.EmailSender != nil {
+			log.Info(ctx, "closing email sender...")
+			if err := svc.EmailSender.Close(ctx); err != nil {
+				log.Error(ctx, "failed to close email sender", err)
+				hasShutdownError = true
+			}
+			log.Info(ctx, "successfully closed email sender")
+		}
+
+		// close the feedback store once no more requests can be received and the outbox is closed
+		if svc.FeedbackStore != nil {
+			log.Info(ctx, "closing feedback store...")
+			if err := svc.FeedbackStore.Close(ctx); err != nil {
+				log.Error(ctx, "failed to close feedback store", err)
+				hasShutdownError = true
+			}
+			log.Info(ctx, "successfully closed feedback store")
+		}
 	}()
 
 	// wait for shutdown success (via cancel) or failure (timeout)
@@ -125,5 +290,23 @@ func (svc *Service) registerCheckers(ctx context.Context) (err error) {
 		return errors.New("healthcheck must be created before registering checkers")
 	}
 
+	if svc.EmailSender != nil {
+		if err = svc.HealthCheck.AddCheck(email.CheckerName, svc.EmailSender.Checker); err != nil {
+			return fmt.Errorf("error adding check for email sender: %w", err)
+		}
+	}
+
+	if svc.IdentityClient != nil {
+		if err = svc.HealthCheck.AddCheck(IdentityCheckerName, svc.IdentityClient.Checker); err != nil {
+			return fmt.Errorf("error adding check for identity client: %w", err)
+		}
+	}
+
+	if svc.KafkaProducer != nil {
+		if err = svc.HealthCheck.AddCheck(event.CheckerName, svc.KafkaProducer.Checker); err != nil {
+			return fmt.Errorf("error adding check for kafka producer: %w", err)
+		}
+	}
+
 	return nil
 }
@@ -4,15 +4,26 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/ONSdigital/dp-api-clients-go/v2/identity"
 	"github.com/ONSdigital/dp-healthcheck/healthcheck"
+	"github.com/ONSdigital/dp-kafka/v4/kafkatest"
 
+	"github.com/ONSdigital/dp-feedback-api/api"
 	"github.com/ONSdigital/dp-feedback-api/config"
+	"github.com/ONSdigital/dp-feedback-api/digest"
+	"github.com/ONSdigital/dp-feedback-api/email"
+	"github.com/ONSdigital/dp-feedback-api/models"
+	"github.com/ONSdigital/dp-feedback-api/outbox"
+	"github.com/ONSdigital/dp-feedback-api/routing"
 	"github.com/ONSdigital/dp-feedback-api/service"
 	serviceMock "github.com/ONSdigital/dp-feedback-api/service/mock"
+	"github.com/ONSdigital/dp-feedback-api/webhook"
+	dprequest "github.com/ONSdigital/dp-net/v3/request"
 
 	"github.com/pkg/errors"
 	. "github.com/smartystreets/goconvey/convey"
@@ -28,6 +39,11 @@ var (
 
 var (
 	errHealthcheck = errors.New("healthCheck error")
+	errAddCheck    = errors.New("add check error")
+	errEmailSender = errors.New("email sender error")
+	errStore       = errors.New("feedback store error")
+	errKafka       = errors.New("kafka producer error")
+	errRateLimiter = errors.New("rate limiter error")
 )
 
 func TestInit(t *testing.T) {
@@ -41,6 +57,8 @@ func TestInit(t *testing.T) {
 		}
 		serverMock := &serviceMock.HTTPServerMock{}
 		emailSenderMock := &serviceMock.EmailSenderMock{}
+		identityClientMock := &serviceMock.IdentityClientMock{}
+		feedbackStoreMock := &serviceMock.FeedbackStoreMock{}
 
 		// Initialiser functions
 		service.GetHealthCheck = func(_ *config.Config, _, _, _ string) (service.HealthChecker, error) {
@@ -51,13 +69,55 @@ func TestInit(t *testing.T) {
 			return serverMock
 		}
 
-		service.GetEmailSender = func(_ *config.Mail) service.EmailSender {
-			return emailSenderMock
+		service.GetEmailSender = func(_ *config.Mail) (service.EmailSender, error) {
+			return emailSenderMock, nil
+		}
+
+		service.GetIdentityClient = func(_ *config.Config) service.IdentityClient {
+			return identityClientMock
+		}
+
+		service.GetFeedbackStore = func(_ context.Context, _ *config.Store) (service.FeedbackStore, error) {
+			return feedbackStoreMock, nil
+		}
+
+		kafkaProducerMock := &kafkatest.IProducerMock{}
+		service.GetKafkaProducer = func(_ context.Context, _ *config.Kafka) (service.KafkaProducer, error) {
+			return kafkaProducerMock, nil
+		}
+
+		rateLimiterMock := &serviceMock.RateLimiterMock{}
+		service.GetRateLimiter = func(_ *config.RateLimit) (service.RateLimiter, error) {
+			return rateLimiterMock, nil
+		}
+
+		idempotencyCacheMock := &serviceMock.IdempotencyCacheMock{}
+		service.GetIdempotencyCache = func(_ time.Duration) service.IdempotencyCache {
+			return idempotencyCacheMock
+		}
+
+		duplicatesMock := &serviceMock.DuplicateDetectorMock{}
+		service.GetDuplicateDetector = func(_ *config.Duplicate) service.DuplicateDetector {
+			return duplicatesMock
 		}
 
 		// Service
 		svc := service.New()
 
+		Convey("Given that creating the email sender returns an error", func() {
+			service.GetEmailSender = func(_ *config.Mail) (service.EmailSender, error) {
+				return nil, errEmailSender
+			}
+
+			Convey("Then service Init fails with the same error and no further initialisations are attempted", func() {
+				err := svc.Init(ctx, cfg, testBuildTime, testGitCommit, testVersion)
+				So(errors.Unwrap(err), ShouldResemble, errEmailSender)
+				So(svc.FeedbackStore, ShouldBeNil)
+				So(svc.HealthCheck, ShouldBeNil)
+				So(svc.Server, ShouldBeNil)
+			})
+		})
+
 		Convey("Given that initialising healthcheck returns an error", func() {
 			service.GetHealthCheck = func(cfg *config.Config, buildTime, gitCommit, version string) (service.HealthChecker, error) {
 				return nil, errHealthcheck
@@ -74,16 +134,185 @@ func TestInit(t *testing.T) {
 			})
 		})
 
+		Convey("Given that the email sender checker cannot be registered", func() {
+			hcMock.AddCheckFunc = func(name string, checker healthcheck.Checker) error { return errAddCheck }
+
+			Convey("Then service Init fails with the expected error", func() {
+				err := svc.Init(ctx, cfg, testBuildTime, testGitCommit, testVersion)
+				So(err, ShouldNotBeNil)
+				So(errors.Is(err, errAddCheck), ShouldBeTrue)
+				So(svc.Server, ShouldBeNil)
+			})
+		})
+
+		Convey("Given that opening the feedback store returns an error", func() {
+			service.GetFeedbackStore = func(_ context.Context, _ *config.Store) (service.FeedbackStore, error) {
+				return nil, errStore
+			}
+
+			Convey("Then service Init fails with the same error and no further initialisations are attempted", func() {
+				err := svc.Init(ctx, cfg, testBuildTime, testGitCommit, testVersion)
+				So(errors.Unwrap(err), ShouldResemble, errStore)
+				So(svc.Config, ShouldResemble, cfg)
+				So(svc.HealthCheck, ShouldBeNil)
+				So(svc.Server, ShouldBeNil)
+			})
+		})
+
+		Convey("Given that the configured email templates cannot be loaded", func() {
+			templatesCfg := *cfg
+			templatesCfg.EmailTemplates = &config.EmailTemplates{TextPath: "missing.tmpl"}
+
+			Convey("Then service Init fails and no further initialisations are attempted", func() {
+				err := svc.Init(ctx, &templatesCfg, testBuildTime, testGitCommit, testVersion)
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldStartWith, "could not load email templates")
+				So(svc.HealthCheck, ShouldBeNil)
+				So(svc.Server, ShouldBeNil)
+			})
+		})
+
+		Convey("Given that the configured feedback routes cannot be loaded", func() {
+			routesCfg := *cfg
+			routesCfg.FeedbackRoutesFile = "missing.json"
+
+			Convey("Then service Init fails and no further initialisations are attempted", func() {
+				err := svc.Init(ctx, &routesCfg, testBuildTime, testGitCommit, testVersion)
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldStartWith, "could not load feedback routes")
+				So(svc.HealthCheck, ShouldBeNil)
+				So(svc.Server, ShouldBeNil)
+			})
+		})
+
+		Convey("Given that the webhook notifier is enabled without a url", func() {
+			webhookCfg := *cfg
+			webhookCfg.Notifiers = []string{config.NotifierEmail, config.NotifierWebhook}
+			webhookCfg.Webhook = &config.Webhook{}
+
+			Convey("Then service Init fails and no further initialisations are attempted", func() {
+				err := svc.Init(ctx, &webhookCfg, testBuildTime, testGitCommit, testVersion)
+				So(err, ShouldNotBeNil)
+				So(err.Error(), ShouldStartWith, "could not instantiate webhook notifier")
+				So(svc.HealthCheck, ShouldBeNil)
+				So(svc.Server, ShouldBeNil)
+			})
+		})
+
+		Convey("Given that only the webhook notifier is enabled", func() {
+			webhookCfg := *cfg
+			webhookCfg.Notifiers = []string{config.NotifierWebhook}
+			webhookCfg.Webhook = &config.Webhook{URL: "http://localhost:8080/hook", QueueSize: 1}
+
+			Convey("Then service Init succeeds without the email dependencies", func() {
+				err := svc.Init(ctx, &webhookCfg, testBuildTime, testGitCommit, testVersion)
+				So(err, ShouldBeNil)
+				So(svc.EmailSender, ShouldBeNil)
+				So(svc.Outbox, ShouldBeNil)
+				So(svc.Digests, ShouldBeNil)
+				So(svc.Webhook, ShouldNotBeNil)
+				So(svc.API.Notifiers, ShouldResemble, []api.Notifier{svc.Webhook})
+				So(hcMock.AddCheckCalls(), ShouldHaveLength, 1)
+				So(hcMock.AddCheckCalls()[0].Name, ShouldEqual, "Zebedee")
+			})
+		})
+
+		Convey("Given that kafka is enabled", func() {
+			kafkaCfg := *cfg
+			kafkaCfg.Kafka = &config.Kafka{Enabled: true, FeedbackReceivedTopic: "feedback-received"}
+
+			Convey("Then service Init succeeds, and the API publishes the events with the kafka producer", func() {
+				err := svc.Init(ctx, &kafkaCfg, testBuildTime, testGitCommit, testVersion)
+				So(err, ShouldBeNil)
+				So(svc.KafkaProducer, ShouldEqual, kafkaProducerMock)
+				So(svc.API.Events, ShouldNotBeNil)
+
+				Convey("Then the kafka producer check is registered", func() {
+					So(hcMock.AddCheckCalls(), ShouldHaveLength, 3)
+					So(hcMock.AddCheckCalls()[2].Name, ShouldEqual, "Kafka producer")
+				})
+			})
+
+			Convey("Given that creating the kafka producer returns an error", func() {
+				service.GetKafkaProducer = func(_ context.Context, _ *config.Kafka) (service.KafkaProducer, error) {
+					return nil, errKafka
+				}
+
+				Convey("Then service Init fails with the same error and no further initialisations are attempted", func() {
+					err := svc.Init(ctx, &kafkaCfg, testBuildTime, testGitCommit, testVersion)
+					So(errors.Unwrap(err), ShouldResemble, errKafka)
+					So(svc.HealthCheck, ShouldBeNil)
+					So(svc.Server, ShouldBeNil)
+				})
+			})
+		})
+
+		Convey("Given that creating the rate limiter returns an error", func() {
+			service.GetRateLimiter = func(_ *config.RateLimit) (service.RateLimiter, error) {
+				return nil, errRateLimiter
+			}
+
+			Convey("Then service Init fails with the same error and no further initialisations are attempted", func() {
+				err := svc.Init(ctx, cfg, testBuildTime, testGitCommit, testVersion)
+				So(errors.Unwrap(err), ShouldResemble, errRateLimiter)
+				So(svc.HealthCheck, ShouldBeNil)
+				So(svc.Server, ShouldBeNil)
+			})
+		})
+
 		Convey("Given that all dependencies are successfully initialised", func() {
 			Convey("Then service Init succeeds, all dependencies are initialised", func() {
 				err := svc.Init(ctx, cfg, testBuildTime, testGitCommit, testVersion)
 				So(err, ShouldBeNil)
 				So(svc.Config, ShouldResemble, cfg)
 				So(svc.Server, ShouldEqual, serverMock)
+				So(svc.IdentityClient, ShouldEqual, identityClientMock)
+				So(svc.FeedbackStore, ShouldEqual, feedbackStoreMock)
+				So(svc.Outbox, ShouldNotBeNil)
+				So(svc.Digests, ShouldNotBeNil)
+				So(svc.Webhook, ShouldBeNil)
+				So(svc.KafkaProducer, ShouldBeNil)
+				So(svc.API.Events, ShouldBeNil)
+				So(svc.API.RateLimiter, ShouldEqual, rateLimiterMock)
+				So(svc.API.IdempotencyCache, ShouldEqual, idempotencyCacheMock)
+				So(svc.API.Duplicates, ShouldEqual, duplicatesMock)
+				So(svc.API.Notifiers, ShouldHaveLength, 1)
+				emailNotifier, ok := svc.API.Notifiers[0].(*email.Notifier)
+				So(ok, ShouldBeTrue)
+				So(emailNotifier.Queue, ShouldEqual, svc.Outbox)
+				So(emailNotifier.Digests, ShouldEqual, svc.Digests)
 				So(svc.HealthCheck, ShouldResemble, hcMock)
 
-				Convey("Then all checks are registered (none yet)", func() {
-					So(hcMock.AddCheckCalls(), ShouldHaveLength, 0)
+				Convey("Then all checks are registered", func() {
+					So(hcMock.AddCheckCalls(), ShouldHaveLength, 2)
+					So(hcMock.AddCheckCalls()[0].Name, ShouldEqual, "SMTP")
+					So(hcMock.AddCheckCalls()[1].Name, ShouldEqual, "Zebedee")
+				})
+
+				Convey("Then the counters published by expvar are only served to the feedback readers", func() {
+					identityClientMock.CheckRequestFunc = func(req *http.Request, florenceToken, serviceAuthToken string) (context.Context, int, identity.AuthFailure, error) {
+						return dprequest.SetCaller(req.Context(), serviceAuthToken), http.StatusOK, nil, nil
+					}
+					readers := cfg.FeedbackReaders
+					defer func() { cfg.FeedbackReaders = readers }()
+					cfg.FeedbackReaders = []string{"reader"}
+
+					w := httptest.NewRecorder()
+					svc.API.Router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/vars", http.NoBody))
+					So(w.Code, ShouldEqual, http.StatusUnauthorized)
+
+					w = httptest.NewRecorder()
+					req := httptest.NewRequest(http.MethodGet, "/debug/vars", http.NoBody)
+					req.Header.Set("Authorization", "Bearer writer")
+					svc.API.Router.ServeHTTP(w, req)
+					So(w.Code, ShouldEqual, http.StatusForbidden)
+
+					w = httptest.NewRecorder()
+					req = httptest.NewRequest(http.MethodGet, "/debug/vars", http.NoBody)
+					req.Header.Set("Authorization", "Bearer reader")
+					svc.API.Router.ServeHTTP(w, req)
+					So(w.Code, ShouldEqual, http.StatusOK)
+					So(w.Body.String(), ShouldContainSubstring, `"feedback_bot_submissions"`)
 				})
 			})
 		})
@@ -102,11 +331,24 @@ func TestStart(t *testing.T) {
 		serverWg := &sync.WaitGroup{}
 		serverMock := &serviceMock.HTTPServerMock{}
 
+		storeMock := &serviceMock.FeedbackStoreMock{
+			GetPendingOutboxMessagesFunc: func(ctx context.Context, now time.Time, limit int) ([]*models.OutboxMessage, error) {
+				return nil, nil
+			},
+		}
+		emailMock := &serviceMock.EmailSenderMock{}
+		routes, err := routing.Load("", cfg.FeedbackTo)
+		So(err, ShouldBeNil)
+
 		svc := &service.Service{
 			Config:      cfg,
 			Server:      serverMock,
 			HealthCheck: hcMock,
+			Outbox:      outbox.New(storeMock, emailMock, cfg.Outbox),
 		}
+		svc.Digests = digest.New(storeMock, svc.Outbox, routes, nil, cfg.FeedbackFrom, cfg.Digest)
+		defer svc.Outbox.Close(ctx)
+		defer svc.Digests.Close(ctx)
 
 		Convey("When a service with a successful HTTP server is started", func() {
 			serverMock.ListenAndServeFunc = func() error {
@@ -146,6 +388,8 @@ func TestClose(t *testing.T) {
 		So(err, ShouldBeNil)
 
 		hcStopped := false
+		serverStopped := false
+		outboxClosed := false
 
 		// healthcheck Stop does not depend on any other service being closed/stopped
 		hcMock := &serviceMock.HealthCheckerMock{
@@ -161,21 +405,70 @@ func TestClose(t *testing.T) {
 				if !hcStopped {
 					return errors.New("Server stopped before healthcheck")
 				}
+				serverStopped = true
+				return nil
+			},
+		}
+
+		// the outbox is drained once the server is stopped, and the feedback store Close will fail if the outbox is not closed
+		storeMock := &serviceMock.FeedbackStoreMock{
+			GetPendingOutboxMessagesFunc: func(ctx context.Context, now time.Time, limit int) ([]*models.OutboxMessage, error) {
+				if !serverStopped {
+					return nil, errors.New("Outbox drained before server stopped")
+				}
+				outboxClosed = true
+				return nil, nil
+			},
+			CloseFunc: func(ctx context.Context) error {
+				if !outboxClosed {
+					return errors.New("Feedback store closed before outbox")
+				}
+				return nil
+			},
+		}
+
+		// the email sender Close will fail if the outbox is not closed
+		emailSenderMock := &serviceMock.EmailSenderMock{
+			CloseFunc: func(ctx context.Context) error {
+				if !outboxClosed {
+					return errors.New("Email sender closed before outbox")
+				}
 				return nil
 			},
 		}
 
 		svc := &service.Service{
-			Config:      cfg,
-			Server:      serverMock,
-			HealthCheck: hcMock,
+			Config:        cfg,
+			Server:        serverMock,
+			HealthCheck:   hcMock,
+			EmailSender:   emailSenderMock,
+			Outbox:        outbox.New(storeMock, emailSenderMock, cfg.Outbox),
+			FeedbackStore: storeMock,
+		}
+		svc.Webhook, err = webhook.New(&config.Webhook{URL: "http://localhost:8080/hook"})
+		So(err, ShouldBeNil)
+
+		// the kafka producer Close will fail if the server is not stopped
+		kafkaProducerMock := &kafkatest.IProducerMock{
+			CloseFunc: func(ctx context.Context) error {
+				if !serverStopped {
+					return errors.New("Kafka producer closed before server")
+				}
+				return nil
+			},
 		}
+		svc.KafkaProducer = kafkaProducerMock
 
 		Convey("Closing the service results in all the dependencies being closed in the expected order", func() {
 			err = svc.Close(context.Background())
 			So(err, ShouldBeNil)
 			So(hcMock.StopCalls(), ShouldHaveLength, 1)
 			So(serverMock.ShutdownCalls(), ShouldHaveLength, 1)
+			So(storeMock.GetPendingOutboxMessagesCalls(), ShouldHaveLength, 1)
+			So(emailSenderMock.CloseCalls(), ShouldHaveLength, 1)
+			So(storeMock.CloseCalls(), ShouldHaveLength, 1)
+			So(svc.Webhook.Notify(ctx, &models.Feedback{}), ShouldEqual, webhook.ErrNotifierClosed)
+			So(kafkaProducerMock.CloseCalls(), ShouldHaveLength, 1)
 		})
 
 		Convey("If services fail to stop, the Close operation tries to close all dependencies and returns an error", func() {
@@ -187,6 +480,8 @@ func TestClose(t *testing.T) {
 			So(err, ShouldNotBeNil)
 			So(hcMock.StopCalls(), ShouldHaveLength, 1)
 			So(serverMock.ShutdownCalls(), ShouldHaveLength, 1)
+			So(emailSenderMock.CloseCalls(), ShouldHaveLength, 1)
+			So(storeMock.CloseCalls(), ShouldHaveLength, 1)
 		})
 
 		Convey("If service times out while shutting down, the Close operation fails with the expected error", func() {
@@ -0,0 +1,162 @@
+// Package spam scores feedback submissions with local rules that detect link spam and SEO junk,
+// to decide whether they are tagged as possible spam, quarantined or rejected
+package spam
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/ONSdigital/dp-feedback-api/config"
+	"github.com/ONSdigital/dp-feedback-api/models"
+)
+
+// Reasons that contribute to the spam score
+const (
+	ReasonLinks              = "links"
+	ReasonExternalLinks      = "external_links"
+	ReasonSpamPhrases        = "spam_phrases"
+	ReasonRepeatedCharacters = "repeated_characters"
+)
+
+// Points added to the score by every rule
+const (
+	// LinkScore is added for every link in the description
+	LinkScore = 1
+	// ExternalLinkScore is added for every link in the description to a site outside the allowed domains, on top of LinkScore
+	ExternalLinkScore = 2
+	// SpamPhraseScore is added for every spam phrase found in the description
+	SpamPhraseScore = 3
+	// RepeatedCharactersScore is added if the description contains a character repeated at least RepeatedCharacters times in a row
+	RepeatedCharactersScore = 2
+	// RepeatedCharacters is the number of times a character has to be repeated in a row to add RepeatedCharactersScore
+	RepeatedCharacters = 6
+)
+
+var linkRegexp = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"']+`)
+
+// Scorer scores feedback submissions according to the configured spam phrases, and chooses the action to take with them
+// according to the configured thresholds. A nil Scorer does not score any feedback.
+type Scorer struct {
+	cfg     *config.Spam
+	domains []*config.DomainRule
+	phrases []*regexp.Regexp
+}
+
+// NewScorer returns a Scorer with the provided configuration, which considers the links to the hosts of the provided
+// allowed domains to be ONS links, whatever their path. It returns nil if no configuration is provided.
+func NewScorer(cfg *config.Spam, domains []*config.DomainRule) *Scorer {
+	if cfg == nil {
+		return nil
+	}
+	s := &Scorer{cfg: cfg, domains: domains}
+	for _, phrase := range cfg.Phrases {
+		words := strings.Fields(phrase)
+		if len(words) == 0 {
+			continue
+		}
+		for i := range words {
+			words[i] = regexp.QuoteMeta(words[i])
+		}
+		s.phrases = append(s.phrases, regexp.MustCompile(`(?i)\b`+strings.Join(words, `\s+`)+`\b`))
+	}
+	return s
+}
+
+// Score returns the spam score of the provided feedback, the reasons that contributed to it, and the action to take
+// according to the thresholds. It returns nil if the feedback does not look like spam at all.
+func (s *Scorer) Score(f *models.Feedback) *models.Spam {
+	if s == nil {
+		return nil
+	}
+
+	result := &models.Spam{}
+	add := func(reason string, points int) {
+		result.Score += points
+		result.Reasons = append(result.Reasons, reason)
+	}
+
+	links := linkRegexp.FindAllString(f.Feedback, -1)
+	if len(links) > 0 {
+		add(ReasonLinks, len(links)*LinkScore)
+	}
+	external := 0
+	for _, link := range links {
+		if !s.isONSLink(link) {
+			external++
+		}
+	}
+	if external > 0 {
+		add(ReasonExternalLinks, external*ExternalLinkScore)
+	}
+
+	phrases := 0
+	for _, p := range s.phrases {
+		if p.MatchString(f.Feedback) {
+			phrases++
+		}
+	}
+	if phrases > 0 {
+		add(ReasonSpamPhrases, phrases*SpamPhraseScore)
+	}
+
+	if hasRepeatedCharacters(f.Feedback, RepeatedCharacters) {
+		add(ReasonRepeatedCharacters, RepeatedCharactersScore)
+	}
+
+	if result.Score == 0 {
+		return nil
+	}
+	result.Action = s.action(result.Score)
+	return result
+}
+
+// action returns the action to take with feedback with the provided score, according to the highest threshold it reaches
+func (s *Scorer) action(score int) string {
+	reaches := func(threshold int) bool {
+		return threshold > 0 && score >= threshold
+	}
+	switch {
+	case reaches(s.cfg.RejectThreshold):
+		return models.SpamActionReject
+	case reaches(s.cfg.QuarantineThreshold):
+		return models.SpamActionQuarantine
+	case reaches(s.cfg.TagThreshold):
+		return models.SpamActionTag
+	default:
+		return ""
+	}
+}
+
+// isONSLink returns true if the host of the provided link matches any of the allowed domains
+func (s *Scorer) isONSLink(link string) bool {
+	u, err := url.Parse(models.NormaliseURL(strings.ToLower(link)))
+	if err != nil {
+		return false
+	}
+	for _, rule := range s.domains {
+		if rule.MatchesHost(u.Hostname()) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasRepeatedCharacters returns true if the provided text contains a character, other than whitespace,
+// repeated at least n times in a row
+func hasRepeatedCharacters(text string, n int) bool {
+	var last rune
+	count := 0
+	for _, r := range text {
+		if r == last && !unicode.IsSpace(r) {
+			count++
+			if count >= n {
+				return true
+			}
+			continue
+		}
+		last, count = r, 1
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package spam_test
+
+import (
+	"testing"
+
+	"github.com/ONSdigital/dp-feedback-api/config"
+	"github.com/ONSdigital/dp-feedback-api/models"
+	"github.com/ONSdigital/dp-feedback-api/spam"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func testConfig() *config.Spam {
+	return &config.Spam{
+		TagThreshold:        3,
+		QuarantineThreshold: 6,
+		RejectThreshold:     10,
+		Phrases:             []string{"buy now", "casino"},
+	}
+}
+
+// onsDomains returns the rules of the default allowed domains: ons.gov.uk and all its subdomains
+func onsDomains() []*config.DomainRule {
+	rules, err := config.ParseDomainRules([]string{"ons.gov.uk", "*.ons.gov.uk"})
+	So(err, ShouldBeNil)
+	return rules
+}
+
+func feedback(description string) *models.Feedback {
+	return &models.Feedback{Feedback: description}
+}
+
+func TestScore(t *testing.T) {
+	Convey("Given a spam scorer for the ONS domain", t, func() {
+		s := spam.NewScorer(testConfig(), onsDomains())
+
+		Convey("Then feedback without any signs of spam is not scored", func() {
+			So(s.Score(feedback("The chart on this page does not load.")), ShouldBeNil)
+		})
+
+		Convey("Then a link to an ONS page adds the link score", func() {
+			So(s.Score(feedback("The link to https://www.ons.gov.uk/economy is broken")), ShouldResemble, &models.Spam{
+				Score:   spam.LinkScore,
+				Reasons: []string{spam.ReasonLinks},
+			})
+		})
+
+		Convey("Then a link to another site is tagged as possible spam", func() {
+			So(s.Score(feedback("Great article, see www.example.com")), ShouldResemble, &models.Spam{
+				Score:   spam.LinkScore + spam.ExternalLinkScore,
+				Reasons: []string{spam.ReasonLinks, spam.ReasonExternalLinks},
+				Action:  models.SpamActionTag,
+			})
+		})
+
+		Convey("Then links to lookalike domains are not ONS links", func() {
+			result := s.Score(feedback("HTTPS://notons.gov.uk/page and http://ons.gov.uk.example.com"))
+			So(result.Score, ShouldEqual, 2*spam.LinkScore+2*spam.ExternalLinkScore)
+			So(result.Action, ShouldEqual, models.SpamActionQuarantine)
+		})
+
+		Convey("Then spam phrases are matched as whole words, ignoring case and spacing", func() {
+			result := s.Score(feedback("BUY  NOW at the best casino"))
+			So(result.Score, ShouldEqual, 2*spam.SpamPhraseScore)
+			So(result.Reasons, ShouldResemble, []string{spam.ReasonSpamPhrases})
+			So(result.Action, ShouldEqual, models.SpamActionQuarantine)
+
+			So(s.Score(feedback("the casinos data is wrong")), ShouldBeNil)
+		})
+
+		Convey("Then repeated characters add their score", func() {
+			result := s.Score(feedback("wow!!!!!! amazing"))
+			So(result.Score, ShouldEqual, spam.RepeatedCharactersScore)
+			So(result.Reasons, ShouldResemble, []string{spam.ReasonRepeatedCharacters})
+			So(result.Action, ShouldBeEmpty)
+
+			So(s.Score(feedback("wow!!!!! amazing      page")), ShouldBeNil)
+		})
+
+		Convey("Then feedback that reaches the reject threshold is rejected", func() {
+			result := s.Score(feedback("buy now at the casino, see www.example.com or www.example.org"))
+			So(result.Score, ShouldEqual, 2*spam.SpamPhraseScore+2*spam.LinkScore+2*spam.ExternalLinkScore)
+			So(result.Action, ShouldEqual, models.SpamActionReject)
+		})
+	})
+
+	Convey("Given a spam scorer for several allowed domains, some of them with a path allow-list", t, func() {
+		domains, err := config.ParseDomainRules([]string{"www.ons.gov.uk", "census.gov.uk/2021"})
+		So(err, ShouldBeNil)
+		s := spam.NewScorer(testConfig(), domains)
+
+		Convey("Then links to the hosts of any of the allowed domains are not external links, whatever their path", func() {
+			So(s.Score(feedback("see https://www.ons.gov.uk/economy and https://census.gov.uk/help")), ShouldResemble, &models.Spam{
+				Score:   2 * spam.LinkScore,
+				Reasons: []string{spam.ReasonLinks},
+			})
+		})
+
+		Convey("Then links to other hosts are external links", func() {
+			result := s.Score(feedback("see https://cy.ons.gov.uk/economy"))
+			So(result.Score, ShouldEqual, spam.LinkScore+spam.ExternalLinkScore)
+		})
+	})
+
+	Convey("Given a spam scorer with a disabled reject threshold", t, func() {
+		cfg := testConfig()
+		cfg.RejectThreshold = 0
+		s := spam.NewScorer(cfg, onsDomains())
+
+		Convey("Then feedback over every threshold is quarantined", func() {
+			f := feedback("buy now at the casino, see www.example.com")
+			So(s.Score(f).Action, ShouldEqual, models.SpamActionQuarantine)
+		})
+	})
+
+	Convey("A nil spam scorer does not score any feedback", t, func() {
+		s := spam.NewScorer(nil, onsDomains())
+		So(s, ShouldBeNil)
+		So(s.Score(feedback("buy now at www.example.com")), ShouldBeNil)
+	})
+}
@@ -0,0 +1,321 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ONSdigital/dp-feedback-api/config"
+	"github.com/ONSdigital/dp-feedback-api/models"
+	"github.com/ONSdigital/log.go/v2/log"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	feedbackBucket    = []byte("feedback")
+	feedbackIDsBucket = []byte("feedback_ids")
+	outboxBucket      = []byte("outbox")
+	digestsBucket     = []byte("digests")
+)
+
+// feedbackKeyTime is the fixed-width RFC 3339 format of the time in the feedback keys, so that they sort by the time the feedback was received
+const feedbackKeyTime = "2006-01-02T15:04:05.000000000Z07:00"
+
+// feedbackKey returns the key of the provided feedback: the time it was received followed by its ID,
+// so that the feedback received within a period of time can be found by seeking the cursor to its bounds
+func feedbackKey(f *models.Feedback) []byte {
+	return []byte(f.ReceivedAt.UTC().Format(feedbackKeyTime) + "/" + f.ID)
+}
+
+// timeKey returns the prefix of the keys of the feedback received at the provided time
+func timeKey(t time.Time) []byte {
+	return []byte(t.UTC().Format(feedbackKeyTime))
+}
+
+// Bolt is a FeedbackStore backed by an embedded BoltDB file.
+// The feedback is keyed by the time it was received, and indexed by its ID in a separate bucket.
+type Bolt struct {
+	db *bolt.DB
+}
+
+// NewBolt opens (or creates) the BoltDB file at the configured path and makes sure that the required buckets exist
+func NewBolt(ctx context.Context, cfg *config.Store) (*Bolt, error) {
+	db, err := bolt.Open(cfg.Path, 0o600, &bolt.Options{Timeout: cfg.Timeout})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bolt db file '%s': %w", cfg.Path, err)
+	}
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{feedbackBucket, feedbackIDsBucket, outboxBucket, digestsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to create bolt db buckets: %w", err)
+	}
+
+	log.Info(ctx, "bolt db feedback store opened", log.Data{"path": cfg.Path})
+	return &Bolt{db: db}, nil
+}
+
+// AddFeedback stores the provided feedback as a JSON document, keyed by the time it was received and indexed by its ID
+func (b *Bolt) AddFeedback(ctx context.Context, f *models.Feedback) error {
+	if err := validateForStorage(f); err != nil {
+		return err
+	}
+
+	doc, err := json.Marshal(f)
+	if err != nil {
+		return fmt.Errorf("failed to marshal feedback: %w", err)
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		ids := tx.Bucket(feedbackIDsBucket)
+		if ids.Get([]byte(f.ID)) != nil {
+			return ErrAlreadyExists
+		}
+		key := feedbackKey(f)
+		if err := ids.Put([]byte(f.ID), key); err != nil {
+			return err
+		}
+		return tx.Bucket(feedbackBucket).Put(key, doc)
+	})
+}
+
+// getFeedbackKey returns the key of the stored feedback with the provided ID, from the index of the IDs
+func getFeedbackKey(tx *bolt.Tx, id string) ([]byte, error) {
+	key := tx.Bucket(feedbackIDsBucket).Get([]byte(id))
+	if key == nil {
+		return nil, ErrNotFound
+	}
+	return key, nil
+}
+
+// GetFeedback returns the stored feedback with the provided ID
+func (b *Bolt) GetFeedback(ctx context.Context, id string) (*models.Feedback, error) {
+	f := &models.Feedback{}
+	err := b.db.View(func(tx *bolt.Tx) error {
+		key, err := getFeedbackKey(tx, id)
+		if err != nil {
+			return err
+		}
+		doc := tx.Bucket(feedbackBucket).Get(key)
+		if doc == nil {
+			return ErrNotFound
+		}
+		return json.Unmarshal(doc, f)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// AddOccurrence counts one more occurrence of the stored feedback with the provided ID, and returns the updated feedback
+func (b *Bolt) AddOccurrence(ctx context.Context, id string) (*models.Feedback, error) {
+	f := &models.Feedback{}
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		key, err := getFeedbackKey(tx, id)
+		if err != nil {
+			return err
+		}
+		bucket := tx.Bucket(feedbackBucket)
+		doc := bucket.Get(key)
+		if doc == nil {
+			return ErrNotFound
+		}
+		if err := json.Unmarshal(doc, f); err != nil {
+			return fmt.Errorf("failed to unmarshal feedback '%s': %w", id, err)
+		}
+
+		f.AddOccurrence()
+		updated, err := json.Marshal(f)
+		if err != nil {
+			return fmt.Errorf("failed to marshal feedback: %w", err)
+		}
+		return bucket.Put(key, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// GetFeedbackList returns all the stored feedback that matches the provided filter, newest first.
+// Only the feedback received within the time bounds of the filter, if any, is read: the cursor is moved to the upper bound,
+// and the feedback is read backwards until the lower bound.
+func (b *Bolt) GetFeedbackList(ctx context.Context, filter *models.FeedbackFilter) ([]*models.Feedback, error) {
+	items := []*models.Feedback{}
+	err := b.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(feedbackBucket).Cursor()
+
+		var k, v []byte
+		if filter != nil && filter.ReceivedBefore != nil {
+			// the upper bound is exclusive, so the newest feedback is the one before the first key at or after it
+			if k, _ = c.Seek(timeKey(*filter.ReceivedBefore)); k == nil {
+				k, v = c.Last()
+			} else {
+				k, v = c.Prev()
+			}
+		} else {
+			k, v = c.Last()
+		}
+
+		var lowerBound []byte
+		if filter != nil && filter.ReceivedAfter != nil {
+			lowerBound = timeKey(*filter.ReceivedAfter)
+		}
+
+		for ; k != nil; k, v = c.Prev() {
+			if lowerBound != nil && bytes.Compare(k, lowerBound) < 0 {
+				break
+			}
+			f := &models.Feedback{}
+			if err := json.Unmarshal(v, f); err != nil {
+				return fmt.Errorf("failed to unmarshal feedback '%s': %w", k, err)
+			}
+			if filter.Matches(f) {
+				items = append(items, f)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortNewestFirst(items)
+	return items, nil
+}
+
+// AddOutboxMessage stores the provided outbox message as a JSON document, keyed by its ID
+func (b *Bolt) AddOutboxMessage(ctx context.Context, msg *models.OutboxMessage) error {
+	if msg.ID == "" {
+		return ErrMissingOutboxMessageID
+	}
+
+	doc, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal outbox message: %w", err)
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(outboxBucket)
+		if bucket.Get([]byte(msg.ID)) != nil {
+			return ErrAlreadyExists
+		}
+		return bucket.Put([]byte(msg.ID), doc)
+	})
+}
+
+// UpdateOutboxMessage replaces the stored outbox message with the same ID by the provided one
+func (b *Bolt) UpdateOutboxMessage(ctx context.Context, msg *models.OutboxMessage) error {
+	doc, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal outbox message: %w", err)
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(outboxBucket)
+		if bucket.Get([]byte(msg.ID)) == nil {
+			return ErrOutboxMessageNotFound
+		}
+		return bucket.Put([]byte(msg.ID), doc)
+	})
+}
+
+// DeleteOutboxMessage removes the outbox message with the provided ID, if it is stored
+func (b *Bolt) DeleteOutboxMessage(ctx context.Context, id string) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(outboxBucket).Delete([]byte(id))
+	})
+}
+
+// GetPendingOutboxMessages returns up to limit outbox messages that are due to be delivered at the provided time, oldest first
+func (b *Bolt) GetPendingOutboxMessages(ctx context.Context, now time.Time, limit int) ([]*models.OutboxMessage, error) {
+	msgs := []*models.OutboxMessage{}
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(outboxBucket).ForEach(func(k, v []byte) error {
+			msg := &models.OutboxMessage{}
+			if err := json.Unmarshal(v, msg); err != nil {
+				return fmt.Errorf("failed to unmarshal outbox message '%s': %w", k, err)
+			}
+			if msg.IsPending(now) {
+				msgs = append(msgs, msg)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortOldestFirst(msgs)
+	return limitOutboxMessages(msgs, limit), nil
+}
+
+// AddDigestEntry stores the provided digest entry as a JSON document, keyed by its ID
+func (b *Bolt) AddDigestEntry(ctx context.Context, e *models.DigestEntry) error {
+	if e.ID == "" {
+		return ErrMissingDigestEntryID
+	}
+
+	doc, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to marshal digest entry: %w", err)
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(digestsBucket)
+		if bucket.Get([]byte(e.ID)) != nil {
+			return ErrAlreadyExists
+		}
+		return bucket.Put([]byte(e.ID), doc)
+	})
+}
+
+// GetDigestEntries returns all the digest entries of the provided route, oldest first
+func (b *Bolt) GetDigestEntries(ctx context.Context, route string) ([]*models.DigestEntry, error) {
+	entries := []*models.DigestEntry{}
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(digestsBucket).ForEach(func(k, v []byte) error {
+			e := &models.DigestEntry{}
+			if err := json.Unmarshal(v, e); err != nil {
+				return fmt.Errorf("failed to unmarshal digest entry '%s': %w", k, err)
+			}
+			if e.Route == route {
+				entries = append(entries, e)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortDigestEntries(entries)
+	return entries, nil
+}
+
+// DeleteDigestEntries removes the digest entries with the provided IDs, if they are stored
+func (b *Bolt) DeleteDigestEntries(ctx context.Context, ids []string) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(digestsBucket)
+		for _, id := range ids {
+			if err := bucket.Delete([]byte(id)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Close closes the underlying BoltDB file
+func (b *Bolt) Close(ctx context.Context) error {
+	return b.db.Close()
+}
@@ -0,0 +1,123 @@
+package store_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-feedback-api/config"
+	"github.com/ONSdigital/dp-feedback-api/store"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func testBoltConfig(t *testing.T) *config.Store {
+	return &config.Store{
+		Path:    filepath.Join(t.TempDir(), "feedback.db"),
+		Timeout: time.Second,
+	}
+}
+
+func TestBoltAddFeedback(t *testing.T) {
+	Convey("Given a bolt store backed by a new file", t, func() {
+		cfg := testBoltConfig(t)
+		b, err := store.NewBolt(ctx, cfg)
+		So(err, ShouldBeNil)
+		defer b.Close(ctx)
+
+		Convey("Then feedback, including positive votes, can be added", func() {
+			So(b.AddFeedback(ctx, testFeedback("id1", &pageNotUseful)), ShouldBeNil)
+			So(b.AddFeedback(ctx, testFeedback("id2", &pageUseful)), ShouldBeNil)
+
+			Convey("And adding feedback with an existing id fails", func() {
+				err := b.AddFeedback(ctx, testFeedback("id1", &pageUseful))
+				So(err, ShouldEqual, store.ErrAlreadyExists)
+			})
+		})
+
+		Convey("Then adding feedback without an id fails", func() {
+			err := b.AddFeedback(ctx, testFeedback("", &pageUseful))
+			So(err, ShouldEqual, store.ErrMissingID)
+		})
+	})
+
+	Convey("Given feedback added to a bolt store that is then closed", t, func() {
+		cfg := testBoltConfig(t)
+		b, err := store.NewBolt(ctx, cfg)
+		So(err, ShouldBeNil)
+		So(b.AddFeedback(ctx, testFeedback("id1", &pageNotUseful)), ShouldBeNil)
+		So(b.Close(ctx), ShouldBeNil)
+
+		Convey("When the same file is opened again", func() {
+			reopened, err := store.NewBolt(ctx, cfg)
+			So(err, ShouldBeNil)
+			defer reopened.Close(ctx)
+
+			Convey("Then the feedback is still stored", func() {
+				err := reopened.AddFeedback(ctx, testFeedback("id1", &pageNotUseful))
+				So(err, ShouldEqual, store.ErrAlreadyExists)
+			})
+		})
+	})
+
+	Convey("Given a path in a directory that does not exist", t, func() {
+		cfg := &config.Store{
+			Path:    filepath.Join(t.TempDir(), "missing", "feedback.db"),
+			Timeout: time.Second,
+		}
+
+		Convey("Then opening a bolt store fails", func() {
+			_, err := store.NewBolt(ctx, cfg)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestBoltGetFeedbackList(t *testing.T) {
+	Convey("Given a bolt store containing some feedback", t, func() {
+		b, err := store.NewBolt(ctx, testBoltConfig(t))
+		So(err, ShouldBeNil)
+		defer b.Close(ctx)
+
+		shouldListFeedback(b)
+	})
+}
+
+func TestBoltGetFeedback(t *testing.T) {
+	Convey("Given a bolt store containing some feedback", t, func() {
+		b, err := store.NewBolt(ctx, testBoltConfig(t))
+		So(err, ShouldBeNil)
+		defer b.Close(ctx)
+
+		shouldGetFeedback(b)
+	})
+}
+
+func TestBoltAddOccurrence(t *testing.T) {
+	Convey("Given a bolt store containing some feedback", t, func() {
+		b, err := store.NewBolt(ctx, testBoltConfig(t))
+		So(err, ShouldBeNil)
+		defer b.Close(ctx)
+
+		shouldAddOccurrences(b)
+	})
+}
+
+func TestBoltOutbox(t *testing.T) {
+	Convey("Given a bolt store containing some outbox messages", t, func() {
+		b, err := store.NewBolt(ctx, testBoltConfig(t))
+		So(err, ShouldBeNil)
+		defer b.Close(ctx)
+
+		shouldPersistOutbox(b)
+	})
+}
+
+func TestBoltDigests(t *testing.T) {
+	Convey("Given a bolt store containing some digest entries", t, func() {
+		b, err := store.NewBolt(ctx, testBoltConfig(t))
+		So(err, ShouldBeNil)
+		defer b.Close(ctx)
+
+		shouldPersistDigests(b)
+	})
+}
@@ -0,0 +1,186 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ONSdigital/dp-feedback-api/models"
+)
+
+// Memory is a FeedbackStore that keeps all the feedback in memory. It is intended for tests and local development,
+// as any stored feedback is lost when the service stops.
+type Memory struct {
+	mu       sync.RWMutex
+	feedback map[string]models.Feedback
+	outbox   map[string]models.OutboxMessage
+	digests  map[string]models.DigestEntry
+}
+
+// NewMemory returns a new, empty, in-memory store
+func NewMemory() *Memory {
+	return &Memory{
+		feedback: map[string]models.Feedback{},
+		outbox:   map[string]models.OutboxMessage{},
+		digests:  map[string]models.DigestEntry{},
+	}
+}
+
+// AddFeedback stores a copy of the provided feedback, keyed by its ID
+func (m *Memory) AddFeedback(ctx context.Context, f *models.Feedback) error {
+	if err := validateForStorage(f); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.feedback[f.ID]; ok {
+		return ErrAlreadyExists
+	}
+	m.feedback[f.ID] = *f
+	return nil
+}
+
+// GetFeedback returns a copy of the stored feedback with the provided ID
+func (m *Memory) GetFeedback(ctx context.Context, id string) (*models.Feedback, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	f, ok := m.feedback[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &f, nil
+}
+
+// AddOccurrence counts one more occurrence of the stored feedback with the provided ID, and returns a copy of the updated feedback
+func (m *Memory) AddOccurrence(ctx context.Context, id string) (*models.Feedback, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, ok := m.feedback[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	f.AddOccurrence()
+	m.feedback[id] = f
+	return &f, nil
+}
+
+// GetFeedbackList returns copies of all the stored feedback that matches the provided filter, newest first
+func (m *Memory) GetFeedbackList(ctx context.Context, filter *models.FeedbackFilter) ([]*models.Feedback, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	items := []*models.Feedback{}
+	for id := range m.feedback {
+		f := m.feedback[id]
+		if filter.Matches(&f) {
+			items = append(items, &f)
+		}
+	}
+	sortNewestFirst(items)
+	return items, nil
+}
+
+// AddOutboxMessage stores a copy of the provided outbox message, keyed by its ID
+func (m *Memory) AddOutboxMessage(ctx context.Context, msg *models.OutboxMessage) error {
+	if msg.ID == "" {
+		return ErrMissingOutboxMessageID
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.outbox[msg.ID]; ok {
+		return ErrAlreadyExists
+	}
+	m.outbox[msg.ID] = *msg
+	return nil
+}
+
+// UpdateOutboxMessage replaces the stored outbox message with the same ID by a copy of the provided one
+func (m *Memory) UpdateOutboxMessage(ctx context.Context, msg *models.OutboxMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.outbox[msg.ID]; !ok {
+		return ErrOutboxMessageNotFound
+	}
+	m.outbox[msg.ID] = *msg
+	return nil
+}
+
+// DeleteOutboxMessage removes the outbox message with the provided ID, if it is stored
+func (m *Memory) DeleteOutboxMessage(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.outbox, id)
+	return nil
+}
+
+// GetPendingOutboxMessages returns copies of up to limit outbox messages that are due to be delivered at the provided time, oldest first
+func (m *Memory) GetPendingOutboxMessages(ctx context.Context, now time.Time, limit int) ([]*models.OutboxMessage, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	msgs := []*models.OutboxMessage{}
+	for id := range m.outbox {
+		msg := m.outbox[id]
+		if msg.IsPending(now) {
+			msgs = append(msgs, &msg)
+		}
+	}
+	sortOldestFirst(msgs)
+	return limitOutboxMessages(msgs, limit), nil
+}
+
+// AddDigestEntry stores a copy of the provided digest entry, keyed by its ID
+func (m *Memory) AddDigestEntry(ctx context.Context, e *models.DigestEntry) error {
+	if e.ID == "" {
+		return ErrMissingDigestEntryID
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.digests[e.ID]; ok {
+		return ErrAlreadyExists
+	}
+	m.digests[e.ID] = *e
+	return nil
+}
+
+// GetDigestEntries returns copies of all the digest entries of the provided route, oldest first
+func (m *Memory) GetDigestEntries(ctx context.Context, route string) ([]*models.DigestEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entries := []*models.DigestEntry{}
+	for id := range m.digests {
+		e := m.digests[id]
+		if e.Route == route {
+			entries = append(entries, &e)
+		}
+	}
+	sortDigestEntries(entries)
+	return entries, nil
+}
+
+// DeleteDigestEntries removes the digest entries with the provided IDs, if they are stored
+func (m *Memory) DeleteDigestEntries(ctx context.Context, ids []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, id := range ids {
+		delete(m.digests, id)
+	}
+	return nil
+}
+
+// Close is a no-op for the in-memory store
+func (m *Memory) Close(ctx context.Context) error {
+	return nil
+}
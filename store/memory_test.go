@@ -0,0 +1,92 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-feedback-api/models"
+	"github.com/ONSdigital/dp-feedback-api/store"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var (
+	ctx           = context.Background()
+	testTime      = time.Date(2024, 3, 15, 10, 30, 0, 0, time.UTC)
+	pageUseful    = true
+	pageNotUseful = false
+)
+
+func testFeedback(id string, isPageUseful *bool) *models.Feedback {
+	receivedAt := testTime
+	return &models.Feedback{
+		ID:                id,
+		ReceivedAt:        &receivedAt,
+		IsPageUseful:      isPageUseful,
+		IsGeneralFeedback: &pageNotUseful,
+		OnsURL:            "https://www.ons.gov.uk/economy",
+		Feedback:          "could not find the data I was looking for",
+	}
+}
+
+func TestMemoryAddFeedback(t *testing.T) {
+	Convey("Given an empty in-memory store", t, func() {
+		m := store.NewMemory()
+
+		Convey("Then feedback, including positive votes, can be added", func() {
+			So(m.AddFeedback(ctx, testFeedback("id1", &pageNotUseful)), ShouldBeNil)
+			So(m.AddFeedback(ctx, testFeedback("id2", &pageUseful)), ShouldBeNil)
+
+			Convey("And adding feedback with an existing id fails", func() {
+				err := m.AddFeedback(ctx, testFeedback("id1", &pageUseful))
+				So(err, ShouldEqual, store.ErrAlreadyExists)
+			})
+		})
+
+		Convey("Then adding feedback without an id fails", func() {
+			err := m.AddFeedback(ctx, testFeedback("", &pageUseful))
+			So(err, ShouldEqual, store.ErrMissingID)
+		})
+
+		Convey("Then adding feedback without a received timestamp fails", func() {
+			f := testFeedback("id1", &pageUseful)
+			f.ReceivedAt = nil
+			err := m.AddFeedback(ctx, f)
+			So(err, ShouldEqual, store.ErrMissingReceivedAt)
+		})
+
+		Convey("Then closing the store succeeds", func() {
+			So(m.Close(ctx), ShouldBeNil)
+		})
+	})
+}
+
+func TestMemoryGetFeedbackList(t *testing.T) {
+	Convey("Given an in-memory store containing some feedback", t, func() {
+		shouldListFeedback(store.NewMemory())
+	})
+}
+
+func TestMemoryGetFeedback(t *testing.T) {
+	Convey("Given an in-memory store containing some feedback", t, func() {
+		shouldGetFeedback(store.NewMemory())
+	})
+}
+
+func TestMemoryAddOccurrence(t *testing.T) {
+	Convey("Given an in-memory store containing some feedback", t, func() {
+		shouldAddOccurrences(store.NewMemory())
+	})
+}
+
+func TestMemoryOutbox(t *testing.T) {
+	Convey("Given an in-memory store containing some outbox messages", t, func() {
+		shouldPersistOutbox(store.NewMemory())
+	})
+}
+
+func TestMemoryDigests(t *testing.T) {
+	Convey("Given an in-memory store containing some digest entries", t, func() {
+		shouldPersistDigests(store.NewMemory())
+	})
+}
@@ -0,0 +1,78 @@
+// Package store provides the implementations of the feedback store used by the service:
+// an embedded on-disk store backed by BoltDB, and an in-memory store for tests.
+// Both also persist the outbox of emails waiting to be delivered, and the feedback waiting to be included in digest emails.
+package store
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/ONSdigital/dp-feedback-api/models"
+)
+
+var (
+	// ErrNotFound is returned when the requested feedback is not stored
+	ErrNotFound = errors.New("feedback not found")
+	// ErrAlreadyExists is returned when trying to add feedback with an ID that is already stored
+	ErrAlreadyExists = errors.New("feedback with the same id already exists")
+	// ErrMissingID is returned when trying to add feedback that has not been assigned an ID
+	ErrMissingID = errors.New("feedback id is required")
+	// ErrMissingReceivedAt is returned when trying to add feedback that has not been assigned a received timestamp
+	ErrMissingReceivedAt = errors.New("feedback received_at timestamp is required")
+	// ErrOutboxMessageNotFound is returned when trying to update an outbox message that is not stored
+	ErrOutboxMessageNotFound = errors.New("outbox message not found")
+	// ErrMissingOutboxMessageID is returned when trying to store an outbox message that has not been assigned an ID
+	ErrMissingOutboxMessageID = errors.New("outbox message id is required")
+	// ErrMissingDigestEntryID is returned when trying to store a digest entry that has not been assigned an ID
+	ErrMissingDigestEntryID = errors.New("digest entry id is required")
+)
+
+// validateForStorage checks that the feedback has been assigned the fields required to store it
+func validateForStorage(f *models.Feedback) error {
+	if f.ID == "" {
+		return ErrMissingID
+	}
+	if f.ReceivedAt == nil {
+		return ErrMissingReceivedAt
+	}
+	return nil
+}
+
+// sortNewestFirst sorts the provided feedback by received timestamp, newest first, using the ID to break ties
+func sortNewestFirst(items []*models.Feedback) {
+	sort.SliceStable(items, func(i, j int) bool {
+		ti, tj := items[i].ReceivedAt, items[j].ReceivedAt
+		if ti.Equal(*tj) {
+			return items[i].ID < items[j].ID
+		}
+		return ti.After(*tj)
+	})
+}
+
+// sortOldestFirst sorts the provided outbox messages by creation timestamp, oldest first, using the ID to break ties
+func sortOldestFirst(msgs []*models.OutboxMessage) {
+	sort.SliceStable(msgs, func(i, j int) bool {
+		if msgs[i].CreatedAt.Equal(msgs[j].CreatedAt) {
+			return msgs[i].ID < msgs[j].ID
+		}
+		return msgs[i].CreatedAt.Before(msgs[j].CreatedAt)
+	})
+}
+
+// sortDigestEntries sorts the provided digest entries by the time they were added, oldest first, using the ID to break ties
+func sortDigestEntries(entries []*models.DigestEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].AddedAt.Equal(entries[j].AddedAt) {
+			return entries[i].ID < entries[j].ID
+		}
+		return entries[i].AddedAt.Before(entries[j].AddedAt)
+	})
+}
+
+// limitOutboxMessages returns the first limit messages, or all of them if limit is not positive
+func limitOutboxMessages(msgs []*models.OutboxMessage, limit int) []*models.OutboxMessage {
+	if limit > 0 && len(msgs) > limit {
+		return msgs[:limit]
+	}
+	return msgs
+}
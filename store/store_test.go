@@ -0,0 +1,251 @@
+package store_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/ONSdigital/dp-feedback-api/models"
+	"github.com/ONSdigital/dp-feedback-api/store"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// feedbackStore is the subset of store methods that all the store implementations must satisfy
+type feedbackStore interface {
+	AddFeedback(ctx context.Context, f *models.Feedback) error
+	GetFeedback(ctx context.Context, id string) (*models.Feedback, error)
+	AddOccurrence(ctx context.Context, id string) (*models.Feedback, error)
+	GetFeedbackList(ctx context.Context, filter *models.FeedbackFilter) ([]*models.Feedback, error)
+}
+
+// outboxStore is the subset of store methods that all the store implementations must satisfy to persist the outbox
+type outboxStore interface {
+	AddOutboxMessage(ctx context.Context, msg *models.OutboxMessage) error
+	UpdateOutboxMessage(ctx context.Context, msg *models.OutboxMessage) error
+	DeleteOutboxMessage(ctx context.Context, id string) error
+	GetPendingOutboxMessages(ctx context.Context, now time.Time, limit int) ([]*models.OutboxMessage, error)
+}
+
+// digestStore is the subset of store methods that all the store implementations must satisfy to persist the digest entries
+type digestStore interface {
+	AddDigestEntry(ctx context.Context, e *models.DigestEntry) error
+	GetDigestEntries(ctx context.Context, route string) ([]*models.DigestEntry, error)
+	DeleteDigestEntries(ctx context.Context, ids []string) error
+}
+
+func testOutboxMessage(id string, createdAt time.Time) *models.OutboxMessage {
+	return &models.OutboxMessage{
+		ID:          id,
+		From:        "sender@mail.com",
+		To:          []string{"receiver@mail.com"},
+		Msg:         []byte("Subject: Feedback received\r\n\r\nDescription: test\r\n"),
+		CreatedAt:   createdAt,
+		NextAttempt: createdAt,
+	}
+}
+
+// shouldPersistOutbox validates the outbox behaviour of the provided, empty, store
+func shouldPersistOutbox(s outboxStore) {
+	first := testOutboxMessage("first", testTime)
+	second := testOutboxMessage("second", testTime.Add(time.Minute))
+	later := testOutboxMessage("later", testTime)
+	later.NextAttempt = testTime.Add(time.Hour)
+	dead := testOutboxMessage("dead", testTime)
+	dead.DeadLettered = true
+	for _, msg := range []*models.OutboxMessage{second, later, first, dead} {
+		So(s.AddOutboxMessage(ctx, msg), ShouldBeNil)
+	}
+
+	Convey("Then the messages that are due are returned oldest first", func() {
+		msgs, err := s.GetPendingOutboxMessages(ctx, testTime.Add(time.Minute), 0)
+		So(err, ShouldBeNil)
+		So(msgs, ShouldResemble, []*models.OutboxMessage{first, second})
+
+		msgs, err = s.GetPendingOutboxMessages(ctx, testTime.Add(time.Minute), 1)
+		So(err, ShouldBeNil)
+		So(msgs, ShouldResemble, []*models.OutboxMessage{first})
+	})
+
+	Convey("Then adding a message with an existing id fails", func() {
+		So(s.AddOutboxMessage(ctx, testOutboxMessage("first", testTime)), ShouldEqual, store.ErrAlreadyExists)
+	})
+
+	Convey("Then adding a message without an id fails", func() {
+		So(s.AddOutboxMessage(ctx, testOutboxMessage("", testTime)), ShouldEqual, store.ErrMissingOutboxMessageID)
+	})
+
+	Convey("Then an updated message is only returned once it is due", func() {
+		first.Attempts = 1
+		first.LastError = "smtp error"
+		first.NextAttempt = testTime.Add(2 * time.Hour)
+		So(s.UpdateOutboxMessage(ctx, first), ShouldBeNil)
+
+		msgs, err := s.GetPendingOutboxMessages(ctx, testTime.Add(time.Minute), 0)
+		So(err, ShouldBeNil)
+		So(msgs, ShouldResemble, []*models.OutboxMessage{second})
+
+		msgs, err = s.GetPendingOutboxMessages(ctx, testTime.Add(2*time.Hour), 0)
+		So(err, ShouldBeNil)
+		So(msgs, ShouldResemble, []*models.OutboxMessage{first, later, second})
+	})
+
+	Convey("Then updating a message that is not stored fails", func() {
+		So(s.UpdateOutboxMessage(ctx, testOutboxMessage("unknown", testTime)), ShouldEqual, store.ErrOutboxMessageNotFound)
+	})
+
+	Convey("Then a deleted message is no longer returned", func() {
+		So(s.DeleteOutboxMessage(ctx, "first"), ShouldBeNil)
+		So(s.DeleteOutboxMessage(ctx, "unknown"), ShouldBeNil)
+
+		msgs, err := s.GetPendingOutboxMessages(ctx, testTime.Add(time.Minute), 0)
+		So(err, ShouldBeNil)
+		So(msgs, ShouldResemble, []*models.OutboxMessage{second})
+	})
+}
+
+func testDigestEntry(id, route string, addedAt time.Time) *models.DigestEntry {
+	return &models.DigestEntry{
+		ID:       id,
+		Route:    route,
+		Feedback: testFeedback(id, &pageNotUseful),
+		AddedAt:  addedAt,
+	}
+}
+
+// shouldPersistDigests validates the digest entries behaviour of the provided, empty, store
+func shouldPersistDigests(s digestStore) {
+	first := testDigestEntry("first", "census", testTime)
+	second := testDigestEntry("second", "census", testTime.Add(time.Minute))
+	other := testDigestEntry("other", "economy", testTime)
+	for _, e := range []*models.DigestEntry{second, other, first} {
+		So(s.AddDigestEntry(ctx, e), ShouldBeNil)
+	}
+
+	Convey("Then the entries of a route are returned oldest first", func() {
+		entries, err := s.GetDigestEntries(ctx, "census")
+		So(err, ShouldBeNil)
+		So(entries, ShouldResemble, []*models.DigestEntry{first, second})
+
+		entries, err = s.GetDigestEntries(ctx, "unknown")
+		So(err, ShouldBeNil)
+		So(entries, ShouldBeEmpty)
+	})
+
+	Convey("Then adding an entry with an existing id fails", func() {
+		So(s.AddDigestEntry(ctx, testDigestEntry("first", "census", testTime)), ShouldEqual, store.ErrAlreadyExists)
+	})
+
+	Convey("Then adding an entry without an id fails", func() {
+		So(s.AddDigestEntry(ctx, testDigestEntry("", "census", testTime)), ShouldEqual, store.ErrMissingDigestEntryID)
+	})
+
+	Convey("Then deleted entries are no longer returned", func() {
+		So(s.DeleteDigestEntries(ctx, []string{"first", "other", "unknown"}), ShouldBeNil)
+
+		entries, err := s.GetDigestEntries(ctx, "census")
+		So(err, ShouldBeNil)
+		So(entries, ShouldResemble, []*models.DigestEntry{second})
+
+		entries, err = s.GetDigestEntries(ctx, "economy")
+		So(err, ShouldBeNil)
+		So(entries, ShouldBeEmpty)
+	})
+}
+
+// shouldGetFeedback validates the behaviour of getting single feedback items from the provided, empty, store
+func shouldGetFeedback(s feedbackStore) {
+	f := testFeedback("id1", &pageNotUseful)
+	So(s.AddFeedback(ctx, f), ShouldBeNil)
+
+	Convey("Then the stored feedback can be retrieved by its id", func() {
+		stored, err := s.GetFeedback(ctx, "id1")
+		So(err, ShouldBeNil)
+		So(stored, ShouldResemble, f)
+	})
+
+	Convey("Then getting feedback with an unknown id fails with ErrNotFound", func() {
+		_, err := s.GetFeedback(ctx, "unknown")
+		So(err, ShouldEqual, store.ErrNotFound)
+	})
+}
+
+// shouldAddOccurrences validates the counting of the occurrences of the feedback in the provided, empty, store
+func shouldAddOccurrences(s feedbackStore) {
+	f := testFeedback("id1", &pageNotUseful)
+	So(s.AddFeedback(ctx, f), ShouldBeNil)
+
+	Convey("Then the first near-duplicate counts as the second occurrence of the feedback", func() {
+		updated, err := s.AddOccurrence(ctx, "id1")
+		So(err, ShouldBeNil)
+		So(updated.Occurrences, ShouldEqual, 2)
+
+		Convey("And every further near-duplicate counts as one more occurrence, which is stored", func() {
+			updated, err := s.AddOccurrence(ctx, "id1")
+			So(err, ShouldBeNil)
+			So(updated.Occurrences, ShouldEqual, 3)
+
+			stored, err := s.GetFeedback(ctx, "id1")
+			So(err, ShouldBeNil)
+			f.Occurrences = 3
+			So(stored, ShouldResemble, f)
+		})
+	})
+
+	Convey("Then counting an occurrence of feedback with an unknown id fails with ErrNotFound", func() {
+		_, err := s.AddOccurrence(ctx, "unknown")
+		So(err, ShouldEqual, store.ErrNotFound)
+	})
+}
+
+// shouldListFeedback validates the listing behaviour of the provided, empty, store
+func shouldListFeedback(s feedbackStore) {
+	older := testFeedback("older", &pageNotUseful)
+	newer := testFeedback("newer", &pageUseful)
+	newerTime := testTime.Add(time.Hour)
+	newer.ReceivedAt = &newerTime
+	newer.OnsURL = "https://www.ons.gov.uk/census"
+	So(s.AddFeedback(ctx, older), ShouldBeNil)
+	So(s.AddFeedback(ctx, newer), ShouldBeNil)
+
+	Convey("Then all the feedback is listed newest first when no filter is provided", func() {
+		items, err := s.GetFeedbackList(ctx, nil)
+		So(err, ShouldBeNil)
+		So(items, ShouldResemble, []*models.Feedback{newer, older})
+	})
+
+	Convey("Then only the matching feedback is listed when a filter is provided", func() {
+		items, err := s.GetFeedbackList(ctx, &models.FeedbackFilter{IsPageUseful: &pageNotUseful})
+		So(err, ShouldBeNil)
+		So(items, ShouldResemble, []*models.Feedback{older})
+
+		items, err = s.GetFeedbackList(ctx, &models.FeedbackFilter{OnsURLPrefix: "www.ons.gov.uk/census"})
+		So(err, ShouldBeNil)
+		So(items, ShouldResemble, []*models.Feedback{newer})
+
+		items, err = s.GetFeedbackList(ctx, &models.FeedbackFilter{ReceivedBefore: &newerTime})
+		So(err, ShouldBeNil)
+		So(items, ShouldResemble, []*models.Feedback{older})
+	})
+
+	Convey("Then only the feedback received within the time bounds of the filter is listed, including the lower bound and excluding the upper bound", func() {
+		newest := testFeedback("newest", &pageNotUseful)
+		newestTime := testTime.Add(2 * time.Hour)
+		newest.ReceivedAt = &newestTime
+		So(s.AddFeedback(ctx, newest), ShouldBeNil)
+
+		items, err := s.GetFeedbackList(ctx, &models.FeedbackFilter{ReceivedAfter: &newerTime})
+		So(err, ShouldBeNil)
+		So(items, ShouldResemble, []*models.Feedback{newest, newer})
+
+		// the bounds can be in any time zone
+		after, before := testTime.In(time.FixedZone("", -3600)), newestTime.In(time.FixedZone("", 3600))
+		items, err = s.GetFeedbackList(ctx, &models.FeedbackFilter{ReceivedAfter: &after, ReceivedBefore: &before})
+		So(err, ShouldBeNil)
+		So(items, ShouldResemble, []*models.Feedback{newer, older})
+	})
+
+	Convey("Then an empty list is returned when no feedback matches the filter", func() {
+		items, err := s.GetFeedbackList(ctx, &models.FeedbackFilter{OnsURLPrefix: "https://www.ons.gov.uk/releases"})
+		So(err, ShouldBeNil)
+		So(items, ShouldBeEmpty)
+	})
+}
@@ -0,0 +1,245 @@
+// Package webhook provides a notifier that posts feedback to a generic HTTP webhook,
+// so that it can be pushed into team chat and ticketing tools.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ONSdigital/dp-feedback-api/config"
+	"github.com/ONSdigital/dp-feedback-api/models"
+	"github.com/ONSdigital/dp-feedback-api/worker"
+	"github.com/ONSdigital/log.go/v2/log"
+	"github.com/gofrs/uuid"
+)
+
+// Headers sent with every webhook request
+const (
+	// EventHeader contains the event of the payload
+	EventHeader = "X-Feedback-Event"
+	// DeliveryHeader contains a unique identifier of the delivery, which is the same for all its attempts
+	DeliveryHeader = "X-Feedback-Delivery"
+	// SignatureHeader contains the HMAC-SHA256 of the request body, keyed with the webhook secret, as "sha256=<hex>".
+	// It is only sent if a secret is configured.
+	SignatureHeader = "X-Feedback-Signature-256"
+)
+
+// EventFeedbackReceived is the event posted when feedback is received
+const EventFeedbackReceived = "feedback_received"
+
+var (
+	// ErrQueueFull is returned when feedback is notified while the queue of pending deliveries is full
+	ErrQueueFull = errors.New("webhook queue is full")
+	// ErrNotifierClosed is returned when feedback is notified after the notifier has been closed
+	ErrNotifierClosed = errors.New("webhook notifier is closed")
+
+	errStopped = errors.New("webhook worker stopped before retrying")
+)
+
+// NewID generates a new unique identifier for a delivery
+var NewID = func() string {
+	return uuid.Must(uuid.NewV4()).String()
+}
+
+// Payload is the JSON body posted to the webhook
+type Payload struct {
+	Event    string           `json:"event"`
+	Feedback *models.Feedback `json:"feedback"`
+}
+
+// delivery is the feedback waiting to be posted to the webhook, with the id sent in all the attempts to post it
+type delivery struct {
+	id       string
+	feedback *models.Feedback
+}
+
+// Notifier posts feedback to an HTTP webhook from a background worker, so that requests are not held up by slow webhooks.
+// Failed deliveries are retried with exponential backoff, up to the configured retries.
+// Deliveries are best-effort: unlike the emails, which are persisted in the outbox, pending deliveries are kept in memory only,
+// so they are lost if the service stops without closing the notifier, and the ones that cannot be delivered are logged and dropped.
+type Notifier struct {
+	cfg    *config.Webhook
+	client *http.Client
+
+	mu     sync.RWMutex
+	closed bool
+	queue  chan *delivery
+	worker *worker.Worker
+}
+
+// New returns a new Notifier that posts feedback to the configured webhook
+func New(cfg *config.Webhook) (*Notifier, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("webhook url is required")
+	}
+	return &Notifier{
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		queue:  make(chan *delivery, cfg.QueueSize),
+		worker: worker.New(),
+	}, nil
+}
+
+// Notify queues the provided feedback to be posted to the webhook by the worker, without blocking
+func (n *Notifier) Notify(ctx context.Context, f *models.Feedback) error {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	if n.closed {
+		return ErrNotifierClosed
+	}
+	select {
+	case n.queue <- &delivery{id: NewID(), feedback: f}:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Start runs the worker in a new go-routine, which posts the queued feedback until the notifier is closed
+func (n *Notifier) Start(ctx context.Context) {
+	n.worker.Start(func(stop <-chan struct{}) {
+		n.run(ctx, stop)
+	})
+}
+
+func (n *Notifier) run(ctx context.Context, stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case d := <-n.queue:
+			n.deliver(ctx, stop, d)
+		}
+	}
+}
+
+// DeliverQueued posts all the queued feedback to the webhook, until the queue is empty or the context is done
+func (n *Notifier) DeliverQueued(ctx context.Context) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		select {
+		case d := <-n.queue:
+			n.deliver(ctx, nil, d)
+		default:
+			return nil
+		}
+	}
+}
+
+// deliver posts the feedback of the provided delivery to the webhook, and logs the result, as there is nobody to report it to.
+// If the provided stop channel is closed while waiting to retry, the delivery is queued again, so that it is posted when the notifier is closed.
+func (n *Notifier) deliver(ctx context.Context, stop <-chan struct{}, d *delivery) {
+	logData := log.Data{"feedback_id": d.feedback.ID, "delivery_id": d.id}
+	err := n.post(ctx, stop, d)
+	switch {
+	case errors.Is(err, errStopped):
+		select {
+		case n.queue <- d:
+			log.Info(ctx, "webhook worker stopped, feedback queued again to be delivered on close", logData)
+		default:
+			log.Error(ctx, "failed to deliver feedback to webhook", ErrQueueFull, logData)
+		}
+	case err != nil:
+		log.Error(ctx, "failed to deliver feedback to webhook", err, logData)
+	default:
+		log.Info(ctx, "feedback delivered to webhook", logData)
+	}
+}
+
+// Post posts the provided feedback to the webhook, retrying network errors, 429 and 5xx responses with exponential backoff.
+// Any other response is not retried.
+func (n *Notifier) Post(ctx context.Context, f *models.Feedback) error {
+	return n.post(ctx, nil, &delivery{id: NewID(), feedback: f})
+}
+
+// post posts the feedback of the provided delivery to the webhook as Post does, but stops waiting to retry if the provided stop channel is closed
+func (n *Notifier) post(ctx context.Context, stop <-chan struct{}, d *delivery) error {
+	body, err := json.Marshal(&Payload{Event: EventFeedbackReceived, Feedback: d.feedback})
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+
+	backoff := n.cfg.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := n.send(ctx, d.id, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= n.cfg.MaxRetries {
+			return fmt.Errorf("webhook delivery failed after %d attempt(s): %w", attempt+1, err)
+		}
+
+		log.Warn(ctx, "failed to deliver feedback to webhook, it will be retried", log.FormatErrors([]error{err}),
+			log.Data{"feedback_id": d.feedback.ID, "delivery_id": d.id, "attempt": attempt + 1, "backoff": backoff.String()})
+		select {
+		case <-time.After(backoff):
+		case <-stop:
+			return errStopped
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+// send sends a single request to the webhook, returning whether it can be retried if it fails
+func (n *Notifier) send(ctx context.Context, deliveryID string, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "dp-feedback-api")
+	req.Header.Set(EventHeader, EventFeedbackReceived)
+	req.Header.Set(DeliveryHeader, deliveryID)
+	if n.cfg.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(n.cfg.Secret, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	// drain the body so that the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("webhook responded with unexpected status %d", resp.StatusCode)
+}
+
+// Sign returns the value of the signature header for the provided body, which receivers can verify with the shared secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Close stops accepting feedback and stops the worker, and then posts any queued feedback, until the queue is empty or the context is done
+func (n *Notifier) Close(ctx context.Context) error {
+	n.mu.Lock()
+	n.closed = true
+	n.mu.Unlock()
+
+	// wait for the worker to finish any delivery in progress
+	if err := n.worker.Stop(ctx); err != nil {
+		return err
+	}
+	return n.DeliverQueued(ctx)
+}
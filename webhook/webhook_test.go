@@ -0,0 +1,266 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-feedback-api/config"
+	"github.com/ONSdigital/dp-feedback-api/models"
+	"github.com/ONSdigital/dp-feedback-api/webhook"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var ctx = context.Background()
+
+// receiver is a webhook receiver that records the requests it receives,
+// and responds with the provided statuses in order, and then with 204 No Content
+type receiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	statuses []int
+	requests []*request
+	received chan struct{}
+}
+
+type request struct {
+	header http.Header
+	body   []byte
+}
+
+func newReceiver(statuses ...int) *receiver {
+	r := &receiver{statuses: statuses, received: make(chan struct{}, 10)}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.mu.Lock()
+		r.requests = append(r.requests, &request{header: req.Header.Clone(), body: body})
+		status := http.StatusNoContent
+		if len(r.statuses) > 0 {
+			status, r.statuses = r.statuses[0], r.statuses[1:]
+		}
+		r.mu.Unlock()
+		w.WriteHeader(status)
+		r.received <- struct{}{}
+	}))
+	return r
+}
+
+func (r *receiver) Requests() []*request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.requests
+}
+
+func testConfig(url string) *config.Webhook {
+	return &config.Webhook{
+		URL:          url,
+		Secret:       "shared-secret",
+		Timeout:      time.Second,
+		MaxRetries:   2,
+		RetryBackoff: time.Millisecond,
+		QueueSize:    2,
+	}
+}
+
+func testFeedback() *models.Feedback {
+	isPageUseful, isGeneralFeedback := false, false
+	return &models.Feedback{
+		ID:                "test-id",
+		IsPageUseful:      &isPageUseful,
+		IsGeneralFeedback: &isGeneralFeedback,
+		OnsURL:            "https://testhost/sub/path",
+		Feedback:          "broken link",
+	}
+}
+
+func TestNew(t *testing.T) {
+	Convey("A webhook notifier cannot be created without a url", t, func() {
+		n, err := webhook.New(&config.Webhook{})
+		So(err, ShouldNotBeNil)
+		So(n, ShouldBeNil)
+	})
+}
+
+func TestPost(t *testing.T) {
+	newID := webhook.NewID
+	defer func() { webhook.NewID = newID }()
+	webhook.NewID = func() string { return "delivery-id" }
+
+	Convey("Given a webhook notifier for a receiver that accepts the requests", t, func() {
+		r := newReceiver()
+		defer r.Close()
+		n, err := webhook.New(testConfig(r.URL))
+		So(err, ShouldBeNil)
+
+		Convey("When feedback is posted", func() {
+			So(n.Post(ctx, testFeedback()), ShouldBeNil)
+
+			Convey("Then the receiver gets the feedback as a JSON payload", func() {
+				So(r.Requests(), ShouldHaveLength, 1)
+				req := r.Requests()[0]
+				So(req.header.Get("Content-Type"), ShouldEqual, "application/json")
+				So(req.header.Get(webhook.EventHeader), ShouldEqual, webhook.EventFeedbackReceived)
+				So(req.header.Get(webhook.DeliveryHeader), ShouldEqual, "delivery-id")
+
+				payload := &webhook.Payload{}
+				So(json.Unmarshal(req.body, payload), ShouldBeNil)
+				So(payload, ShouldResemble, &webhook.Payload{Event: webhook.EventFeedbackReceived, Feedback: testFeedback()})
+			})
+
+			Convey("Then the payload is signed with the shared secret", func() {
+				req := r.Requests()[0]
+				So(req.header.Get(webhook.SignatureHeader), ShouldStartWith, "sha256=")
+				So(req.header.Get(webhook.SignatureHeader), ShouldEqual, webhook.Sign("shared-secret", req.body))
+				So(req.header.Get(webhook.SignatureHeader), ShouldNotEqual, webhook.Sign("other-secret", req.body))
+			})
+		})
+	})
+
+	Convey("Given a webhook notifier without a secret", t, func() {
+		r := newReceiver()
+		defer r.Close()
+		cfg := testConfig(r.URL)
+		cfg.Secret = ""
+		n, err := webhook.New(cfg)
+		So(err, ShouldBeNil)
+
+		Convey("Then the feedback is posted without a signature", func() {
+			So(n.Post(ctx, testFeedback()), ShouldBeNil)
+			So(r.Requests()[0].header.Get(webhook.SignatureHeader), ShouldBeEmpty)
+		})
+	})
+
+	Convey("Given a webhook notifier for a receiver that is temporarily unavailable", t, func() {
+		r := newReceiver(http.StatusServiceUnavailable, http.StatusTooManyRequests)
+		defer r.Close()
+		n, err := webhook.New(testConfig(r.URL))
+		So(err, ShouldBeNil)
+
+		Convey("Then the feedback is posted after retrying, with the same delivery id", func() {
+			So(n.Post(ctx, testFeedback()), ShouldBeNil)
+			So(r.Requests(), ShouldHaveLength, 3)
+			So(r.Requests()[2].header.Get(webhook.DeliveryHeader), ShouldEqual, "delivery-id")
+		})
+	})
+
+	Convey("Given a webhook notifier for a receiver that keeps failing", t, func() {
+		r := newReceiver(http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError)
+		defer r.Close()
+		n, err := webhook.New(testConfig(r.URL))
+		So(err, ShouldBeNil)
+
+		Convey("Then posting the feedback fails once the retries are exhausted", func() {
+			err := n.Post(ctx, testFeedback())
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "after 3 attempt(s)")
+			So(r.Requests(), ShouldHaveLength, 3)
+		})
+	})
+
+	Convey("Given a webhook notifier for a receiver that rejects the requests", t, func() {
+		r := newReceiver(http.StatusBadRequest)
+		defer r.Close()
+		n, err := webhook.New(testConfig(r.URL))
+		So(err, ShouldBeNil)
+
+		Convey("Then posting the feedback fails without retrying", func() {
+			So(n.Post(ctx, testFeedback()), ShouldNotBeNil)
+			So(r.Requests(), ShouldHaveLength, 1)
+		})
+	})
+
+	Convey("Given a webhook notifier for a receiver that is too slow to respond", t, func() {
+		release := make(chan struct{})
+		slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			<-release
+		}))
+		defer slow.Close()
+		defer close(release)
+		cfg := testConfig(slow.URL)
+		cfg.Timeout = 10 * time.Millisecond
+		cfg.MaxRetries = 0
+		n, err := webhook.New(cfg)
+		So(err, ShouldBeNil)
+
+		Convey("Then posting the feedback fails once the timeout expires", func() {
+			err := n.Post(ctx, testFeedback())
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "webhook request failed")
+		})
+	})
+}
+
+func TestNotify(t *testing.T) {
+	Convey("Given a started webhook notifier", t, func() {
+		r := newReceiver()
+		defer r.Close()
+		n, err := webhook.New(testConfig(r.URL))
+		So(err, ShouldBeNil)
+		n.Start(ctx)
+
+		Convey("When it is notified of feedback", func() {
+			So(n.Notify(ctx, testFeedback()), ShouldBeNil)
+
+			Convey("Then the worker posts it to the webhook in the background", func() {
+				select {
+				case <-r.received:
+				case <-time.After(5 * time.Second):
+					So("feedback not posted", ShouldBeEmpty)
+				}
+				So(n.Close(ctx), ShouldBeNil)
+				So(r.Requests(), ShouldHaveLength, 1)
+			})
+		})
+	})
+
+	Convey("Given a started webhook notifier waiting to retry a delivery to a receiver that is temporarily unavailable", t, func() {
+		r := newReceiver(http.StatusServiceUnavailable)
+		defer r.Close()
+		cfg := testConfig(r.URL)
+		cfg.RetryBackoff = time.Hour
+		n, err := webhook.New(cfg)
+		So(err, ShouldBeNil)
+		n.Start(ctx)
+		So(n.Notify(ctx, testFeedback()), ShouldBeNil)
+		<-r.received
+
+		Convey("When it is closed", func() {
+			closeCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			defer cancel()
+			err := n.Close(closeCtx)
+
+			Convey("Then it stops waiting to retry, and posts the feedback again with the same delivery id before the context is done", func() {
+				So(err, ShouldBeNil)
+				So(r.Requests(), ShouldHaveLength, 2)
+				So(r.Requests()[1].header.Get(webhook.DeliveryHeader), ShouldEqual, r.Requests()[0].header.Get(webhook.DeliveryHeader))
+			})
+		})
+	})
+
+	Convey("Given a webhook notifier that was not started", t, func() {
+		r := newReceiver()
+		defer r.Close()
+		n, err := webhook.New(testConfig(r.URL))
+		So(err, ShouldBeNil)
+
+		Convey("When it is notified of more feedback than its queue can hold", func() {
+			So(n.Notify(ctx, testFeedback()), ShouldBeNil)
+			So(n.Notify(ctx, testFeedback()), ShouldBeNil)
+
+			Convey("Then the feedback that does not fit is rejected", func() {
+				So(n.Notify(ctx, testFeedback()), ShouldEqual, webhook.ErrQueueFull)
+			})
+
+			Convey("Then closing it posts the queued feedback, and no more feedback is accepted", func() {
+				So(n.Close(ctx), ShouldBeNil)
+				So(r.Requests(), ShouldHaveLength, 2)
+				So(n.Notify(ctx, testFeedback()), ShouldEqual, webhook.ErrNotifierClosed)
+			})
+		})
+	})
+}
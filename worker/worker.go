@@ -0,0 +1,57 @@
+// Package worker provides the lifecycle shared by the background workers of the service, e.g. the outbox,
+// the digest scheduler and the webhook notifier, which run in a go-routine from when they are started until they are stopped
+package worker
+
+import (
+	"context"
+	"sync"
+)
+
+// Worker runs a function in a go-routine from when it is started until it is stopped.
+// It is only started once, and it cannot be started once it has been stopped.
+type Worker struct {
+	stop      chan struct{}
+	done      chan struct{}
+	started   bool
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+// New returns a new Worker that has not been started
+func New() *Worker {
+	return &Worker{
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+}
+
+// Start runs the provided function in a new go-routine, unless the worker has already been started or stopped.
+// The function must return once the provided stop channel is closed.
+func (w *Worker) Start(run func(stop <-chan struct{})) {
+	w.startOnce.Do(func() {
+		w.started = true
+		go func() {
+			defer close(w.done)
+			run(w.stop)
+		}()
+	})
+}
+
+// Stop closes the stop channel of the worker, and waits for its function to return, until the context is done.
+// The worker cannot be started once it has been stopped.
+func (w *Worker) Stop(ctx context.Context) error {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+
+	// prevent the worker from being started once stopped, and wait for it to finish any work in progress
+	w.startOnce.Do(func() {})
+	if w.started {
+		select {
+		case <-w.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package worker_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-feedback-api/worker"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWorker(t *testing.T) {
+	Convey("Given a started worker", t, func() {
+		w := worker.New()
+		runs := make(chan struct{}, 2)
+		returned := false
+		run := func(stop <-chan struct{}) {
+			runs <- struct{}{}
+			<-stop
+			returned = true
+		}
+		w.Start(run)
+
+		Convey("Then starting it again does not run the function again", func() {
+			w.Start(run)
+			<-runs
+			So(runs, ShouldHaveLength, 0)
+			So(w.Stop(context.Background()), ShouldBeNil)
+		})
+
+		Convey("Then stopping it waits for the function to return, and it can be stopped again", func() {
+			So(w.Stop(context.Background()), ShouldBeNil)
+			So(returned, ShouldBeTrue)
+			So(w.Stop(context.Background()), ShouldBeNil)
+		})
+	})
+
+	Convey("Given a started worker whose function does not return when it is stopped", t, func() {
+		w := worker.New()
+		release := make(chan struct{})
+		defer close(release)
+		w.Start(func(stop <-chan struct{}) {
+			<-release
+		})
+
+		Convey("Then stopping it fails once the context is done", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			So(w.Stop(ctx), ShouldEqual, context.DeadlineExceeded)
+		})
+	})
+
+	Convey("Given a worker that is stopped before it is started", t, func() {
+		w := worker.New()
+		So(w.Stop(context.Background()), ShouldBeNil)
+
+		Convey("Then starting it does not run the function", func() {
+			called := false
+			w.Start(func(stop <-chan struct{}) { called = true })
+			time.Sleep(10 * time.Millisecond)
+			So(called, ShouldBeFalse)
+		})
+	})
+}